@@ -0,0 +1,57 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/rodrigopv/nextr4y"
+	"github.com/urfave/cli/v2"
+)
+
+// defaultConfigPath returns the config file loaded when --config is not
+// given (~/.config/nextr4y/config.yaml on Linux), or "" if there is no
+// user config directory.
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "nextr4y", "config.yaml")
+}
+
+// loadConfig reads the config file given with --config, or the default one
+// if it exists. It returns nil when there is no config file to apply.
+func loadConfig(c *cli.Context) (*nextr4y.Config, error) {
+	path := c.String("config")
+	explicit := c.IsSet("config")
+	if !explicit {
+		if path = defaultConfigPath(); path == "" {
+			return nil, nil
+		}
+	}
+
+	cfg, err := nextr4y.LoadConfig(path)
+	if err != nil {
+		if !explicit && errors.Is(err, fs.ErrNotExist) {
+			return nil, nil // The default config file is optional
+		}
+		return nil, cli.Exit(fmt.Sprintf("Error: %v", err), 1)
+	}
+	return cfg, nil
+}
+
+// applyConfig applies the config file to opts, except for the options whose
+// flag was given on the command line, which take precedence.
+func applyConfig(c *cli.Context, opts *nextr4y.Options) error {
+	cfg, err := loadConfig(c)
+	if err != nil || cfg == nil {
+		return err
+	}
+	if err := cfg.Apply(opts, c.IsSet); err != nil {
+		return cli.Exit(fmt.Sprintf("Error: %v", err), 1)
+	}
+	return nil
+}
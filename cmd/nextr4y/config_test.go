@@ -0,0 +1,91 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+// runWithConfig runs the app with args and returns the error of the command.
+func runWithConfig(t *testing.T, args ...string) error {
+	t.Helper()
+	exiter := cli.OsExiter
+	cli.OsExiter = func(int) {}
+	defer func() { cli.OsExiter = exiter }()
+
+	var err error
+	captureStdout(t, func() {
+		err = newApp().Run(append([]string{"nextr4y"}, args...))
+	})
+	return err
+}
+
+// writeConfig writes a config file with content and returns its path.
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestConfig_RejectsUnknownKeys(t *testing.T) {
+	path := writeConfig(t, "timeout: 20s\nretries: 3\nproxy: http://localhost:8080\n")
+
+	err := runWithConfig(t, "scan", "-q", "--config", path, "https://example.com")
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unknown option(s) in config file")
+	require.Contains(t, err.Error(), "proxy, retries")
+}
+
+func TestConfig_FlagsTakePrecedence(t *testing.T) {
+	server, headers := headerServer(t)
+	path := writeConfig(t, "accept-language: fr\nheader:\n  - 'X-Team: red'\n")
+
+	runApp(t, "scan", "-q", "-f", "json", "--no-versions", "--config", path, "--accept-language", "de", server.URL)
+
+	received := <-headers
+	require.Equal(t, "de", received.Get("Accept-Language"))
+	require.Equal(t, "red", received.Get("X-Team"))
+}
+
+func TestConfig_InvalidValues(t *testing.T) {
+	// A nested map is not a list of headers
+	path := writeConfig(t, "header:\n  X-Team: red\n")
+	err := runWithConfig(t, "scan", "-q", "--config", path, "https://example.com")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid config file")
+
+	path = writeConfig(t, "header:\n  - 'no colon'\n")
+	err = runWithConfig(t, "scan", "-q", "--config", path, "https://example.com")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid header in config file")
+}
+
+func TestConfig_Manifest(t *testing.T) {
+	server, headers := headerServer(t)
+	path := writeConfig(t, "header:\n  - 'X-Team: red'\n")
+
+	// The page is not a manifest, only the request headers matter
+	runApp(t, "manifest", "-q", "--config", path, server.URL+"/")
+
+	require.Equal(t, "red", (<-headers).Get("X-Team"))
+}
+
+func TestConfig_MissingDefaultFileIsIgnored(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	server, headers := headerServer(t)
+
+	_, exitCode := runApp(t, "scan", "-q", "-f", "json", "--no-versions", server.URL)
+	require.NotEqual(t, exitUsage, exitCode)
+	require.Len(t, headers, 1)
+
+	// An explicit config file must exist
+	err := runWithConfig(t, "scan", "-q", "--config", filepath.Join(t.TempDir(), "missing.yaml"), "https://example.com")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to read config file")
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+// Log formats accepted by --log-format.
+const (
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+// configureLogging makes the default slog logger write to w in the given
+// format. Since slog.SetDefault also routes the standard log package through
+// the new handler, the scanner's log.Printf lines come out in the same format,
+// as "info" records.
+func configureLogging(format string, w io.Writer) error {
+	switch format {
+	case logFormatText:
+		return nil // Keep the default handler, which writes through the log package
+	case logFormatJSON:
+		slog.SetDefault(slog.New(slog.NewJSONHandler(w, nil)))
+		return nil
+	default:
+		return fmt.Errorf("invalid log format '%s', use 'text' or 'json'", format)
+	}
+}
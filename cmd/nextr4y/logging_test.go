@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rodrigopv/nextr4y"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigureLogging_JSON(t *testing.T) {
+	defaultLogger, flags := slog.Default(), log.Flags()
+	defer func() {
+		slog.SetDefault(defaultLogger)
+		log.SetOutput(os.Stderr)
+		log.SetFlags(flags)
+	}()
+
+	var buf bytes.Buffer
+	require.NoError(t, configureLogging(logFormatJSON, &buf))
+
+	log.Printf("Scanning target: %s", "https://example.com")
+	slog.Info("Scan finished", "target", "https://example.com", "duration", 1500*time.Millisecond)
+
+	var records []map[string]interface{}
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var record map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record), scanner.Text())
+		records = append(records, record)
+	}
+	require.Len(t, records, 2)
+
+	require.Equal(t, "INFO", records[0]["level"])
+	require.Equal(t, "Scanning target: https://example.com", records[0]["msg"])
+	require.Contains(t, records[0], "time")
+
+	require.Equal(t, "Scan finished", records[1]["msg"])
+	require.Equal(t, "https://example.com", records[1]["target"])
+	require.EqualValues(t, 1500*time.Millisecond, records[1]["duration"])
+}
+
+func TestConfigureLogging_ScanRecordsCarryTarget(t *testing.T) {
+	defaultLogger, flags := slog.Default(), log.Flags()
+	defer func() {
+		slog.SetDefault(defaultLogger)
+		log.SetOutput(os.Stderr)
+		log.SetFlags(flags)
+	}()
+	server := servePage(t, nextPage)
+
+	var buf bytes.Buffer
+	require.NoError(t, configureLogging(logFormatJSON, &buf))
+	_, err := nextr4y.Scan(context.Background(), server.URL, nextr4y.Options{SkipVersions: true})
+	require.Error(t, err, "the stub site has no build manifest")
+
+	records := make(map[string]map[string]interface{})
+	lines := bufio.NewScanner(&buf)
+	for lines.Scan() {
+		var record map[string]interface{}
+		require.NoError(t, json.Unmarshal(lines.Bytes(), &record), lines.Text())
+		records[record["msg"].(string)] = record
+	}
+	require.Contains(t, records, "Scanning target")
+	require.Equal(t, server.URL, records["Scanning target"]["target"])
+	require.Contains(t, records, "Scan failed")
+	require.Equal(t, server.URL, records["Scan failed"]["target"])
+	require.Equal(t, "WARN", records["Scan failed"]["level"])
+	require.Contains(t, records["Scan failed"]["error"], "manifest")
+	require.Contains(t, records["Scan failed"], "duration")
+}
+
+func TestConfigureLogging_InvalidFormat(t *testing.T) {
+	t.Parallel()
+
+	require.ErrorContains(t, configureLogging("xml", &bytes.Buffer{}), "invalid log format")
+}
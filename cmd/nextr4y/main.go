@@ -1,12 +1,25 @@
 package main
 
 import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"log/slog"
+	"net/http"
 	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
 	"strings"
 
 	"github.com/fatih/color"                       // Import color package
+	"github.com/mattn/go-isatty"
+	"github.com/rodrigopv/nextr4y"
+	"github.com/rodrigopv/nextr4y/internal/diff"
 	"github.com/rodrigopv/nextr4y/internal/fetch"
 	"github.com/rodrigopv/nextr4y/internal/mcpserver"
 	"github.com/rodrigopv/nextr4y/internal/scanner"
@@ -15,6 +28,13 @@ import (
 	// TODO: Import github.com/mark3labs/mcp-go when it's available for implementation
 )
 
+// Exit codes of the scan command, besides 0 for a Next.js target scanned without errors.
+const (
+	exitUsage      = 1 // Invalid usage or critical error, no results
+	exitScanErrors = 2 // Scan completed with errors, partial results were output
+	exitNotNextJS  = 3 // Scan completed, but the target is not a Next.js app
+)
+
 // Build information, initialized to defaults and potentially overridden by ldflags.
 var (
 	version = "development" // Git tag or version number
@@ -22,7 +42,17 @@ var (
 	date    = "n/a"         // Build date
 )
 
-func printBanner() {
+// buildInfo is the build information printed by the version command.
+type buildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	Date      string `json:"date"`
+	GoVersion string `json:"goVersion"`
+}
+
+// printBanner prints the banner and build info to w (stderr, so it never
+// mixes with the results on stdout).
+func printBanner(w io.Writer) {
 	lineColor := color.New(color.FgYellow)
 	nameColor := color.New(color.FgWhite, color.Bold)
 	urlColor := color.New(color.FgCyan)
@@ -43,24 +73,24 @@ func printBanner() {
 	urlPaddingLeft := strings.Repeat(" ", urlPaddingTotal/2)
 	urlPaddingRight := strings.Repeat(" ", width-len(urlText)-(urlPaddingTotal/2)) // Calculate remainder
 
-	lineColor.Println(border)
-	lineColor.Print("|")      // Print starting pipe (colored)
-	fmt.Print(namePaddingLeft) // Print left padding (no color)
-	nameColor.Print(nameText)  // Print colored name
-	fmt.Print(namePaddingRight)// Print right padding (no color)
-	lineColor.Println("|")     // Print ending pipe and newline (colored)
+	lineColor.Fprintln(w, border)
+	lineColor.Fprint(w, "|")       // Print starting pipe (colored)
+	fmt.Fprint(w, namePaddingLeft)  // Print left padding (no color)
+	nameColor.Fprint(w, nameText)   // Print colored name
+	fmt.Fprint(w, namePaddingRight) // Print right padding (no color)
+	lineColor.Fprintln(w, "|")      // Print ending pipe and newline (colored)
 
-	lineColor.Print("|")     // Print starting pipe (colored)
-	fmt.Print(urlPaddingLeft) // Print left padding (no color)
-	urlColor.Print(urlText)   // Print colored url
-	fmt.Print(urlPaddingRight)// Print right padding (no color)
-	lineColor.Println("|")    // Print ending pipe and newline (colored)
+	lineColor.Fprint(w, "|")      // Print starting pipe (colored)
+	fmt.Fprint(w, urlPaddingLeft)  // Print left padding (no color)
+	urlColor.Fprint(w, urlText)    // Print colored url
+	fmt.Fprint(w, urlPaddingRight) // Print right padding (no color)
+	lineColor.Fprintln(w, "|")     // Print ending pipe and newline (colored)
 
-	lineColor.Println(border)
+	lineColor.Fprintln(w, border)
 
 	// Print Build Info
 	buildInfo := fmt.Sprintf("Version: %s | Commit: %s | Date: %s", version, commit, date)
-	fmt.Printf("%s\n\n", metaColor.Sprint(buildInfo))
+	fmt.Fprintf(w, "%s\n\n", metaColor.Sprint(buildInfo))
 }
 
 // scanAction is the default scan action
@@ -68,27 +98,337 @@ func scanAction(c *cli.Context) error {
 	if c.NArg() != 1 {
 		cli.ShowCommandHelpAndExit(c, c.Command.Name, 1) // Show help if URL is missing
 	}
-	targetURL := c.Args().Get(0)
 	outputFile := c.String("output")
 	outputFormat := c.String("format")
 	customBaseURL := c.String("base-url")
+	compact := c.Bool("compact")
 
-	if outputFormat != "text" && outputFormat != "json" {
-		return cli.Exit(fmt.Sprintf("Error: Invalid output format '%s'. Use 'text' or 'json'.", outputFormat), 1)
+	// "-" reads the targets from stdin, one per line
+	targets := []string{c.Args().Get(0)}
+	if targets[0] == "-" {
+		var err error
+		targets, err = readTargets(os.Stdin)
+		if err != nil {
+			return cli.Exit(fmt.Sprintf("Error: failed to read targets from stdin: %v", err), 1)
+		}
+		if len(targets) == 0 {
+			return cli.Exit("Error: no target URL read from stdin", 1)
+		}
+		if len(targets) > 1 && outputFile != "" {
+			return cli.Exit("Error: --output cannot be used with several targets, use --output-dir or redirect stdout instead", 1)
+		}
+		if len(targets) > 1 && outputFormat == "json" && !c.IsSet("output-dir") {
+			compact = true // One result per line (NDJSON)
+		}
+	}
+
+	if outputFormat != "text" && outputFormat != "json" && outputFormat != "markdown" && outputFormat != "table" {
+		return cli.Exit(fmt.Sprintf("Error: Invalid output format '%s'. Use 'text', 'json', 'markdown' or 'table'.", outputFormat), 1)
+	}
+	if c.Bool("routes-only") {
+		if c.IsSet("format") && outputFormat != "text" {
+			return cli.Exit("Error: --routes-only cannot be combined with --format", 1)
+		}
+		outputFormat = "routes"
+	}
+	if c.Bool("tee") && outputFile == "" {
+		return cli.Exit("Error: --tee requires --output", 1)
+	}
+
+	var namer *outputNamer
+	if outputDir := c.String("output-dir"); outputDir != "" {
+		if outputFile != "" {
+			return cli.Exit("Error: --output and --output-dir cannot be combined", 1)
+		}
+		if err := os.MkdirAll(outputDir, 0755); err != nil {
+			return cli.Exit(fmt.Sprintf("Error: failed to create --output-dir: %v", err), 1)
+		}
+		namer = newOutputNamer(outputDir, outputFormat)
+	}
+
+	if customBaseURL != "" {
+		if _, err := scanner.NormalizeBaseURL(customBaseURL); err != nil {
+			return cli.Exit(fmt.Sprintf("Error: %v", err), 1)
+		}
+	}
+
+	if assetHost := c.String("asset-host-override"); assetHost != "" {
+		if _, err := scanner.NormalizeAssetHost(assetHost); err != nil {
+			return cli.Exit(fmt.Sprintf("Error: %v", err), 1)
+		}
+	}
+
+	if _, err := fetch.NormalizeHTTPVersion(c.String("http-version")); err != nil {
+		return cli.Exit(fmt.Sprintf("Error: %v", err), 1)
+	}
+
+	if c.IsSet("seed") && !c.Bool("rotate-ua") {
+		return cli.Exit("Error: --seed requires --rotate-ua", 1)
+	}
+
+	var cookies []*http.Cookie
+	if c.IsSet("cookies") {
+		var err error
+		cookies, err = http.ParseCookie(c.String("cookies"))
+		if err != nil {
+			return cli.Exit(fmt.Sprintf("Error: invalid --cookies value, expected 'name=value; ...': %v", err), 1)
+		}
+	}
+
+	scheme := c.String("scheme")
+	switch scheme {
+	case "auto":
+		scheme = scanner.SchemeAuto
+	case scanner.SchemeHTTPS, scanner.SchemeHTTP:
+	default:
+		return cli.Exit(fmt.Sprintf("Error: Invalid scheme '%s'. Use 'auto', 'https' or 'http'.", scheme), 1)
+	}
+
+	var assetPattern *regexp.Regexp
+	if c.IsSet("asset-pattern") {
+		var err error
+		assetPattern, err = regexp.Compile(c.String("asset-pattern"))
+		if err != nil {
+			return cli.Exit(fmt.Sprintf("Error: invalid --asset-pattern: %v", err), 1)
+		}
+	}
+
+	var wordlist []string
+	if c.IsSet("wordlist") {
+		file, err := os.Open(c.String("wordlist"))
+		if err != nil {
+			return cli.Exit(fmt.Sprintf("Error: failed to open --wordlist: %v", err), 1)
+		}
+		wordlist, err = scanner.ParseWordlist(file)
+		file.Close()
+		if err != nil {
+			return cli.Exit(fmt.Sprintf("Error: failed to read --wordlist: %v", err), 1)
+		}
+		if len(wordlist) == 0 {
+			return cli.Exit("Error: --wordlist has no paths", 1)
+		}
+	}
+
+	headers, err := nextr4y.ParseHeaders(c.StringSlice("header"))
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("Error: %v", err), 1)
+	}
+	resolve, err := fetch.ParseResolve(c.StringSlice("resolve"))
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("Error: %v", err), 1)
+	}
+	if fromDir := c.String("from-dir"); fromDir != "" {
+		if c.Bool("render") {
+			return cli.Exit("Error: --from-dir cannot be combined with --render", 1)
+		}
+		if _, err := os.Stat(filepath.Join(fromDir, "index.html")); err != nil {
+			return cli.Exit(fmt.Sprintf("Error: --from-dir needs the saved page as index.html: %v", err), 1)
+		}
+	}
+	if c.IsSet("basic-auth") {
+		authorization, err := basicAuthHeader(c.String("basic-auth"))
+		if err != nil {
+			return cli.Exit(fmt.Sprintf("Error: %v", err), 1)
+		}
+		for name := range headers {
+			if strings.EqualFold(name, "Authorization") {
+				return cli.Exit("Error: --basic-auth cannot be combined with an Authorization header", 1)
+			}
+		}
+		headers["Authorization"] = authorization
+	}
+	if c.IsSet("accept-language") {
+		for name := range headers {
+			if strings.EqualFold(name, "Accept-Language") {
+				return cli.Exit("Error: --accept-language cannot be combined with an Accept-Language header", 1)
+			}
+		}
+		headers["Accept-Language"] = c.String("accept-language")
+	}
+
+	quiet := c.Bool("quiet")
+	if quiet {
+		log.SetOutput(io.Discard)
 	}
 
-	log.Printf("Scanning target: %s", targetURL)
 	if customBaseURL != "" {
 		log.Printf("Using custom base URL: %s", customBaseURL)
 	}
 
-	// Create the fetcher and scanner instances
-	fetcher := fetch.NewHTTPFetcher()
-	versionDetector := &versiondetect.HeuristicAssetScannerDetector{}
-	scr := scanner.NewScanner(fetcher, versionDetector, customBaseURL) // Pass the custom base URL
+	opts := nextr4y.Options{
+		BaseURL:               customBaseURL,
+		AssetHostOverride:     c.String("asset-host-override"),
+		Timeout:               c.Duration("timeout"),
+		ManifestTimeout:       c.Duration("manifest-exec-timeout"),
+		Headers:               headers,
+		Scheme:                scheme,
+		Render:                c.Bool("render"),
+		FromDir:               c.String("from-dir"),
+		HashAssets:            c.Bool("hash-assets"),
+		Discover:              c.Bool("discover"),
+		Metadata:              c.Bool("metadata"),
+		RateLimit:             c.Float64("rate"),
+		CookieJar:             c.Bool("cookie-jar"),
+		Insecure:              c.Bool("insecure"),
+		RotateUserAgent:       c.Bool("rotate-ua"),
+		Seed:                  c.Int64("seed"),
+		ProfileDelay:          c.Duration("profile-delay"),
+		HTTPVersion:           c.String("http-version"),
+		Resolve:               resolve,
+		Cookies:               cookies,
+		IncludeRaw:            c.Bool("include-raw"),
+		ExtractEndpoints:      c.Bool("extract-endpoints"),
+		ExtractKeys:           c.Bool("extract-keys"),
+		DetectInstrumentation: c.Bool("detect-instrumentation"),
+		AssetPattern:          assetPattern,
+		ProbeData:             c.Bool("probe-data"),
+		ProbeImage:            c.Bool("probe-image"),
+		ProbePreview:          c.Bool("probe-preview"),
+		Bruteforce:            c.Bool("bruteforce") || len(wordlist) > 0,
+		Wordlist:              wordlist,
+		SkipVersions:          c.Bool("no-versions"),
+		IncludeErrors:         c.Bool("include-errors"),
+		IncludeHeaders:        c.Bool("include-headers"),
+		Debug:                 c.Bool("debug"),
+		DryRun:                c.Bool("dry-run"),
+		CacheDir:              c.String("cache-dir"),
+		RefreshCache:          c.Bool("no-cache"),
+	}
+	if maxRedirects := c.Int("max-redirects"); maxRedirects > 0 {
+		opts.MaxRedirects = maxRedirects
+	} else {
+		opts.MaxRedirects = -1 // Do not follow redirects
+	}
+	if maxAssets := c.Int("max-assets"); maxAssets > 0 {
+		opts.MaxAssets = maxAssets
+	} else {
+		opts.MaxAssets = -1 // No limit
+	}
+	if maxBodySize := c.Int64("max-body-size"); maxBodySize > 0 {
+		opts.MaxBodySize = maxBodySize
+	} else {
+		opts.MaxBodySize = -1 // No limit
+	}
+	if cacheTTL := c.Duration("cache-ttl"); cacheTTL > 0 {
+		opts.CacheTTL = cacheTTL
+	} else {
+		opts.CacheTTL = -1 // Never expires
+	}
+	if err := applyConfig(c, &opts); err != nil {
+		return err
+	}
+
+	// Show a progress line on interactive terminals, with the logs printed above it
+	var progress *progressReporter
+	if !quiet && stderrIsTerminal() {
+		progress = newProgressReporter(os.Stderr)
+		log.SetOutput(progress)
+		opts.Progress = progress.Update
+	}
 
-	// Call the ScanTarget method
-	result, err := scr.ScanTarget(targetURL)
+	if len(targets) == 1 {
+		if namer != nil {
+			outputFile = namer.path(targets[0])
+		}
+		code, err := scanAndOutput(c, targets[0], opts, progress, outputFile, outputFormat, compact, nil)
+		if err != nil {
+			return err
+		}
+		if code != 0 {
+			return cli.Exit("", code)
+		}
+		return nil
+	}
+
+	if threshold := c.Int("circuit-breaker"); threshold > 0 && len(targets) > 1 {
+		opts.CircuitBreaker = nextr4y.NewCircuitBreaker(threshold)
+	}
+	if opts.RateLimit > 0 && len(targets) > 1 {
+		// One budget for the whole batch, not a fresh one per target
+		opts.RateLimiter = fetch.NewLimiter(opts.RateLimit)
+	}
+
+	var nextJS, notNextJS, failed, skipped int
+	var written []string // "<target> -> <file> (<outcome>)" lines of the --output-dir summary
+	// A table is printed once with a row per target, after all of them are scanned
+	var tableRows *[]*nextr4y.ScanResult
+	if outputFormat == "table" && namer == nil {
+		tableRows = &[]*nextr4y.ScanResult{}
+	}
+	for _, targetURL := range targets {
+		if opts.CircuitBreaker != nil {
+			if err := opts.CircuitBreaker.Check(targetURL); err != nil {
+				log.Printf("Skipping %s: %v", targetURL, err)
+				skipped++
+				if namer != nil {
+					written = append(written, fmt.Sprintf("  %s (skipped, host failing)", targetURL))
+				}
+				continue
+			}
+		}
+		if namer != nil {
+			outputFile = namer.path(targetURL)
+		}
+		code, err := scanAndOutput(c, targetURL, opts, progress, outputFile, outputFormat, compact, tableRows)
+		outcome := fmt.Sprintf("-> %s (Next.js)", outputFile)
+		switch {
+		case err != nil:
+			log.Printf("Skipping %s: %v", targetURL, err)
+			failed++
+			outcome = "(failed, no result)"
+		case code == exitScanErrors:
+			failed++
+			outcome = fmt.Sprintf("-> %s (with errors)", outputFile)
+		case code == exitNotNextJS:
+			notNextJS++
+			outcome = fmt.Sprintf("-> %s (not Next.js)", outputFile)
+			if c.Bool("only-nextjs") {
+				outcome = "(not Next.js, skipped)"
+			}
+		default:
+			nextJS++
+		}
+		if namer != nil {
+			written = append(written, fmt.Sprintf("  %s %s", targetURL, outcome))
+		}
+	}
+	summary := fmt.Sprintf("Scanned %d targets: %d Next.js, %d not Next.js, %d with errors", len(targets), nextJS, notNextJS, failed)
+	if skipped > 0 {
+		summary += fmt.Sprintf(", %d skipped (failing hosts)", skipped)
+	}
+	summary += "."
+	if namer != nil {
+		// Results are in files, so stdout is free for the summary
+		fmt.Println(summary)
+		fmt.Println(strings.Join(written, "\n"))
+	} else {
+		log.Print(summary)
+	}
+	if tableRows != nil && len(*tableRows) > 0 {
+		if err := scanner.RenderTable(*tableRows, os.Stdout); err != nil {
+			return cli.Exit(fmt.Sprintf("Error printing results: %v", err), 1)
+		}
+	}
+
+	// Report the worst outcome
+	if failed > 0 || skipped > 0 {
+		return cli.Exit("", exitScanErrors)
+	}
+	if notNextJS > 0 {
+		return cli.Exit("", exitNotNextJS)
+	}
+	return nil
+}
+
+// scanAndOutput scans a single target, outputs its result and returns the
+// exit code describing the outcome. An error is only returned if there is no
+// result to output. When tableRows is set, the result is appended to it
+// instead of being output.
+func scanAndOutput(c *cli.Context, targetURL string, opts nextr4y.Options, progress *progressReporter, outputFile, outputFormat string, compact bool, tableRows *[]*nextr4y.ScanResult) (int, error) {
+	result, err := nextr4y.Scan(c.Context, targetURL, opts)
+	if progress != nil {
+		progress.Done()
+	}
 	if err != nil {
 		// Log the error, but proceed to print/write partial results if available
 		log.Printf("Scan encountered an error: %v", err)
@@ -97,66 +437,382 @@ func scanAction(c *cli.Context) error {
 			result.ExecutionError = err
 		} else if result == nil {
 			// Handle cases where ScanTarget returns nil result (e.g., invalid final URL parse)
-			return cli.Exit(fmt.Sprintf("Critical error during scan setup: %v", err), 1)
+			return 0, cli.Exit(fmt.Sprintf("Critical error during scan setup: %v", err), exitUsage)
+		}
+	}
+
+	if c.Bool("only-nextjs") && !result.IsNextJS {
+		log.Printf("Skipping output for %s: not a Next.js target (--only-nextjs)", targetURL)
+	} else if tableRows != nil {
+		*tableRows = append(*tableRows, result)
+	} else if err := outputResult(c, result, outputFile, outputFormat, compact); err != nil {
+		return 0, err
+	}
+
+	// Report the outcome through the exit code so scripts can branch on it.
+	// A missing __NEXT_DATA__ is only the symptom of a non-Next.js target.
+	if result.ExecutionError != nil && !errors.Is(result.ExecutionError, scanner.ErrNextDataNotFound) {
+		log.Printf("Scan completed with errors (see logs or JSON output for details).")
+		return exitScanErrors, nil
+	}
+	if !result.IsNextJS {
+		log.Println("Scan completed successfully, but the target does not appear to use Next.js.")
+		return exitNotNextJS, nil
+	}
+	log.Println("Scan completed successfully.")
+	return 0, nil
+}
+
+// readTargets reads one target URL per line, skipping blank lines.
+func readTargets(r io.Reader) ([]string, error) {
+	var targets []string
+	lines := bufio.NewScanner(r)
+	for lines.Scan() {
+		if target := strings.TrimSpace(lines.Text()); target != "" {
+			targets = append(targets, target)
 		}
 	}
+	return targets, lines.Err()
+}
 
-	// Handle output
+// outputResult writes the result to outputFile and/or prints it to stdout
+// (--tee does both).
+func outputResult(c *cli.Context, result *nextr4y.ScanResult, outputFile, outputFormat string, compact bool) error {
 	if outputFile != "" {
-		err := scanner.WriteOutput(result, outputFile, outputFormat)
+		err := scanner.WriteOutput(result, outputFile, outputFormat, compact)
 		if err != nil {
 			return cli.Exit(fmt.Sprintf("Error writing output file: %v", err), 1)
 		}
-	} else {
-		err := scanner.PrintResults(result, outputFormat)
+	}
+	if outputFile == "" || c.Bool("tee") {
+		err := scanner.PrintResults(result, outputFormat, compact)
 		if err != nil {
 			// This should ideally not happen if format validation is done
 			return cli.Exit(fmt.Sprintf("Error printing results: %v", err), 1)
 		}
 	}
+	return nil
+}
 
-	// Indicate if there was a non-critical error during the scan
-	if result != nil && result.ExecutionError != nil {
-		// Return a non-zero exit code to indicate partial failure
-		// Return nil here to let the log message suffice, or return the error string?
-		// Let's return nil for now, the log indicates the issue. User can use JSON output for details.
-		log.Printf("Scan completed with errors (see logs or JSON output for details).")
+// versionAction prints the build information, one "key: value" per line or
+// as JSON, without the banner so tools can parse it.
+func versionAction(c *cli.Context) error {
+	outputFormat := c.String("format")
+	if outputFormat != "text" && outputFormat != "json" {
+		return cli.Exit(fmt.Sprintf("Error: Invalid output format '%s'. Use 'text' or 'json'.", outputFormat), 1)
+	}
+
+	info := buildInfo{Version: version, Commit: commit, Date: date, GoVersion: runtime.Version()}
+	if outputFormat == "json" {
+		outJSON, err := json.Marshal(info)
+		if err != nil {
+			return cli.Exit(fmt.Sprintf("Error: failed to marshal build info to JSON: %v", err), 1)
+		}
+		fmt.Println(string(outJSON))
+		return nil
+	}
+	fmt.Printf("version: %s\ncommit: %s\ndate: %s\ngo: %s\n", info.Version, info.Commit, info.Date, info.GoVersion)
+	return nil
+}
+
+// diffAction compares two scan result JSON files
+func diffAction(c *cli.Context) error {
+	if c.NArg() != 2 {
+		cli.ShowCommandHelpAndExit(c, c.Command.Name, 1) // Show help if a file is missing
+	}
+	outputFormat := c.String("format")
+	if outputFormat != "text" && outputFormat != "json" {
+		return cli.Exit(fmt.Sprintf("Error: Invalid output format '%s'. Use 'text' or 'json'.", outputFormat), 1)
+	}
+
+	var fields []string
+	if c.IsSet("fields") {
+		var err error
+		fields, err = diff.ParseFields(c.String("fields"))
+		if err != nil {
+			return cli.Exit(fmt.Sprintf("Error: %v", err), 1)
+		}
+	}
+
+	oldResult, err := diff.LoadResult(c.Args().Get(0))
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("Error: %v", err), 1)
+	}
+	newResult, err := diff.LoadResult(c.Args().Get(1))
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("Error: %v", err), 1)
+	}
+
+	report := diff.Compare(oldResult, newResult)
+	if fields != nil {
+		report = report.Only(fields)
+	}
+	if outputFormat == "json" {
+		outJSON, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return cli.Exit(fmt.Sprintf("Error: failed to marshal diff to JSON: %v", err), 1)
+		}
+		fmt.Println(string(outJSON))
+		return nil
+	}
+	fmt.Print(diff.RenderText(report))
+	return nil
+}
+
+// manifestAction reads the routes of a build manifest fetched directly from
+// its URL, without fetching the page, and outputs them like a scan.
+func manifestAction(c *cli.Context) error {
+	if c.NArg() != 1 {
+		cli.ShowCommandHelpAndExit(c, c.Command.Name, 1) // Show help if URL is missing
+	}
+	manifestURL := c.Args().Get(0)
+	outputFile := c.String("output")
+	outputFormat := c.String("format")
+
+	if outputFormat != "text" && outputFormat != "json" && outputFormat != "markdown" && outputFormat != "table" {
+		return cli.Exit(fmt.Sprintf("Error: Invalid output format '%s'. Use 'text', 'json', 'markdown' or 'table'.", outputFormat), 1)
+	}
+	if c.Bool("routes-only") {
+		if c.IsSet("format") && outputFormat != "text" {
+			return cli.Exit("Error: --routes-only cannot be combined with --format", 1)
+		}
+		outputFormat = "routes"
+	}
+	if c.Bool("tee") && outputFile == "" {
+		return cli.Exit("Error: --tee requires --output", 1)
+	}
+	if customBaseURL := c.String("base-url"); customBaseURL != "" {
+		if _, err := scanner.NormalizeBaseURL(customBaseURL); err != nil {
+			return cli.Exit(fmt.Sprintf("Error: %v", err), 1)
+		}
+	}
+	headers, err := nextr4y.ParseHeaders(c.StringSlice("header"))
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("Error: %v", err), 1)
+	}
+
+	if c.Bool("quiet") {
+		log.SetOutput(io.Discard)
+	}
+
+	opts := nextr4y.Options{
+		BaseURL:         c.String("base-url"),
+		Timeout:         c.Duration("timeout"),
+		ManifestTimeout: c.Duration("manifest-exec-timeout"),
+		Headers:         headers,
+		Insecure:        c.Bool("insecure"),
+		IncludeRaw:      c.Bool("include-raw"),
+	}
+	if maxRedirects := c.Int("max-redirects"); maxRedirects > 0 {
+		opts.MaxRedirects = maxRedirects
 	} else {
-		log.Println("Scan completed successfully.")
+		opts.MaxRedirects = -1 // Do not follow redirects
+	}
+	if err := applyConfig(c, &opts); err != nil {
+		return err
 	}
 
+	log.Printf("Reading build manifest: %s", manifestURL)
+	result, err := nextr4y.ScanManifest(c.Context, manifestURL, opts)
+	if result == nil {
+		return cli.Exit(fmt.Sprintf("Critical error during scan setup: %v", err), exitUsage)
+	}
+	if err != nil {
+		log.Printf("Scan encountered an error: %v", err)
+	}
+	if err := outputResult(c, result, outputFile, outputFormat, c.Bool("compact")); err != nil {
+		return err
+	}
+	if result.ExecutionError != nil {
+		return cli.Exit("", exitScanErrors)
+	}
 	return nil
 }
 
+// basicAuthHeader converts a "user:pass" value into an Authorization header value.
+// Errors never include the value itself so credentials don't end up in logs.
+func basicAuthHeader(value string) (string, error) {
+	user, _, found := strings.Cut(value, ":")
+	if !found || user == "" {
+		return "", fmt.Errorf("invalid --basic-auth value, expected 'user:pass'")
+	}
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(value)), nil
+}
+
 // serveAction is the action for the serve command
 func serveAction(c *cli.Context) error {
 	port := c.Int("port")
 	host := c.String("host")
-	
-	log.Printf("Starting MCP server on %s:%d", host, port)
-	log.Printf("The server accepts nextr4y scan requests via MCP protocol")
+
+	if err := configureLogging(c.String("log-format"), os.Stderr); err != nil {
+		return cli.Exit(fmt.Sprintf("Error: %v", err), 1)
+	}
+	// The config file sets the defaults of the scans the server runs
+	var scanOpts nextr4y.Options
+	if err := applyConfig(c, &scanOpts); err != nil {
+		return err
+	}
+
+	slog.Info("Starting MCP server", "host", host, "port", port)
+	slog.Info("The server accepts nextr4y scan requests via MCP protocol")
 	
 	// Create and start the MCP server
-	server := mcpserver.NewMCPServer(host, port)
+	server := mcpserver.NewMCPServerWithOptions(host, port, mcpserver.Options{
+		EnableHTTPAPI:      c.Bool("http"),
+		MaxConcurrentScans: c.Int("max-concurrent-scans"),
+		EnableMetrics:      c.Bool("metrics"),
+		ShutdownTimeout:    c.Duration("shutdown-timeout"),
+		ScanOptions:        scanOpts,
+	})
 	return server.Start()
 }
 
-func main() {
-	printBanner() // Print the banner first
+// configureColor applies the --color/--no-color flags and the NO_COLOR
+// environment variable to the color package. Flags set on a command take
+// precedence over global ones, and both take precedence over NO_COLOR.
+func configureColor(c *cli.Context) error {
+	mode := "auto"
+	if os.Getenv("NO_COLOR") != "" {
+		mode = "never"
+	}
+
+	// Walk from the root context down so the most specific setting wins
+	lineage := c.Lineage()
+	for i := len(lineage) - 1; i >= 0; i-- {
+		ctx := lineage[i]
+		if ctx.IsSet("color") {
+			mode = ctx.String("color")
+		}
+		if ctx.IsSet("no-color") && ctx.Bool("no-color") {
+			mode = "never"
+		}
+	}
+
+	switch mode {
+	case "auto":
+		// Keep the color package's own TTY detection
+	case "always":
+		// color.New also checks NO_COLOR itself, so clear it to force colors
+		os.Unsetenv("NO_COLOR")
+		color.NoColor = false
+	case "never":
+		color.NoColor = true
+	default:
+		return cli.Exit(fmt.Sprintf("Error: Invalid color mode '%s'. Use 'auto', 'always' or 'never'.", mode), 1)
+	}
+	return nil
+}
+
+// stderrIsTerminal reports whether stderr is a terminal, for the banner and
+// the progress line. Tests replace it.
+var stderrIsTerminal = func() bool {
+	return isatty.IsTerminal(os.Stderr.Fd())
+}
+
+// showBanner reports whether the banner should be printed before the command
+// runs. It goes to stderr, but is still left out when stderr isn't a terminal
+// and for output meant for other programs: JSON, --routes-only and results
+// written to a file. --no-banner disables it explicitly.
+func showBanner(c *cli.Context) bool {
+	for _, ctx := range c.Lineage() {
+		if ctx.Bool("no-banner") {
+			return false
+		}
+	}
+	if c.String("format") == "json" || c.Bool("routes-only") || c.String("output") != "" || c.String("log-format") == logFormatJSON {
+		return false
+	}
+	return stderrIsTerminal()
+}
+
+// beforeCommand configures color output and prints the banner before a command runs.
+func beforeCommand(c *cli.Context) error {
+	if err := configureColor(c); err != nil {
+		return err
+	}
+	if showBanner(c) {
+		printBanner(os.Stderr)
+	}
+	return nil
+}
+
+// selectFlags returns the flags of flags with the given names, in the order
+// of flags.
+func selectFlags(flags []cli.Flag, names ...string) []cli.Flag {
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+	var selected []cli.Flag
+	for _, flag := range flags {
+		if wanted[flag.Names()[0]] {
+			selected = append(selected, flag)
+		}
+	}
+	return selected
+}
+
+// newApp builds the command-line application.
+func newApp() *cli.App {
+	// Color and banner flags, accepted both globally and on the scan and diff commands
+	displayFlags := []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "no-color",
+			Usage: "Disable colored output (same as --color=never)",
+		},
+		&cli.StringFlag{
+			Name:  "color",
+			Value: "auto",
+			Usage: "Colorize output: `auto`, always or never (NO_COLOR is honored)",
+		},
+		&cli.BoolFlag{
+			Name:  "no-banner",
+			Usage: "Do not print the banner (it is also skipped for JSON, --output and when stderr is not a terminal)",
+		},
+	}
 
 	// Common flags for scan command
 	scanFlags := []cli.Flag{
+		&cli.StringFlag{
+			Name:  "config",
+			Usage: "Load default options from a YAML `FILE` (default: ~/.config/nextr4y/config.yaml if it exists)",
+		},
 		&cli.StringFlag{
 			Name:    "output",
 			Aliases: []string{"o"},
 			Value:   "", // Default is stdout
 			Usage:   "Write output to `FILE`",
 		},
+		&cli.StringFlag{
+			Name:  "output-dir",
+			Usage: "Write each target's result to its own file in `DIR`, named after its host (e.g. DIR/example.com.json)",
+		},
+		&cli.BoolFlag{
+			Name:  "tee",
+			Usage: "Also print the results to stdout when writing them with --output",
+		},
 		&cli.StringFlag{
 			Name:    "format",
 			Aliases: []string{"f"},
 			Value:   "text", // Default format
-			Usage:   "Output format (`text` or `json`)",
+			Usage:   "Output format (`text`, json, markdown or table)",
+		},
+		&cli.BoolFlag{
+			Name:  "routes-only",
+			Usage: "Only print the route paths, one per line and sorted (e.g. for fuzzing wordlists)",
+		},
+		&cli.BoolFlag{
+			Name:    "quiet",
+			Aliases: []string{"q"},
+			Usage:   "Suppress log messages and the progress indicator",
+		},
+		&cli.BoolFlag{
+			Name:  "only-nextjs",
+			Usage: "Only output results for targets that use Next.js (the exit code still reports the outcome)",
+		},
+		&cli.BoolFlag{
+			Name:  "compact",
+			Usage: "Print JSON output on a single line instead of indenting it",
 		},
 		&cli.StringFlag{
 			Name:    "base-url",
@@ -164,7 +820,191 @@ func main() {
 			Value:   "", // Default is empty (use auto-detection)
 			Usage:   "Override the auto-detected base URL for asset resolution",
 		},
+		&cli.StringFlag{
+			Name:  "asset-host-override",
+			Usage: "Fetch assets served from another host than the page from this `host` instead (for asset hosts blocking the scanner)",
+		},
+		&cli.DurationFlag{
+			Name:  "timeout",
+			Value: 0, // Default is the fetcher's own timeout
+			Usage: "Per-request timeout (e.g. `20s`)",
+		},
+		&cli.DurationFlag{
+			Name:  "manifest-exec-timeout",
+			Value: scanner.DefaultManifestExecTimeout,
+			Usage: "Time allowed for evaluating the build manifest JS, independent of --timeout (raise it for very large manifests)",
+		},
+		&cli.StringSliceFlag{
+			Name:    "header",
+			Aliases: []string{"H"},
+			Usage:   "Extra request header as `'Name: Value'` (repeatable)",
+		},
+		&cli.StringSliceFlag{
+			Name:  "resolve",
+			Usage: "Connect to IP instead of resolving host, keeping the host name in SNI and Host, as `host:[port:]IP` like curl (repeatable)",
+		},
+		&cli.StringFlag{
+			Name:  "accept-language",
+			Usage: "Accept-Language header sent with every request, to scan a specific locale (e.g. `fr-FR,fr;q=0.9`)",
+		},
+		&cli.StringFlag{
+			Name:  "basic-auth",
+			Usage: "HTTP Basic Auth credentials as `user:pass`, sent with every request",
+		},
+		&cli.BoolFlag{
+			Name:  "cookie-jar",
+			Usage: "Keep cookies set by responses and send them on later requests of the scan",
+		},
+		&cli.StringFlag{
+			Name:  "cookies",
+			Usage: "Seed cookies sent to the target as `'name=value; ...'` (enables --cookie-jar)",
+		},
+		&cli.BoolFlag{
+			Name:  "insecure",
+			Usage: "Skip TLS certificate verification (for self-signed or expired certificates)",
+		},
+		&cli.BoolFlag{
+			Name:  "rotate-ua",
+			Usage: "Start each request from a random TLS/User-Agent profile, to vary the fingerprint across requests",
+		},
+		&cli.Int64Flag{
+			Name:  "seed",
+			Usage: "Seed for --rotate-ua, to pick the same sequence of profiles on every run (default: random)",
+		},
+		&cli.DurationFlag{
+			Name:  "profile-delay",
+			Usage: "Wait this long (plus random jitter) before retrying a request with the next TLS profile, against WAFs blocking rapid retries; slows down scans of hosts rejecting profiles (enables --rotate-ua)",
+		},
+		&cli.StringFlag{
+			Name:  "http-version",
+			Value: "auto",
+			Usage: "HTTP version of the requests: auto (negotiated, HTTP/2 preferred), 1.1 or 2",
+		},
+		&cli.StringFlag{
+			Name:  "scheme",
+			Value: "auto",
+			Usage: "Scheme for targets given without one: 'auto' (HTTPS, falling back to HTTP), 'https' or 'http'",
+		},
+		&cli.IntFlag{
+			Name:  "max-redirects",
+			Value: fetch.DefaultMaxRedirects,
+			Usage: "Maximum number of redirects followed per request (0 to not follow redirects)",
+		},
+		&cli.BoolFlag{
+			Name:  "hash-assets",
+			Usage: "Download every discovered asset and record its SHA-256 (useful to diff deployments)",
+		},
+		&cli.BoolFlag{
+			Name:  "discover",
+			Usage: "Also collect paths from /robots.txt and sitemap.xml",
+		},
+		&cli.BoolFlag{
+			Name:  "metadata",
+			Usage: "Also fetch /favicon.ico and the web app manifest to report the app name and theme color",
+		},
+		&cli.BoolFlag{
+			Name:  "probe-data",
+			Usage: fmt.Sprintf("Fetch the _next/data JSON of up to %d routes to check which ones are live", scanner.DefaultMaxDataProbes),
+		},
+		&cli.BoolFlag{
+			Name:  "probe-image",
+			Usage: "Check whether the /_next/image optimizer is served and fetches images from external hosts (SSRF risk)",
+		},
+		&cli.BoolFlag{
+			Name:  "probe-preview",
+			Usage: "Probe the conventional Preview and Draft Mode routes (/api/preview, /api/draft, ...) of CMS-backed sites",
+		},
+		&cli.BoolFlag{
+			Name:  "bruteforce",
+			Usage: "Probe a built-in wordlist of common Next.js paths (auth, health and revalidation API routes, development chunks)",
+		},
+		&cli.StringFlag{
+			Name:  "wordlist",
+			Usage: "Probe the paths listed in `FILE`, one per line, instead of the built-in wordlist (enables --bruteforce)",
+		},
+		&cli.BoolFlag{
+			Name:  "extract-endpoints",
+			Usage: "Collect URLs and API/GraphQL endpoints referenced in the fetched JS chunks",
+		},
+		&cli.BoolFlag{
+			Name:  "extract-keys",
+			Usage: "Collect Sentry DSNs, analytics IDs and other SDK keys embedded in the fetched JS chunks (reported unredacted)",
+		},
+		&cli.BoolFlag{
+			Name:  "detect-instrumentation",
+			Usage: "Look for OpenTelemetry SDKs, Next.js instrumentation hooks and OTLP collector endpoints in the fetched JS chunks",
+		},
+		&cli.StringFlag{
+			Name:  "asset-pattern",
+			Usage: "Also treat page scripts whose URL matches this `REGEX` as Next.js chunks (for chunks served outside _next/static)",
+		},
+		&cli.BoolFlag{
+			Name:  "include-raw",
+			Usage: "Include the full executed build manifest (BuildManifestRaw) and App Router flight payload (FlightPayloadRaw) in JSON output",
+		},
+		&cli.BoolFlag{
+			Name:  "no-versions",
+			Usage: "Skip version detection, the slowest phase, when only routes and the build ID are needed",
+		},
+		&cli.BoolFlag{
+			Name:  "include-errors",
+			Usage: "Record why each failed asset download failed in AssetErrors (explains Unknown versions)",
+		},
+		&cli.BoolFlag{
+			Name:  "include-headers",
+			Usage: "Record every header of the page response in ResponseHeaders, with every Set-Cookie value",
+		},
+		&cli.BoolFlag{
+			Name:  "debug",
+			Usage: "Include troubleshooting details in the result: every version candidate weighed by the detector (VersionCandidates)",
+		},
+		&cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "Only fetch the page and print the URLs the scan would request, without requesting them",
+		},
+		&cli.StringFlag{
+			Name:  "cache-dir",
+			Usage: "Cache the last result of each host and set of options in `DIR`, and reuse it without fetching the manifest nor the assets while the build ID is unchanged",
+		},
+		&cli.DurationFlag{
+			Name:  "cache-ttl",
+			Value: scanner.DefaultCacheTTL,
+			Usage: "How long a cached result is reused (0 to never expire)",
+		},
+		&cli.BoolFlag{
+			Name:  "no-cache",
+			Usage: "Scan fully even if the build ID is cached, then cache the new result",
+		},
+		&cli.IntFlag{
+			Name:  "max-assets",
+			Value: versiondetect.DefaultMaxAssets,
+			Usage: "Maximum number of JS assets fetched for version detection (0 for no limit)",
+		},
+		&cli.Int64Flag{
+			Name:  "max-body-size",
+			Value: fetch.DefaultMaxBodySize,
+			Usage: "Maximum size in bytes of a response body (HTML page or asset); larger responses fail with \"response too large\" (0 for no limit)",
+		},
+		&cli.Float64Flag{
+			Name:  "rate",
+			Value: 0, // Default is unlimited
+			Usage: "Maximum requests per second across all fetches (0 for unlimited)",
+		},
+		&cli.IntFlag{
+			Name:  "circuit-breaker",
+			Value: fetch.DefaultCircuitBreakerThreshold,
+			Usage: "When scanning several targets, skip a host for the rest of the batch after `N` consecutive targets whose page fetch failed (no response, 5xx, 401, 403 or 429) (0 to never skip)",
+		},
+		&cli.BoolFlag{
+			Name:  "render",
+			Usage: "Render the page in headless Chrome when the static HTML shows no Next.js signal",
+		},
+		&cli.StringFlag{
+			Name:  "from-dir",
+			Usage: "Scan a saved copy of the target from `DIR` without network access: the page from DIR/index.html, other URLs by path (e.g. DIR/_next/static/...)",
+		},
 	}
+	scanFlags = append(scanFlags, displayFlags...)
 
 	// Serve command flags
 	serveFlags := []cli.Flag{
@@ -179,9 +1019,63 @@ func main() {
 			Value:   "0.0.0.0",
 			Usage:   "Host for the MCP server",
 		},
+		&cli.BoolFlag{
+			Name:  "http",
+			Usage: "Also serve a plain REST API (POST /scan, GET /healthz) on the same address",
+		},
+		&cli.IntFlag{
+			Name:  "max-concurrent-scans",
+			Value: mcpserver.DefaultMaxConcurrentScans,
+			Usage: "Maximum number of scans running at once; excess requests are queued, then rejected",
+		},
+		&cli.BoolFlag{
+			Name:  "metrics",
+			Usage: "Serve Prometheus metrics (scan counts, durations, errors) at GET /metrics on the same address",
+		},
+		&cli.DurationFlag{
+			Name:  "shutdown-timeout",
+			Value: mcpserver.DefaultShutdownTimeout,
+			Usage: "How long in-flight scans may run after SIGINT/SIGTERM before the server stops",
+		},
+		&cli.StringFlag{
+			Name:  "log-format",
+			Value: logFormatText,
+			Usage: "Log format: `text`, or json for one JSON object per line (level, msg, time and fields such as target and duration)",
+		},
 	}
+	serveFlags = append(serveFlags, selectFlags(scanFlags, "config")...)
 
-	app := &cli.App{
+	// Manifest command flags, the scan flags that apply to a lone manifest
+	manifestFlags := selectFlags(scanFlags, "config", "output", "tee", "format", "routes-only", "quiet", "compact", "base-url", "timeout",
+		"manifest-exec-timeout", "header", "insecure", "max-redirects", "include-raw")
+	manifestFlags = append(manifestFlags, displayFlags...)
+
+	// Diff command flags
+	diffFlags := []cli.Flag{
+		&cli.StringFlag{
+			Name:    "format",
+			Aliases: []string{"f"},
+			Value:   "text",
+			Usage:   "Output format (`text` or `json`)",
+		},
+		&cli.StringFlag{
+			Name:  "fields",
+			Usage: "Only compare these comma-separated `FIELDS`: " + strings.Join(diff.Fields, ", ") + " (default: all)",
+		},
+	}
+	diffFlags = append(diffFlags, displayFlags...)
+
+	// Version command flags
+	versionFlags := []cli.Flag{
+		&cli.StringFlag{
+			Name:    "format",
+			Aliases: []string{"f"},
+			Value:   "text",
+			Usage:   "Output format (`text` or `json`)",
+		},
+	}
+
+	return &cli.App{
 		Name:      "nextr4y",
 		Usage:     "Uncover the hidden internals of Next.js sites.",
 		UsageText: "nextr4y [command] [command options] [arguments...]",
@@ -189,26 +1083,58 @@ func main() {
 			{
 				Name:      "scan",
 				Usage:     "Scan a Next.js site",
-				UsageText: "nextr4y scan [options] <target_url>",
+				UsageText: "nextr4y scan [options] <target_url | ->",
 				Flags:     scanFlags,
+				Before:    beforeCommand,
 				Action:    scanAction,
 			},
+			{
+				Name:      "manifest",
+				Usage:     "Read the routes of a build manifest from its URL, without fetching the page",
+				UsageText: "nextr4y manifest [options] <manifest_url>",
+				Flags:     manifestFlags,
+				Before:    beforeCommand,
+				Action:    manifestAction,
+			},
+			{
+				Name:      "diff",
+				Usage:     "Compare two scan results saved with 'scan -f json'",
+				UsageText: "nextr4y diff [options] <old.json> <new.json>",
+				Flags:     diffFlags,
+				Before:    beforeCommand,
+				Action:    diffAction,
+			},
 			{
 				Name:      "serve",
 				Usage:     "Start an MCP server to handle nextr4y scan requests",
 				UsageText: "nextr4y serve [options]",
 				Flags:     serveFlags,
+				Before:    beforeCommand,
 				Action:    serveAction,
 			},
+			{
+				Name:      "version",
+				Usage:     "Print the version, commit and build date of nextr4y",
+				UsageText: "nextr4y version [options]",
+				Flags:     versionFlags,
+				Action:    versionAction,
+			},
 		},
 		// Show help when no command is specified instead of defaulting to scan
 		Action: func(c *cli.Context) error {
+			if err := beforeCommand(c); err != nil {
+				return err
+			}
 			cli.ShowAppHelp(c)
-			return cli.Exit("No command specified. Please provide a command (scan or serve).", 1)
+			return cli.Exit("No command specified. Please provide a command (scan, manifest, diff, serve or version).", 1)
 		},
 		// We still need flags in case -h or --help is used
-		Flags: []cli.Flag{},
+		Flags: displayFlags,
 	}
+}
+
+func main() {
+	app := newApp()
 
 	// Customize Help Printer
 	cli.AppHelpTemplate = fmt.Sprintf(`%s
@@ -216,7 +1142,10 @@ func main() {
    nextr4y scan https://example.com
    nextr4y scan -f json -o results.json https://vercel.com
    nextr4y scan -b https://cdn.example.com https://example.com
+   nextr4y manifest https://example.com/_next/static/abc123/_buildManifest.js
+   nextr4y diff old.json new.json
    nextr4y serve -p 8080
+   nextr4y version -f json
 `)
 
 	err := app.Run(os.Args)
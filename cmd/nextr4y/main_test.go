@@ -0,0 +1,446 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+const nextPage = `<html><head>
+<script src="/_next/static/chunks/main-abc123.js"></script>
+</head><body><div id="__next"></div>
+<script id="__NEXT_DATA__" type="application/json">{"props":{"pageProps":{}},"page":"/","buildId":"V1StGXR8_Z5jdHi6B-myT"}</script>
+</body></html>`
+
+// captureStdout runs fn with os.Stdout redirected and returns what it printed.
+func captureStdout(t *testing.T, fn func()) []byte {
+	t.Helper()
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	output := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(r)
+		output <- data
+	}()
+	fn()
+	w.Close()
+	return <-output
+}
+
+// captureStderr runs fn and returns what it wrote to stderr.
+func captureStderr(t *testing.T, fn func()) []byte {
+	t.Helper()
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	stderr := os.Stderr
+	os.Stderr = w
+	defer func() { os.Stderr = stderr }()
+
+	output := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(r)
+		output <- data
+	}()
+	fn()
+	w.Close()
+	return <-output
+}
+
+// servePage starts a server answering page at / and 404 elsewhere.
+func servePage(t *testing.T, page string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(page))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// setStdin replaces os.Stdin with a file holding content for the rest of
+// the test.
+func setStdin(t *testing.T, content string) {
+	t.Helper()
+	stdin, err := os.CreateTemp(t.TempDir(), "stdin")
+	require.NoError(t, err)
+	_, err = stdin.WriteString(content)
+	require.NoError(t, err)
+	_, err = stdin.Seek(0, io.SeekStart)
+	require.NoError(t, err)
+	original := os.Stdin
+	os.Stdin = stdin
+	t.Cleanup(func() {
+		os.Stdin = original
+		stdin.Close()
+	})
+}
+
+// runApp runs the CLI with args and returns what it printed to stdout and
+// its exit code.
+func runApp(t *testing.T, args ...string) (stdout []byte, exitCode int) {
+	t.Helper()
+	exiter := cli.OsExiter
+	cli.OsExiter = func(code int) { exitCode = code }
+	defer func() { cli.OsExiter = exiter }()
+
+	stdout = captureStdout(t, func() {
+		_ = newApp().Run(append([]string{"nextr4y"}, args...))
+	})
+	return stdout, exitCode
+}
+
+func TestScan_JSONStdoutIsOnlyJSON(t *testing.T) {
+	// The first TLS profile is refused, so that the fetcher reports falling
+	// back to the next one
+	var pageRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		if pageRequests.Add(1) == 1 {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(nextPage))
+	}))
+	defer server.Close()
+
+	// Scan errors (the stub site has no build manifest) exit through OsExiter
+	exiter := cli.OsExiter
+	cli.OsExiter = func(int) {}
+	defer func() { cli.OsExiter = exiter }()
+
+	stdout := captureStdout(t, func() {
+		_ = newApp().Run([]string{"nextr4y", "scan", "-q", "-f", "json", server.URL})
+	})
+
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(stdout, &result), "stdout is not JSON:\n%s", stdout)
+	require.Equal(t, true, result["IsNextJS"])
+	require.Equal(t, "V1StGXR8_Z5jdHi6B-myT", result["BuildID"])
+}
+
+func TestScan_TableOutput(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(nextPage))
+	}))
+	defer server.Close()
+
+	exiter := cli.OsExiter
+	cli.OsExiter = func(int) {}
+	defer func() { cli.OsExiter = exiter }()
+
+	stdout := captureStdout(t, func() {
+		_ = newApp().Run([]string{"nextr4y", "scan", "-q", "-f", "table", "--no-versions", server.URL})
+	})
+
+	lines := strings.Split(strings.TrimSpace(string(stdout)), "\n")
+	require.Len(t, lines, 2, "expected a header and a row:\n%s", stdout)
+	require.True(t, strings.HasPrefix(lines[0], "HOST "))
+	require.Contains(t, lines[1], "V1StGXR8_Z5jdHi6B-myT")
+}
+
+func TestScan_Banner(t *testing.T) {
+	terminal := stderrIsTerminal
+	stderrIsTerminal = func() bool { return true }
+	defer func() { stderrIsTerminal = terminal }()
+	server := servePage(t, nextPage)
+	const banner = "github.com/rodrigopv/nextr4y"
+
+	for _, tt := range []struct {
+		name   string
+		args   []string
+		banner bool
+	}{
+		{"default", nil, true},
+		{"no-banner", []string{"--no-banner"}, false},
+		{"json", []string{"-f", "json"}, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"scan", "-q", "--no-versions"}, tt.args...)
+			stderr := captureStderr(t, func() {
+				runApp(t, append(args, server.URL)...)
+			})
+			if tt.banner {
+				require.Contains(t, string(stderr), banner)
+			} else {
+				require.NotContains(t, string(stderr), banner)
+			}
+		})
+	}
+}
+
+func TestScan_CircuitBreakerSkipsFailingHost(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(nextPage))
+	}))
+	defer server.Close()
+	dead := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer dead.Close()
+	deadURL := dead.URL
+
+	stdin, err := os.CreateTemp(t.TempDir(), "targets")
+	require.NoError(t, err)
+	_, err = stdin.WriteString(strings.Join([]string{deadURL + "/a", deadURL + "/b", deadURL + "/c", server.URL}, "\n"))
+	require.NoError(t, err)
+	_, err = stdin.Seek(0, io.SeekStart)
+	require.NoError(t, err)
+	defer stdin.Close()
+	originalStdin := os.Stdin
+	os.Stdin = stdin
+	defer func() { os.Stdin = originalStdin }()
+
+	exiter := cli.OsExiter
+	cli.OsExiter = func(int) {}
+	defer func() { cli.OsExiter = exiter }()
+
+	stdout := captureStdout(t, func() {
+		_ = newApp().Run([]string{"nextr4y", "scan", "-q", "--circuit-breaker", "1", "--output-dir", t.TempDir(), "-"})
+	})
+
+	require.Contains(t, string(stdout), "2 skipped (failing hosts)")
+	require.Contains(t, string(stdout), deadURL+"/b (skipped, host failing)")
+	require.Contains(t, string(stdout), deadURL+"/c (skipped, host failing)")
+}
+
+func TestScan_CircuitBreakerIgnoresProbes(t *testing.T) {
+	// The page is served, but every other route is forbidden
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(nextPage))
+	}))
+	defer server.Close()
+
+	stdin, err := os.CreateTemp(t.TempDir(), "targets")
+	require.NoError(t, err)
+	_, err = stdin.WriteString(server.URL + "/\n" + server.URL + "/?again\n")
+	require.NoError(t, err)
+	_, err = stdin.Seek(0, io.SeekStart)
+	require.NoError(t, err)
+	defer stdin.Close()
+	originalStdin := os.Stdin
+	os.Stdin = stdin
+	defer func() { os.Stdin = originalStdin }()
+
+	exiter := cli.OsExiter
+	cli.OsExiter = func(int) {}
+	defer func() { cli.OsExiter = exiter }()
+
+	stdout := captureStdout(t, func() {
+		_ = newApp().Run([]string{"nextr4y", "scan", "-q", "--probe-preview", "--no-versions", "--circuit-breaker", "1", "--output-dir", t.TempDir(), "-"})
+	})
+
+	require.NotContains(t, string(stdout), "skipped")
+}
+
+func TestScan_RateSharedAcrossTargets(t *testing.T) {
+	var mu sync.Mutex
+	var times []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		times = append(times, time.Now())
+		mu.Unlock()
+		w.Write([]byte(nextPage))
+	}))
+	defer server.Close()
+	setStdin(t, strings.Join([]string{server.URL + "/a", server.URL + "/b", server.URL + "/c"}, "\n"))
+
+	runApp(t, "scan", "-q", "--no-versions", "--rate", "20", "--output-dir", t.TempDir(), "-")
+
+	// The first request of a target waits for the budget left by the previous one
+	mu.Lock()
+	defer mu.Unlock()
+	require.Greater(t, len(times), 3)
+	for i := 1; i < len(times); i++ {
+		require.GreaterOrEqual(t, times[i].Sub(times[i-1]), 40*time.Millisecond, "request %d", i)
+	}
+}
+
+func TestVersion_JSON(t *testing.T) {
+	stdout := captureStdout(t, func() {
+		require.NoError(t, newApp().Run([]string{"nextr4y", "version", "-f", "json"}))
+	})
+
+	var info buildInfo
+	require.NoError(t, json.Unmarshal(stdout, &info), "stdout is not JSON:\n%s", stdout)
+	require.Equal(t, buildInfo{Version: version, Commit: commit, Date: date, GoVersion: runtime.Version()}, info)
+}
+
+func TestScan_OnlyNextJS(t *testing.T) {
+	other := servePage(t, `<html><body><h1>Hello</h1></body></html>`)
+	next := servePage(t, nextPage)
+
+	stdout, exitCode := runApp(t, "scan", "-q", "-f", "json", "--no-versions", "--only-nextjs", other.URL)
+	require.Empty(t, stdout)
+	require.Equal(t, exitNotNextJS, exitCode, "the exit code still reports the outcome")
+
+	stdout, _ = runApp(t, "scan", "-q", "-f", "json", "--no-versions", "--only-nextjs", next.URL)
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(stdout, &result), "stdout is not JSON:\n%s", stdout)
+	require.Equal(t, true, result["IsNextJS"])
+}
+
+func TestScan_TargetsFromStdin(t *testing.T) {
+	first := servePage(t, nextPage)
+	second := servePage(t, nextPage)
+	setStdin(t, "\n"+first.URL+"\n  \n  "+second.URL+"  \n")
+
+	stdout, exitCode := runApp(t, "scan", "-q", "-f", "json", "--no-versions", "-")
+
+	// One compact JSON result per target (NDJSON), blank lines skipped
+	lines := strings.Split(strings.TrimSpace(string(stdout)), "\n")
+	require.Len(t, lines, 2, "expected one line per target:\n%s", stdout)
+	for i, server := range []*httptest.Server{first, second} {
+		var result map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(lines[i]), &result))
+		require.Equal(t, server.URL, strings.TrimSuffix(result["BaseURL"].(string), "/"))
+	}
+	require.Equal(t, exitScanErrors, exitCode, "the stub sites have no build manifest")
+
+	setStdin(t, "\n\n")
+	_, exitCode = runApp(t, "scan", "-q", "-")
+	require.Equal(t, exitUsage, exitCode)
+}
+
+func TestScan_Tee(t *testing.T) {
+	server := servePage(t, nextPage)
+	outputFile := filepath.Join(t.TempDir(), "result.json")
+
+	stdout, _ := runApp(t, "scan", "-q", "-f", "json", "--no-versions", "-o", outputFile, "--tee", server.URL)
+
+	written, err := os.ReadFile(outputFile)
+	require.NoError(t, err)
+	require.JSONEq(t, string(written), string(stdout), "stdout and the file hold the same result")
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(stdout, &result), "stdout is not JSON:\n%s", stdout)
+	require.Equal(t, "V1StGXR8_Z5jdHi6B-myT", result["BuildID"])
+
+	// Without --tee, stdout stays empty
+	stdout, _ = runApp(t, "scan", "-q", "-f", "json", "--no-versions", "-o", outputFile, server.URL)
+	require.Empty(t, stdout)
+
+	_, exitCode := runApp(t, "scan", "-q", "--tee", server.URL)
+	require.Equal(t, exitUsage, exitCode, "--tee requires --output")
+}
+
+// headerServer serves nextPage at / and sends the headers of each request
+// for it on the returned channel.
+func headerServer(t *testing.T) (*httptest.Server, <-chan http.Header) {
+	t.Helper()
+	headers := make(chan http.Header, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		select {
+		case headers <- r.Header.Clone():
+		default:
+		}
+		w.Write([]byte(nextPage))
+	}))
+	t.Cleanup(server.Close)
+	return server, headers
+}
+
+func TestScan_AcceptLanguage(t *testing.T) {
+	server, headers := headerServer(t)
+
+	runApp(t, "scan", "-q", "-f", "json", "--no-versions", "--accept-language", "fr-FR,fr;q=0.9", server.URL)
+	require.Equal(t, "fr-FR,fr;q=0.9", (<-headers).Get("Accept-Language"))
+
+	_, exitCode := runApp(t, "scan", "-q", "--accept-language", "fr", "-H", "accept-language: de", server.URL)
+	require.Equal(t, exitUsage, exitCode, "conflicting Accept-Language header")
+	require.Empty(t, headers, "nothing is requested after a usage error")
+}
+
+func TestScan_BasicAuth(t *testing.T) {
+	server, headers := headerServer(t)
+
+	runApp(t, "scan", "-q", "-f", "json", "--no-versions", "--basic-auth", "alice:s3cr:et", server.URL)
+	request := &http.Request{Header: <-headers}
+	user, password, ok := request.BasicAuth()
+	require.True(t, ok)
+	require.Equal(t, "alice", user)
+	require.Equal(t, "s3cr:et", password)
+
+	_, exitCode := runApp(t, "scan", "-q", "--basic-auth", "alice", server.URL)
+	require.Equal(t, exitUsage, exitCode, "credentials without a colon")
+
+	_, exitCode = runApp(t, "scan", "-q", "--basic-auth", "alice:pass", "-H", "Authorization: Bearer abc", server.URL)
+	require.Equal(t, exitUsage, exitCode, "conflicting Authorization header")
+	require.Empty(t, headers, "nothing is requested after a usage error")
+}
+
+func TestConfigureColor(t *testing.T) {
+	noColor := color.NoColor
+	t.Cleanup(func() { color.NoColor = noColor })
+
+	// runScanCommand runs the scan command with its action replaced, so only
+	// the flags are applied
+	runScanCommand := func(args ...string) int {
+		exiter := cli.OsExiter
+		exitCode := 0
+		cli.OsExiter = func(code int) { exitCode = code }
+		defer func() { cli.OsExiter = exiter }()
+
+		app := newApp()
+		app.Command("scan").Action = func(*cli.Context) error { return nil }
+		_ = app.Run(append([]string{"nextr4y"}, args...))
+		return exitCode
+	}
+
+	for _, tc := range []struct {
+		name    string
+		noColor string // NO_COLOR environment variable
+		args    []string
+		want    bool // Expected color.NoColor
+	}{
+		{"never", "", []string{"scan", "--color", "never", "x"}, true},
+		{"no-color flag", "", []string{"scan", "--no-color", "x"}, true},
+		{"NO_COLOR", "1", []string{"scan", "x"}, true},
+		{"always overrides NO_COLOR", "1", []string{"scan", "--color", "always", "x"}, false},
+		{"command flag overrides global one", "", []string{"--color", "always", "scan", "--no-color", "x"}, true},
+		{"global flag", "", []string{"--color", "always", "scan", "x"}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", tc.noColor)
+			color.NoColor = !tc.want
+
+			require.Zero(t, runScanCommand(tc.args...))
+			require.Equal(t, tc.want, color.NoColor)
+		})
+	}
+
+	require.Equal(t, exitUsage, runScanCommand("scan", "--color", "sometimes", "x"))
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// outputExtensions maps each output format to the extension of the files
+// written with --output-dir.
+var outputExtensions = map[string]string{
+	"json":     ".json",
+	"text":     ".txt",
+	"markdown": ".md",
+	"routes":   ".txt",
+	"table":    ".txt",
+}
+
+// outputNamer assigns every target of a batch its own file in an output
+// directory, named after the target host. Hosts scanned several times get a
+// counter appended ("example.com-2.json").
+type outputNamer struct {
+	dir  string
+	ext  string
+	used map[string]bool
+}
+
+// newOutputNamer creates an outputNamer for files of the given format in dir.
+func newOutputNamer(dir, outputFormat string) *outputNamer {
+	return &outputNamer{dir: dir, ext: outputExtensions[outputFormat], used: make(map[string]bool)}
+}
+
+// path returns the file the result of targetURL is written to.
+func (n *outputNamer) path(targetURL string) string {
+	host := sanitizeHost(targetURL)
+	name := host
+	for i := 2; n.used[name]; i++ {
+		name = fmt.Sprintf("%s-%d", host, i)
+	}
+	n.used[name] = true
+	return filepath.Join(n.dir, name+n.ext)
+}
+
+// sanitizeHost turns the host of a target, given with or without a scheme,
+// into a safe file name: lowercase letters, digits, dots and dashes, with the
+// port separated by an underscore ("example.com_8080").
+func sanitizeHost(targetURL string) string {
+	raw := strings.TrimSpace(targetURL)
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	host := targetURL
+	if u, err := url.Parse(raw); err == nil {
+		host = u.Host
+	}
+
+	var sb strings.Builder
+	for _, r := range strings.ToLower(host) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '.', r == '-':
+			sb.WriteRune(r)
+		default:
+			sb.WriteRune('_')
+		}
+	}
+	name := strings.Trim(sb.String(), "._")
+	if name == "" {
+		return "target"
+	}
+	return name
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// progressBarWidth is the number of cells of the progress bar.
+const progressBarWidth = 20
+
+// progressReporter draws a single status line ("Fetching asset 5/40") at the
+// bottom of a terminal. Log output is routed through it so the line is cleared
+// before each log message and redrawn after it, instead of being interleaved.
+type progressReporter struct {
+	mu   sync.Mutex
+	out  io.Writer
+	line string
+}
+
+// newProgressReporter creates a progressReporter drawing on out, which must be a terminal.
+func newProgressReporter(out io.Writer) *progressReporter {
+	return &progressReporter{out: out}
+}
+
+// Update redraws the status line. Its signature matches nextr4y.Options.Progress.
+func (p *progressReporter) Update(stage string, done, total int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	filled := 0
+	if total > 0 {
+		filled = min(done, total) * progressBarWidth / total
+	}
+	bar := strings.Repeat("#", filled) + strings.Repeat(".", progressBarWidth-filled)
+	p.line = fmt.Sprintf("[%s] %s %d/%d", bar, stage, done, total)
+	fmt.Fprintf(p.out, "\r\033[K%s", p.line)
+}
+
+// Write implements io.Writer so the reporter can be used as the log output.
+func (p *progressReporter) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.line == "" {
+		return p.out.Write(b)
+	}
+	fmt.Fprint(p.out, "\r\033[K")
+	n, err := p.out.Write(b)
+	fmt.Fprint(p.out, p.line)
+	return n, err
+}
+
+// Done clears the status line.
+func (p *progressReporter) Done() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.line != "" {
+		fmt.Fprint(p.out, "\r\033[K")
+		p.line = ""
+	}
+}
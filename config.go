@@ -0,0 +1,300 @@
+package nextr4y
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rodrigopv/nextr4y/internal/fetch"
+	"github.com/rodrigopv/nextr4y/internal/scanner"
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds default scan options read from a YAML file by LoadConfig, and
+// applied to Options with Apply. Keys are the long names of the CLI flags
+// setting the same options ("timeout: 20s", "header: [...]"), and values mean
+// what they do on the command line: e.g. "max-redirects: 0" follows no
+// redirect. Options missing from the file are nil and left unchanged.
+type Config struct {
+	BaseURL               *string        `yaml:"base-url"`
+	AssetHostOverride     *string        `yaml:"asset-host-override"`
+	Timeout               *time.Duration `yaml:"timeout"`
+	ManifestTimeout       *time.Duration `yaml:"manifest-exec-timeout"`
+	Header                []string       `yaml:"header"` // "Name: Value" entries, like --header
+	BasicAuth             *string        `yaml:"basic-auth"`
+	AcceptLanguage        *string        `yaml:"accept-language"`
+	MaxRedirects          *int           `yaml:"max-redirects"`
+	CookieJar             *bool          `yaml:"cookie-jar"`
+	Cookies               *string        `yaml:"cookies"`
+	Insecure              *bool          `yaml:"insecure"`
+	RotateUserAgent       *bool          `yaml:"rotate-ua"`
+	Seed                  *int64         `yaml:"seed"`
+	ProfileDelay          *time.Duration `yaml:"profile-delay"`
+	HTTPVersion           *string        `yaml:"http-version"`
+	Resolve               []string       `yaml:"resolve"` // "host:ip" or "host:port:ip" entries, like --resolve
+	Scheme                *string        `yaml:"scheme"`
+	Render                *bool          `yaml:"render"`
+	HashAssets            *bool          `yaml:"hash-assets"`
+	Discover              *bool          `yaml:"discover"`
+	Metadata              *bool          `yaml:"metadata"`
+	IncludeRaw            *bool          `yaml:"include-raw"`
+	ExtractEndpoints      *bool          `yaml:"extract-endpoints"`
+	ExtractKeys           *bool          `yaml:"extract-keys"`
+	DetectInstrumentation *bool          `yaml:"detect-instrumentation"`
+	AssetPattern          *string        `yaml:"asset-pattern"`
+	ProbeData             *bool          `yaml:"probe-data"`
+	ProbeImage            *bool          `yaml:"probe-image"`
+	ProbePreview          *bool          `yaml:"probe-preview"`
+	Bruteforce            *bool          `yaml:"bruteforce"`
+	SkipVersions          *bool          `yaml:"no-versions"`
+	IncludeErrors         *bool          `yaml:"include-errors"`
+	IncludeHeaders        *bool          `yaml:"include-headers"`
+	Debug                 *bool          `yaml:"debug"`
+	MaxAssets             *int           `yaml:"max-assets"`
+	MaxBodySize           *int64         `yaml:"max-body-size"`
+	CacheDir              *string        `yaml:"cache-dir"`
+	CacheTTL              *time.Duration `yaml:"cache-ttl"`
+	RateLimit             *float64       `yaml:"rate"`
+}
+
+// LoadConfig reads the YAML config file at path. Unknown keys are reported as
+// an error, as are values of the wrong type. Errors reading the file wrap the
+// os error, so a missing file can be told apart with errors.Is(err,
+// fs.ErrNotExist).
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var values map[string]interface{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %v", path, err)
+	}
+	known := configKeys()
+	var unknown []string
+	for key := range values {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown option(s) in config file %s: %s", path, strings.Join(unknown, ", "))
+	}
+
+	cfg := &Config{}
+	if len(values) == 0 {
+		return cfg, nil // yaml.v3 reports an empty document as EOF
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %v", path, err)
+	}
+	return cfg, nil
+}
+
+// configKeys returns the keys accepted in a config file, from the yaml tags
+// of Config.
+func configKeys() map[string]bool {
+	t := reflect.TypeOf(Config{})
+	keys := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		keys[t.Field(i).Tag.Get("yaml")] = true
+	}
+	return keys
+}
+
+// Apply sets the options of opts given in the config. When skip is not nil,
+// the options whose key it reports are left alone, e.g. to let the flags set
+// on the command line take precedence. Headers from the config (including
+// basic-auth and accept-language) are only added when opts has none of the
+// same name, so programs apply the config first and then set their own
+// values, or set them first and pass skip.
+func (c *Config) Apply(opts *Options, skip func(key string) bool) error {
+	apply := func(key string, set bool) bool {
+		return set && (skip == nil || !skip(key))
+	}
+
+	if apply("base-url", c.BaseURL != nil) {
+		if _, err := scanner.NormalizeBaseURL(*c.BaseURL); err != nil {
+			return fmt.Errorf("invalid base-url in config file: %w", err)
+		}
+		opts.BaseURL = *c.BaseURL
+	}
+	if apply("asset-host-override", c.AssetHostOverride != nil) {
+		opts.AssetHostOverride = *c.AssetHostOverride
+	}
+	if apply("timeout", c.Timeout != nil) {
+		opts.Timeout = *c.Timeout
+	}
+	if apply("manifest-exec-timeout", c.ManifestTimeout != nil) {
+		opts.ManifestTimeout = *c.ManifestTimeout
+	}
+	if apply("header", c.Header != nil) {
+		headers, err := ParseHeaders(c.Header)
+		if err != nil {
+			return fmt.Errorf("invalid header in config file: %w", err)
+		}
+		for name, value := range headers {
+			addDefaultHeader(opts, name, value)
+		}
+	}
+	if apply("basic-auth", c.BasicAuth != nil) {
+		if user, _, found := strings.Cut(*c.BasicAuth, ":"); !found || user == "" {
+			return fmt.Errorf("invalid basic-auth in config file, expected 'user:pass'")
+		}
+		addDefaultHeader(opts, "Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(*c.BasicAuth)))
+	}
+	if apply("accept-language", c.AcceptLanguage != nil) {
+		addDefaultHeader(opts, "Accept-Language", *c.AcceptLanguage)
+	}
+	if apply("max-redirects", c.MaxRedirects != nil) {
+		opts.MaxRedirects = *c.MaxRedirects
+		if opts.MaxRedirects <= 0 {
+			opts.MaxRedirects = -1 // Do not follow redirects
+		}
+	}
+	if apply("cookie-jar", c.CookieJar != nil) {
+		opts.CookieJar = *c.CookieJar
+	}
+	if apply("cookies", c.Cookies != nil) {
+		cookies, err := http.ParseCookie(*c.Cookies)
+		if err != nil {
+			return fmt.Errorf("invalid cookies in config file, expected 'name=value; ...': %w", err)
+		}
+		opts.Cookies = cookies
+	}
+	if apply("insecure", c.Insecure != nil) {
+		opts.Insecure = *c.Insecure
+	}
+	if apply("rotate-ua", c.RotateUserAgent != nil) {
+		opts.RotateUserAgent = *c.RotateUserAgent
+	}
+	if apply("seed", c.Seed != nil) {
+		opts.Seed = *c.Seed
+	}
+	if apply("profile-delay", c.ProfileDelay != nil) {
+		opts.ProfileDelay = *c.ProfileDelay
+	}
+	if apply("http-version", c.HTTPVersion != nil) {
+		if _, err := fetch.NormalizeHTTPVersion(*c.HTTPVersion); err != nil {
+			return fmt.Errorf("invalid http-version in config file: %w", err)
+		}
+		opts.HTTPVersion = *c.HTTPVersion
+	}
+	if apply("resolve", c.Resolve != nil) {
+		resolve, err := fetch.ParseResolve(c.Resolve)
+		if err != nil {
+			return fmt.Errorf("invalid resolve in config file: %w", err)
+		}
+		opts.Resolve = resolve
+	}
+	if apply("scheme", c.Scheme != nil) {
+		switch *c.Scheme {
+		case "auto":
+			opts.Scheme = scanner.SchemeAuto
+		case scanner.SchemeHTTPS, scanner.SchemeHTTP:
+			opts.Scheme = *c.Scheme
+		default:
+			return fmt.Errorf("invalid scheme '%s' in config file, use 'auto', 'https' or 'http'", *c.Scheme)
+		}
+	}
+	if apply("asset-pattern", c.AssetPattern != nil) {
+		pattern, err := regexp.Compile(*c.AssetPattern)
+		if err != nil {
+			return fmt.Errorf("invalid asset-pattern in config file: %w", err)
+		}
+		opts.AssetPattern = pattern
+	}
+
+	for _, option := range []struct {
+		key   string
+		value *bool
+		field *bool
+	}{
+		{"render", c.Render, &opts.Render},
+		{"hash-assets", c.HashAssets, &opts.HashAssets},
+		{"discover", c.Discover, &opts.Discover},
+		{"metadata", c.Metadata, &opts.Metadata},
+		{"include-raw", c.IncludeRaw, &opts.IncludeRaw},
+		{"extract-endpoints", c.ExtractEndpoints, &opts.ExtractEndpoints},
+		{"extract-keys", c.ExtractKeys, &opts.ExtractKeys},
+		{"detect-instrumentation", c.DetectInstrumentation, &opts.DetectInstrumentation},
+		{"probe-data", c.ProbeData, &opts.ProbeData},
+		{"probe-image", c.ProbeImage, &opts.ProbeImage},
+		{"probe-preview", c.ProbePreview, &opts.ProbePreview},
+		{"bruteforce", c.Bruteforce, &opts.Bruteforce},
+		{"no-versions", c.SkipVersions, &opts.SkipVersions},
+		{"include-errors", c.IncludeErrors, &opts.IncludeErrors},
+		{"include-headers", c.IncludeHeaders, &opts.IncludeHeaders},
+		{"debug", c.Debug, &opts.Debug},
+	} {
+		if apply(option.key, option.value != nil) {
+			*option.field = *option.value
+		}
+	}
+
+	if apply("max-assets", c.MaxAssets != nil) {
+		opts.MaxAssets = *c.MaxAssets
+		if opts.MaxAssets <= 0 {
+			opts.MaxAssets = -1 // No limit
+		}
+	}
+	if apply("max-body-size", c.MaxBodySize != nil) {
+		opts.MaxBodySize = *c.MaxBodySize
+		if opts.MaxBodySize <= 0 {
+			opts.MaxBodySize = -1 // No limit
+		}
+	}
+	if apply("cache-dir", c.CacheDir != nil) {
+		opts.CacheDir = *c.CacheDir
+	}
+	if apply("cache-ttl", c.CacheTTL != nil) {
+		opts.CacheTTL = *c.CacheTTL
+		if opts.CacheTTL <= 0 {
+			opts.CacheTTL = -1 // Never expires
+		}
+	}
+	if apply("rate", c.RateLimit != nil) {
+		opts.RateLimit = *c.RateLimit
+	}
+	return nil
+}
+
+// addDefaultHeader sets the header name of opts to value, unless opts
+// already has a header of that name.
+func addDefaultHeader(opts *Options, name, value string) {
+	for existing := range opts.Headers {
+		if strings.EqualFold(existing, name) {
+			return
+		}
+	}
+	if opts.Headers == nil {
+		opts.Headers = make(map[string]string)
+	}
+	opts.Headers[name] = value
+}
+
+// ParseHeaders converts "Name: Value" entries, as given to the CLI's --header
+// flag, into a header map for Options.Headers.
+func ParseHeaders(values []string) (map[string]string, error) {
+	headers := make(map[string]string, len(values))
+	for _, v := range values {
+		name, value, found := strings.Cut(v, ":")
+		name = strings.TrimSpace(name)
+		if !found || name == "" {
+			return nil, fmt.Errorf("invalid header '%s', expected 'Name: Value'", v)
+		}
+		headers[name] = strings.TrimSpace(value)
+	}
+	return headers, nil
+}
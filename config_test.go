@@ -0,0 +1,49 @@
+package nextr4y
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Apply(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("timeout: 20s\nmax-redirects: 0\nrate: 5\nno-versions: true\nheader:\n  - 'X-Team: red'\n  - 'Accept-Language: fr'\n"), 0o644))
+	cfg, err := LoadConfig(path)
+	require.NoError(t, err)
+
+	opts := Options{Timeout: 5 * time.Second, Headers: map[string]string{"accept-language": "de"}}
+	require.NoError(t, cfg.Apply(&opts, func(key string) bool { return key == "timeout" }))
+
+	require.Equal(t, 5*time.Second, opts.Timeout, "skipped")
+	require.Equal(t, -1, opts.MaxRedirects, "0 follows no redirect, as on the command line")
+	require.Equal(t, 5.0, opts.RateLimit)
+	require.True(t, opts.SkipVersions)
+	require.Equal(t, map[string]string{"accept-language": "de", "X-Team": "red"}, opts.Headers)
+}
+
+func TestLoadConfig_Errors(t *testing.T) {
+	t.Parallel()
+
+	write := func(content string) string {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		return path
+	}
+
+	_, err := LoadConfig(write("format: json\ntimeout: 20s\n"))
+	require.ErrorContains(t, err, "unknown option(s) in config file")
+	require.ErrorContains(t, err, ": format")
+
+	_, err = LoadConfig(write("timeout: soon\n"))
+	require.ErrorContains(t, err, "invalid config file")
+
+	cfg, err := LoadConfig(write("# nothing set\n"))
+	require.NoError(t, err)
+	require.Equal(t, &Config{}, cfg)
+}
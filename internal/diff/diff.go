@@ -0,0 +1,264 @@
+// Package diff compares two saved scan results to track a target's deployments.
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/rodrigopv/nextr4y/internal/scanner"
+)
+
+// Change records a value that differs between the old and new scan.
+type Change struct {
+	Old string
+	New string
+}
+
+// Report lists everything that changed between two scans.
+// Fields for values that did not change are left nil or empty.
+type Report struct {
+	OldTarget     string
+	NewTarget     string
+	BuildID       *Change
+	NextVersion   *Change
+	ReactVersion  *Change
+	AddedRoutes   []string
+	RemovedRoutes []string
+	AddedAssets   []string
+	RemovedAssets []string
+	ChangedAssets []string // Assets present in both scans whose hash changed (needs --hash-assets on both)
+	NewProps      []string // Paths in __NEXT_DATA__ props that only appear in the new scan
+}
+
+// HasChanges reports whether the two scans differ at all.
+func (r *Report) HasChanges() bool {
+	return r.BuildID != nil || r.NextVersion != nil || r.ReactVersion != nil ||
+		len(r.AddedRoutes) > 0 || len(r.RemovedRoutes) > 0 ||
+		len(r.AddedAssets) > 0 || len(r.RemovedAssets) > 0 ||
+		len(r.ChangedAssets) > 0 || len(r.NewProps) > 0
+}
+
+// Names of the fields a Report can be restricted to with Only.
+const (
+	FieldBuildID = "buildid" // BuildID
+	FieldVersion = "version" // NextVersion and ReactVersion
+	FieldRoutes  = "routes"  // AddedRoutes and RemovedRoutes
+	FieldAssets  = "assets"  // AddedAssets and RemovedAssets
+	FieldHashes  = "hashes"  // ChangedAssets
+	FieldProps   = "props"   // NewProps
+)
+
+// Fields lists every field name, in report order.
+var Fields = []string{FieldBuildID, FieldVersion, FieldRoutes, FieldAssets, FieldHashes, FieldProps}
+
+// ParseFields parses a comma-separated list of field names, e.g.
+// "routes,version". Names are case-insensitive; unknown names are an error.
+func ParseFields(value string) ([]string, error) {
+	var fields []string
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		known := false
+		for _, field := range Fields {
+			if name == field {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("diff: unknown field '%s', expected one of %s", name, strings.Join(Fields, ", "))
+		}
+		fields = append(fields, name)
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("diff: no field given, expected one of %s", strings.Join(Fields, ", "))
+	}
+	return fields, nil
+}
+
+// Only returns a copy of the report keeping the changes of the given fields
+// (see Fields) and dropping the others, e.g. to ignore asset hashes that
+// change with every build.
+func (r *Report) Only(fields []string) *Report {
+	keep := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		keep[field] = true
+	}
+	filtered := &Report{OldTarget: r.OldTarget, NewTarget: r.NewTarget}
+	if keep[FieldBuildID] {
+		filtered.BuildID = r.BuildID
+	}
+	if keep[FieldVersion] {
+		filtered.NextVersion, filtered.ReactVersion = r.NextVersion, r.ReactVersion
+	}
+	if keep[FieldRoutes] {
+		filtered.AddedRoutes, filtered.RemovedRoutes = r.AddedRoutes, r.RemovedRoutes
+	}
+	if keep[FieldAssets] {
+		filtered.AddedAssets, filtered.RemovedAssets = r.AddedAssets, r.RemovedAssets
+	}
+	if keep[FieldHashes] {
+		filtered.ChangedAssets = r.ChangedAssets
+	}
+	if keep[FieldProps] {
+		filtered.NewProps = r.NewProps
+	}
+	return filtered
+}
+
+// LoadResult reads a ScanResult previously written with `scan -f json`.
+func LoadResult(path string) (*scanner.ScanResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("diff: failed to read '%s': %w", path, err)
+	}
+
+	result, err := scanner.DecodeResult(data)
+	if err != nil {
+		return nil, fmt.Errorf("diff: '%s' is not a valid scan result JSON file: %w", path, err)
+	}
+	// Results written before SchemaVersion existed have none, and the 1.x shape
+	if result.SchemaVersion != "" && majorVersion(result.SchemaVersion) != majorVersion(scanner.SchemaVersion) {
+		return nil, fmt.Errorf("diff: '%s' has schema version %s, incompatible with the current %s", path, result.SchemaVersion, scanner.SchemaVersion)
+	}
+	return result, nil
+}
+
+// majorVersion returns the major part of a "major.minor" schema version.
+func majorVersion(schemaVersion string) string {
+	major, _, _ := strings.Cut(schemaVersion, ".")
+	return major
+}
+
+// Compare reports the differences between an older and a newer scan.
+func Compare(oldResult, newResult *scanner.ScanResult) *Report {
+	report := &Report{
+		OldTarget:    oldResult.BaseURL,
+		NewTarget:    newResult.BaseURL,
+		BuildID:      compareValue(oldResult.BuildID, newResult.BuildID),
+		NextVersion:  compareValue(oldResult.DetectedNextVersion, newResult.DetectedNextVersion),
+		ReactVersion: compareValue(oldResult.DetectedReactVersion, newResult.DetectedReactVersion),
+	}
+
+	report.AddedRoutes, report.RemovedRoutes = compareSets(keys(oldResult.Routes), keys(newResult.Routes))
+	report.AddedAssets, report.RemovedAssets = compareSets(oldResult.AllAssets, newResult.AllAssets)
+
+	for asset, newHash := range newResult.AssetHashes {
+		if oldHash, ok := oldResult.AssetHashes[asset]; ok && oldHash != newHash {
+			report.ChangedAssets = append(report.ChangedAssets, asset)
+		}
+	}
+	sort.Strings(report.ChangedAssets)
+
+	report.NewProps, _ = compareSets(propPaths(oldResult.NextDataJSONRaw), propPaths(newResult.NextDataJSONRaw))
+
+	return report
+}
+
+// compareValue returns a Change if old and new differ.
+func compareValue(oldValue, newValue string) *Change {
+	if oldValue == newValue {
+		return nil
+	}
+	return &Change{Old: oldValue, New: newValue}
+}
+
+// compareSets returns the sorted members only in newSet (added) and only in oldSet (removed).
+func compareSets(oldSet, newSet map[string]bool) (added, removed []string) {
+	for item := range newSet {
+		if !oldSet[item] {
+			added = append(added, item)
+		}
+	}
+	for item := range oldSet {
+		if !newSet[item] {
+			removed = append(removed, item)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+// keys returns the keys of a route map as a set.
+func keys(routes map[string][]string) map[string]bool {
+	set := make(map[string]bool, len(routes))
+	for route := range routes {
+		set[route] = true
+	}
+	return set
+}
+
+// propPaths returns the dotted path of every key under "props" in a raw
+// __NEXT_DATA__ document (e.g. "props.pageProps.user.email").
+// Array elements are collapsed into "[]". Invalid JSON yields an empty set.
+func propPaths(rawNextData string) map[string]bool {
+	paths := make(map[string]bool)
+	if rawNextData == "" {
+		return paths
+	}
+	var nextData map[string]interface{}
+	if err := json.Unmarshal([]byte(rawNextData), &nextData); err != nil {
+		return paths
+	}
+	if props, ok := nextData["props"]; ok {
+		collectPaths("props", props, paths)
+	}
+	return paths
+}
+
+func collectPaths(prefix string, value interface{}, paths map[string]bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			childPath := prefix + "." + key
+			paths[childPath] = true
+			collectPaths(childPath, child, paths)
+		}
+	case []interface{}:
+		for _, child := range v {
+			collectPaths(prefix+"[]", child, paths)
+		}
+	}
+}
+
+// RenderText formats a report for humans.
+func RenderText(r *Report) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Comparing %s -> %s\n", r.OldTarget, r.NewTarget))
+	if !r.HasChanges() {
+		sb.WriteString("No changes detected.\n")
+		return sb.String()
+	}
+
+	writeChange := func(name string, c *Change) {
+		if c != nil {
+			sb.WriteString(fmt.Sprintf("%s: %s -> %s\n", name, c.Old, c.New))
+		}
+	}
+	writeList := func(name, marker string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+		sb.WriteString(fmt.Sprintf("%s (%d):\n", name, len(items)))
+		for _, item := range items {
+			sb.WriteString(fmt.Sprintf("  %s %s\n", marker, item))
+		}
+	}
+
+	writeChange("Build ID", r.BuildID)
+	writeChange("Next.js Version", r.NextVersion)
+	writeChange("React Version", r.ReactVersion)
+	writeList("Added Routes", "+", r.AddedRoutes)
+	writeList("Removed Routes", "-", r.RemovedRoutes)
+	writeList("Added Assets", "+", r.AddedAssets)
+	writeList("Removed Assets", "-", r.RemovedAssets)
+	writeList("Changed Assets", "~", r.ChangedAssets)
+	writeList("New Props", "+", r.NewProps)
+	return sb.String()
+}
@@ -0,0 +1,130 @@
+package diff
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigopv/nextr4y/internal/scanner"
+)
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	oldResult := &scanner.ScanResult{
+		BaseURL:              "https://example.com/",
+		BuildID:              "build-1",
+		DetectedNextVersion:  "13.4.12",
+		DetectedReactVersion: "18.2.0",
+		Routes:               map[string][]string{"/": {"a.js"}, "/about": {"b.js"}},
+		AllAssets:            map[string]bool{"a.js": true, "b.js": true},
+		AssetHashes:          map[string]string{"a.js": "111", "b.js": "222"},
+		NextDataJSONRaw:      `{"props":{"pageProps":{"title":"Home"}}}`,
+	}
+	newResult := &scanner.ScanResult{
+		BaseURL:              "https://example.com/",
+		BuildID:              "build-2",
+		DetectedNextVersion:  "13.4.12",
+		DetectedReactVersion: "18.3.1",
+		Routes:               map[string][]string{"/": {"a.js"}, "/admin": {"c.js"}},
+		AllAssets:            map[string]bool{"a.js": true, "c.js": true},
+		AssetHashes:          map[string]string{"a.js": "333", "c.js": "444"},
+		NextDataJSONRaw:      `{"props":{"pageProps":{"title":"Home","user":{"email":"a@b.c"}}}}`,
+	}
+
+	report := Compare(oldResult, newResult)
+
+	require.True(t, report.HasChanges())
+	require.Equal(t, &Change{Old: "build-1", New: "build-2"}, report.BuildID)
+	require.Nil(t, report.NextVersion)
+	require.Equal(t, &Change{Old: "18.2.0", New: "18.3.1"}, report.ReactVersion)
+	require.Equal(t, []string{"/admin"}, report.AddedRoutes)
+	require.Equal(t, []string{"/about"}, report.RemovedRoutes)
+	require.Equal(t, []string{"c.js"}, report.AddedAssets)
+	require.Equal(t, []string{"b.js"}, report.RemovedAssets)
+	require.Equal(t, []string{"a.js"}, report.ChangedAssets)
+	require.Equal(t, []string{"props.pageProps.user", "props.pageProps.user.email"}, report.NewProps)
+
+	require.False(t, Compare(oldResult, oldResult).HasChanges())
+}
+
+func TestReportOnly(t *testing.T) {
+	t.Parallel()
+
+	report := &Report{
+		OldTarget:     "https://example.com/",
+		NewTarget:     "https://example.com/",
+		BuildID:       &Change{Old: "build-1", New: "build-2"},
+		ReactVersion:  &Change{Old: "18.2.0", New: "18.3.1"},
+		AddedRoutes:   []string{"/admin"},
+		ChangedAssets: []string{"a.js"},
+	}
+
+	fields, err := ParseFields(" Routes,version ")
+	require.NoError(t, err)
+	require.Equal(t, []string{FieldRoutes, FieldVersion}, fields)
+
+	filtered := report.Only(fields)
+	require.Equal(t, &Report{
+		OldTarget:    "https://example.com/",
+		NewTarget:    "https://example.com/",
+		ReactVersion: &Change{Old: "18.2.0", New: "18.3.1"},
+		AddedRoutes:  []string{"/admin"},
+	}, filtered)
+	require.False(t, report.Only([]string{FieldProps}).HasChanges())
+	require.Contains(t, RenderText(report.Only([]string{FieldHashes})), "Changed Assets (1):\n  ~ a.js\n")
+
+	_, err = ParseFields("routes,hash")
+	require.ErrorContains(t, err, "unknown field 'hash'")
+	_, err = ParseFields(",")
+	require.Error(t, err)
+}
+
+func TestLoadResult_IgnoresExecutionError(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "scan.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"BaseURL":"https://example.com/","BuildID":"b1","ExecutionError":{},"Routes":{"/":[]}}`), 0644))
+
+	result, err := LoadResult(path)
+
+	require.NoError(t, err)
+	require.Equal(t, "b1", result.BuildID)
+	require.Contains(t, result.Routes, "/")
+	require.Nil(t, result.ExecutionError)
+}
+
+func TestLoadResult_SchemaVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		schemaVersion string
+		wantErr       bool
+	}{
+		{name: "no version", schemaVersion: ""},
+		{name: "current version", schemaVersion: scanner.SchemaVersion},
+		{name: "newer minor version", schemaVersion: "1.99"},
+		{name: "other major version", schemaVersion: "2.0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "scan.json")
+			require.NoError(t, os.WriteFile(path, []byte(`{"SchemaVersion":"`+tt.schemaVersion+`","BaseURL":"https://example.com/","BuildID":"b1"}`), 0644))
+
+			result, err := LoadResult(path)
+
+			if tt.wantErr {
+				require.ErrorContains(t, err, "schema version 2.0")
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "b1", result.BuildID)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package fetch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// defaultRenderTimeout bounds a single page render when no timeout is configured.
+const defaultRenderTimeout = 30 * time.Second
+
+// ChromedpFetcher implements the Fetcher interface by driving a headless Chrome.
+// It returns the DOM as serialized after the page's JavaScript has run, which
+// makes it suitable for client-rendered pages whose initial HTML is empty.
+// It is intended for page fetches; scripts and manifests are better served by HTTPFetcher.
+type ChromedpFetcher struct {
+	allocCtx    context.Context
+	cancelAlloc context.CancelFunc
+	options     HTTPFetcherOptions
+}
+
+// NewChromedpFetcher creates a ChromedpFetcher backed by a headless Chrome
+// instance. Chrome is started lazily on the first Fetch.
+// Close must be called to shut the browser down.
+func NewChromedpFetcher(opts HTTPFetcherOptions) *ChromedpFetcher {
+	allocOpts := append(chromedp.DefaultExecAllocatorOptions[:], chromedp.Headless)
+	if opts.InsecureSkipVerify {
+		allocOpts = append(allocOpts, chromedp.Flag("ignore-certificate-errors", true))
+	}
+	if len(opts.Resolve) > 0 {
+		allocOpts = append(allocOpts, chromedp.Flag("host-resolver-rules", hostResolverRules(opts.Resolve)))
+	}
+	allocCtx, cancel := chromedp.NewExecAllocator(context.Background(), allocOpts...)
+	return &ChromedpFetcher{
+		allocCtx:    allocCtx,
+		cancelAlloc: cancel,
+		options:     opts,
+	}
+}
+
+// Fetch renders targetURL in a new browser tab and returns the resulting HTML
+// along with the URL the tab ended up on.
+func (f *ChromedpFetcher) Fetch(targetURL string) (io.ReadCloser, string, error) {
+	timeout := f.options.Timeout
+	if timeout <= 0 {
+		timeout = defaultRenderTimeout
+	}
+
+	tabCtx, cancelTab := chromedp.NewContext(f.allocCtx)
+	defer cancelTab()
+	ctx, cancel := context.WithTimeout(tabCtx, timeout)
+	defer cancel()
+
+	actions := []chromedp.Action{network.Enable()}
+	if len(f.options.Headers) > 0 {
+		headers := make(network.Headers, len(f.options.Headers))
+		for k, v := range f.options.Headers {
+			headers[k] = v
+		}
+		actions = append(actions, network.SetExtraHTTPHeaders(headers))
+	}
+
+	var html, finalURL string
+	actions = append(actions,
+		chromedp.Navigate(targetURL),
+		chromedp.WaitReady("body", chromedp.ByQuery),
+		chromedp.Location(&finalURL),
+		chromedp.OuterHTML("html", &html, chromedp.ByQuery),
+	)
+
+	if err := chromedp.Run(ctx, actions...); err != nil {
+		return nil, targetURL, fmt.Errorf("chromedp_fetcher: failed to render %s: %w", targetURL, err)
+	}
+	if finalURL == "" {
+		finalURL = targetURL
+	}
+
+	return io.NopCloser(strings.NewReader(html)), finalURL, nil
+}
+
+// Capabilities implements the Fetcher interface.
+func (f *ChromedpFetcher) Capabilities() FetcherCapabilities {
+	return FetcherCapabilities{
+		CanExecuteJavaScript: true,
+		CanQueryDOM:          true,
+	}
+}
+
+// Close shuts down the browser started by this fetcher.
+func (f *ChromedpFetcher) Close() {
+	f.cancelAlloc()
+}
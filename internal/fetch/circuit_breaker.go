@@ -0,0 +1,144 @@
+package fetch
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultCircuitBreakerThreshold is the number of consecutive failed
+// requests after which a host is skipped by default.
+const DefaultCircuitBreakerThreshold = 5
+
+// ErrCircuitOpen is returned for requests to a host skipped by a
+// CircuitBreaker.
+var ErrCircuitOpen = errors.New("circuit open")
+
+// CircuitBreaker counts the consecutive failed requests to each host and
+// opens the circuit of a host once they reach a threshold: from then on,
+// requests to that host are refused without being sent. It is meant to be
+// shared by the scans of a batch, so a host that is down or blocking
+// everything is only tried a few times. It is safe for concurrent use.
+type CircuitBreaker struct {
+	threshold int
+	mu        sync.Mutex
+	failures  map[string]int // Consecutive failures by lowercase host (and port)
+}
+
+// NewCircuitBreaker creates a CircuitBreaker opening the circuit of a host
+// after threshold consecutive failures.
+func NewCircuitBreaker(threshold int) *CircuitBreaker {
+	return &CircuitBreaker{threshold: threshold, failures: make(map[string]int)}
+}
+
+// Check returns an error wrapping ErrCircuitOpen if the circuit of the host
+// of targetURL, which may lack a scheme, is open.
+func (b *CircuitBreaker) Check(targetURL string) error {
+	host := breakerHost(targetURL)
+	b.mu.Lock()
+	failures := b.failures[host]
+	b.mu.Unlock()
+	if host == "" || failures < b.threshold {
+		return nil
+	}
+	return fmt.Errorf("circuit_breaker: skipping %s, %s failed %d times in a row: %w", targetURL, host, failures, ErrCircuitOpen)
+}
+
+// record counts a failed request to the host of targetURL, or resets its
+// count after a successful one.
+func (b *CircuitBreaker) record(targetURL string, failed bool) {
+	host := breakerHost(targetURL)
+	if host == "" {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if failed {
+		b.failures[host]++
+	} else {
+		delete(b.failures, host)
+	}
+}
+
+// breakerHost returns the lowercase host of targetURL, with its port if any,
+// or "" if it has none.
+func breakerHost(targetURL string) string {
+	if !strings.Contains(targetURL, "://") {
+		targetURL = "https://" + targetURL
+	}
+	u, err := url.Parse(targetURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Host)
+}
+
+// isHostFailure reports whether a request failed because of its host: no
+// response at all (connection error, timeout), a server error, or an answer
+// telling the client it is locked out or throttled (401, 403, 429). Other
+// statuses, such as a 404 for a missing chunk, show the host is up.
+func isHostFailure(info *ResponseInfo, err error) bool {
+	if err == nil {
+		return false
+	}
+	if info == nil || info.StatusCode == 0 {
+		return true
+	}
+	switch info.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests:
+		return true
+	}
+	return info.StatusCode >= 500
+}
+
+// CircuitBreakerFetcher decorates the Fetcher of a scan so that requests go
+// through a CircuitBreaker: requests to a host whose circuit is open fail
+// with ErrCircuitOpen. Only the outcome of the requests up to the first one
+// that did not fail is recorded, i.e. the target page (or the manifest of a
+// manifest scan) and its scheme fallback. Once that one is served, the host
+// is up: the assets and probes that follow, which often hit a 401 or 403 on
+// protected routes, say nothing more about it.
+type CircuitBreakerFetcher struct {
+	Fetcher
+	breaker *CircuitBreaker
+	settled atomic.Bool // A request did not fail, later ones are not recorded
+}
+
+// NewCircuitBreakerFetcher wraps fetcher with breaker.
+func NewCircuitBreakerFetcher(fetcher Fetcher, breaker *CircuitBreaker) *CircuitBreakerFetcher {
+	return &CircuitBreakerFetcher{
+		Fetcher: fetcher,
+		breaker: breaker,
+	}
+}
+
+// Fetch implements the Fetcher interface.
+func (f *CircuitBreakerFetcher) Fetch(targetURL string) (io.ReadCloser, string, error) {
+	reader, info, err := f.FetchWithInfo(targetURL)
+	finalURL := targetURL
+	if info != nil {
+		finalURL = info.FinalURL
+	}
+	return reader, finalURL, err
+}
+
+// FetchWithInfo implements the InfoFetcher interface.
+func (f *CircuitBreakerFetcher) FetchWithInfo(targetURL string) (io.ReadCloser, *ResponseInfo, error) {
+	if err := f.breaker.Check(targetURL); err != nil {
+		return nil, &ResponseInfo{FinalURL: targetURL}, err
+	}
+	reader, info, err := FetchWithInfo(f.Fetcher, targetURL)
+	if !f.settled.Load() {
+		failed := isHostFailure(info, err)
+		f.breaker.record(targetURL, failed)
+		if !failed {
+			f.settled.Store(true)
+		}
+	}
+	return reader, info, err
+}
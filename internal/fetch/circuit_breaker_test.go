@@ -0,0 +1,103 @@
+package fetch
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// statusStubFetcher answers every URL of a host with the status set for it,
+// failing for anything but a 200, and counts the requests.
+type statusStubFetcher struct {
+	statuses map[string]int // Status by host, 0 for a connection error
+	calls    int
+}
+
+func (f *statusStubFetcher) Fetch(targetURL string) (io.ReadCloser, string, error) {
+	reader, info, err := f.FetchWithInfo(targetURL)
+	return reader, info.FinalURL, err
+}
+
+func (f *statusStubFetcher) FetchWithInfo(targetURL string) (io.ReadCloser, *ResponseInfo, error) {
+	f.calls++
+	status := f.statuses[breakerHost(targetURL)]
+	info := &ResponseInfo{FinalURL: targetURL, StatusCode: status}
+	if status != http.StatusOK {
+		return nil, info, errors.New("stub: request failed")
+	}
+	return io.NopCloser(strings.NewReader("")), info, nil
+}
+
+func (f *statusStubFetcher) Capabilities() FetcherCapabilities {
+	return FetcherCapabilities{}
+}
+
+func TestCircuitBreakerFetcher(t *testing.T) {
+	t.Parallel()
+
+	inner := &statusStubFetcher{statuses: map[string]int{
+		"down.example.com":    0,
+		"missing.example.com": http.StatusNotFound,
+		"up.example.com":      http.StatusOK,
+	}}
+	breaker := NewCircuitBreaker(2)
+	// Each scan wraps its fetcher, sharing the breaker
+	scan := func() *CircuitBreakerFetcher {
+		return NewCircuitBreakerFetcher(inner, breaker)
+	}
+
+	// Two consecutive failures open the circuit, and no more requests are sent
+	for i := 0; i < 2; i++ {
+		_, _, err := scan().Fetch("https://down.example.com/page")
+		require.Error(t, err)
+		require.NotErrorIs(t, err, ErrCircuitOpen)
+	}
+	_, _, err := scan().Fetch("https://DOWN.example.com/other")
+	require.ErrorIs(t, err, ErrCircuitOpen)
+	require.Equal(t, 2, inner.calls)
+	require.ErrorIs(t, breaker.Check("down.example.com"), ErrCircuitOpen)
+
+	// A 404 shows the host is up
+	for i := 0; i < 3; i++ {
+		_, _, err := scan().Fetch("https://missing.example.com/chunk.js")
+		require.NotErrorIs(t, err, ErrCircuitOpen)
+	}
+	require.NoError(t, breaker.Check("https://missing.example.com/"))
+
+	// A success resets the count
+	inner.statuses["up.example.com"] = http.StatusServiceUnavailable
+	_, _, err = scan().Fetch("https://up.example.com/")
+	require.Error(t, err)
+	inner.statuses["up.example.com"] = http.StatusOK
+	_, _, err = scan().Fetch("https://up.example.com/")
+	require.NoError(t, err)
+	inner.statuses["up.example.com"] = http.StatusServiceUnavailable
+	_, _, err = scan().Fetch("https://up.example.com/")
+	require.NotErrorIs(t, err, ErrCircuitOpen)
+	require.NoError(t, breaker.Check("https://up.example.com/"))
+}
+
+func TestCircuitBreakerFetcher_OnlyCountsPageFetch(t *testing.T) {
+	t.Parallel()
+
+	inner := &statusStubFetcher{statuses: map[string]int{"app.example.com": http.StatusOK}}
+	breaker := NewCircuitBreaker(2)
+	fetcher := NewCircuitBreakerFetcher(inner, breaker)
+
+	_, _, err := fetcher.Fetch("https://app.example.com/")
+	require.NoError(t, err)
+
+	// Probes of protected routes after the page was served are not counted
+	inner.statuses["app.example.com"] = http.StatusForbidden
+	for i := 0; i < 5; i++ {
+		_, _, err := fetcher.Fetch("https://app.example.com/api/probe")
+		require.Error(t, err)
+		require.NotErrorIs(t, err, ErrCircuitOpen)
+	}
+	require.NoError(t, breaker.Check("https://app.example.com/"))
+	require.Equal(t, 6, inner.calls)
+}
@@ -2,6 +2,7 @@ package fetch
 
 import (
 	"io"
+	"net/http"
 )
 
 // FetcherCapabilities describes the optional abilities of a Fetcher implementation.
@@ -22,4 +23,32 @@ type Fetcher interface {
 
 	// Capabilities returns a description of the fetcher's optional abilities.
 	Capabilities() FetcherCapabilities
-} 
\ No newline at end of file
+}
+
+// ResponseInfo describes the response behind a successful or failed fetch.
+type ResponseInfo struct {
+	FinalURL      string      // URL reached after following redirects.
+	StatusCode    int         // Status code of the final response, 0 if no response was received.
+	RedirectChain []string    // Every URL visited, starting with the requested one and ending with FinalURL.
+	Headers       http.Header // Headers of the final response, nil if unknown.
+}
+
+// InfoFetcher is implemented by fetchers that can report details about the
+// response in addition to its content.
+type InfoFetcher interface {
+	Fetcher
+
+	// FetchWithInfo behaves like Fetch but returns a ResponseInfo instead of
+	// just the final URL. The returned ResponseInfo is never nil.
+	FetchWithInfo(targetURL string) (content io.ReadCloser, info *ResponseInfo, err error)
+}
+
+// FetchWithInfo fetches targetURL with f, using f's FetchWithInfo when it
+// implements InfoFetcher and falling back to a ResponseInfo holding only the final URL otherwise.
+func FetchWithInfo(f Fetcher, targetURL string) (io.ReadCloser, *ResponseInfo, error) {
+	if infoFetcher, ok := f.(InfoFetcher); ok {
+		return infoFetcher.FetchWithInfo(targetURL)
+	}
+	content, finalURL, err := f.Fetch(targetURL)
+	return content, &ResponseInfo{FinalURL: finalURL}, err
+}
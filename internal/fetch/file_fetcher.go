@@ -0,0 +1,105 @@
+package fetch
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// FileFetcher serves a saved copy of a page and its assets from the local
+// file system, for offline scans of captured pages and end-to-end tests
+// without network. The page URL is answered with htmlFile, any other URL
+// with the file at its path under assetDir, whatever its host, so assets
+// saved from a CDN are found too.
+type FileFetcher struct {
+	pagePath string
+	htmlFile string
+	assetDir string
+}
+
+// NewFileFetcher creates a FileFetcher answering pageURL, which may lack a
+// scheme, with htmlFile and other URLs from assetDir (e.g. a URL with the
+// path /_next/static/chunks/main.js from assetDir/_next/static/chunks/main.js).
+func NewFileFetcher(pageURL, htmlFile, assetDir string) *FileFetcher {
+	if !strings.Contains(pageURL, "://") {
+		pageURL = "https://" + pageURL
+	}
+	pagePath := "/"
+	if u, err := url.Parse(pageURL); err == nil {
+		pagePath = cleanURLPath(u.Path)
+	}
+	return &FileFetcher{pagePath: pagePath, htmlFile: htmlFile, assetDir: assetDir}
+}
+
+// Fetch implements the Fetcher interface.
+func (f *FileFetcher) Fetch(targetURL string) (io.ReadCloser, string, error) {
+	reader, info, err := f.FetchWithInfo(targetURL)
+	return reader, info.FinalURL, err
+}
+
+// FetchWithInfo implements the InfoFetcher interface. Missing files are
+// reported with a 404 status.
+func (f *FileFetcher) FetchWithInfo(targetURL string) (io.ReadCloser, *ResponseInfo, error) {
+	info := &ResponseInfo{FinalURL: targetURL, RedirectChain: []string{targetURL}}
+	u, err := url.Parse(targetURL)
+	if err != nil {
+		return nil, info, fmt.Errorf("file_fetcher: invalid URL '%s': %w", targetURL, err)
+	}
+
+	filePath := f.htmlFile
+	if cleanURLPath(u.Path) != f.pagePath {
+		filePath = f.assetPath(u)
+	}
+	if filePath == "" {
+		info.StatusCode = http.StatusNotFound
+		return nil, info, fmt.Errorf("file_fetcher: no saved file for %s in %s", targetURL, f.assetDir)
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, info, fmt.Errorf("file_fetcher: failed to open %s for %s: %w", filePath, targetURL, err)
+	}
+
+	info.StatusCode = http.StatusOK
+	info.Headers = http.Header{}
+	contentType := mime.TypeByExtension(filepath.Ext(filePath))
+	if filePath == f.htmlFile || contentType == "" {
+		contentType = "text/html; charset=utf-8"
+	}
+	info.Headers.Set("Content-Type", contentType)
+	return file, info, nil
+}
+
+// Capabilities implements the Fetcher interface.
+func (f *FileFetcher) Capabilities() FetcherCapabilities {
+	return FetcherCapabilities{}
+}
+
+// assetPath returns the saved file of u, or "" if there is none. When the
+// full path is missing, the part from "/_next/" on is tried, since an
+// assetPrefix or basePath adds a prefix the captured tree may not have.
+func (f *FileFetcher) assetPath(u *url.URL) string {
+	candidates := []string{u.Path, u.EscapedPath()}
+	if i := strings.Index(u.Path, "/_next/"); i > 0 {
+		candidates = append(candidates, u.Path[i:])
+	}
+	for _, candidate := range candidates {
+		// Cleaning the rooted path drops any "..", keeping it inside assetDir
+		filePath := filepath.Join(f.assetDir, filepath.FromSlash(path.Clean("/"+candidate)))
+		if stat, err := os.Stat(filePath); err == nil && stat.Mode().IsRegular() {
+			return filePath
+		}
+	}
+	return ""
+}
+
+// cleanURLPath normalizes a URL path for comparison, ignoring a trailing
+// slash.
+func cleanURLPath(p string) string {
+	return path.Clean("/" + p)
+}
@@ -0,0 +1,63 @@
+package fetch
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileFetcher(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	htmlFile := filepath.Join(dir, "page.html")
+	require.NoError(t, os.WriteFile(htmlFile, []byte("<html></html>"), 0644))
+	assetDir := filepath.Join(dir, "site")
+	chunkDir := filepath.Join(assetDir, "_next", "static", "chunks")
+	require.NoError(t, os.MkdirAll(chunkDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(chunkDir, "main.js"), []byte("main()"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("secret"), 0644))
+
+	f := NewFileFetcher("example.com/blog/", htmlFile, assetDir)
+
+	tests := []struct {
+		name        string
+		url         string
+		wantBody    string
+		wantType    string
+		wantMissing bool
+	}{
+		{name: "page", url: "https://example.com/blog", wantBody: "<html></html>", wantType: "text/html; charset=utf-8"},
+		{name: "page with trailing slash and query", url: "http://example.com/blog/?utm=1", wantBody: "<html></html>", wantType: "text/html; charset=utf-8"},
+		{name: "asset", url: "https://example.com/_next/static/chunks/main.js?dpl=1", wantBody: "main()", wantType: "text/javascript; charset=utf-8"},
+		{name: "asset on a CDN under an asset prefix", url: "https://cdn.example.net/app/_next/static/chunks/main.js", wantBody: "main()", wantType: "text/javascript; charset=utf-8"},
+		{name: "missing file", url: "https://example.com/_next/static/chunks/other.js", wantMissing: true},
+		{name: "directory", url: "https://example.com/_next/static", wantMissing: true},
+		{name: "path traversal", url: "https://example.com/_next/../../secret.txt", wantMissing: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			reader, info, err := f.FetchWithInfo(tt.url)
+			if tt.wantMissing {
+				require.Error(t, err)
+				require.Equal(t, http.StatusNotFound, info.StatusCode)
+				return
+			}
+			require.NoError(t, err)
+			defer reader.Close()
+			body, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantBody, string(body))
+			require.Equal(t, http.StatusOK, info.StatusCode)
+			require.Equal(t, tt.wantType, info.Headers.Get("Content-Type"))
+			require.Equal(t, tt.url, info.FinalURL)
+		})
+	}
+}
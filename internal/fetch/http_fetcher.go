@@ -3,8 +3,13 @@ package fetch
 import (
 	"fmt"
 	"io"
+	"log"
+	"math/rand"
 	"net/http"
+	"net/url"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/Danny-Dasilva/CycleTLS/cycletls"
 )
@@ -15,7 +20,8 @@ type tlsProfile struct {
 	userAgent string
 }
 
-// defaultProfiles defines the list of profiles to try sequentially.
+// defaultProfiles defines the list of profiles to try sequentially. With
+// HTTPFetcherOptions.RotateProfiles, each request starts from a random one.
 var defaultProfiles = []tlsProfile{
 	{
 		// Safari on macos
@@ -29,21 +35,169 @@ var defaultProfiles = []tlsProfile{
 	},
 }
 
+// HTTPFetcherOptions configures the requests made by an HTTPFetcher.
+// The zero value uses cycleTLS defaults.
+type HTTPFetcherOptions struct {
+	Timeout            time.Duration     // Per-request timeout. Zero uses the cycleTLS default (15s).
+	Headers            map[string]string // Extra headers sent with every request.
+	MaxRedirects       int               // Redirects followed per fetch. Zero uses DefaultMaxRedirects, negative follows none.
+	CookieJar          http.CookieJar    // If set, Set-Cookie responses are stored here and replayed on later requests.
+	InsecureSkipVerify bool              // Skip TLS certificate verification (self-signed or expired certificates).
+	RotateProfiles     bool              // Start each request from a randomly chosen profile instead of the first one.
+	Seed               int64             // Seed of the RotateProfiles choices, for reproducible runs. Zero uses a random seed.
+	HTTPVersion        string            // HTTPVersion1 forces HTTP/1.1; HTTPVersionAuto (or HTTPVersion2) negotiates, preferring HTTP/2.
+	ProfileDelay       time.Duration     // Wait between two profile attempts of a request, plus up to as much random jitter. Zero retries immediately.
+	Resolve            map[string]string // Connect to these IPs instead of resolving the hosts ("host" or "host:port" keys, see ParseResolve); SNI and Host keep the host name.
+	MaxBodySize        int64             // Stop downloading a response past about this many bytes and fail with ErrBodyTooLarge. Zero doesn't limit it.
+}
+
+// HTTP versions accepted by HTTPFetcherOptions.HTTPVersion.
+const (
+	HTTPVersionAuto = ""
+	HTTPVersion1    = "1.1"
+	HTTPVersion2    = "2"
+	HTTPVersion3    = "3"
+)
+
+// NormalizeHTTPVersion validates an HTTP version choice ("auto", "1.1", "2",
+// also accepted with an "HTTP/" prefix) and returns it as one of the
+// HTTPVersion constants. HTTP/3 is rejected: cycleTLS only speaks HTTP/1.1
+// and HTTP/2. HTTP/2 can't be forced either, only preferred through ALPN,
+// which is what negotiation already does, so HTTPVersion2 behaves like
+// HTTPVersionAuto.
+func NormalizeHTTPVersion(version string) (string, error) {
+	switch strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(version)), "HTTP/") {
+	case "", "AUTO":
+		return HTTPVersionAuto, nil
+	case "1.1", "1":
+		return HTTPVersion1, nil
+	case "2", "2.0":
+		return HTTPVersion2, nil
+	case "3", "3.0":
+		return "", fmt.Errorf("HTTP/3 is not supported: the TLS client only speaks HTTP/1.1 and HTTP/2")
+	default:
+		return "", fmt.Errorf("invalid HTTP version '%s': use auto, 1.1 or 2", version)
+	}
+}
+
+// DefaultMaxRedirects is the number of redirects followed when none is configured.
+const DefaultMaxRedirects = 10
+
 // HTTPFetcher implements the Fetcher interface using cycleTLS.
+// It is safe for concurrent use: cycleTLS' Do builds a new transport for each
+// request, and the fetcher's own fields are never modified after creation,
+// so a single instance can be shared by long-running servers. The profile
+// rotation, when enabled, has its own lock.
 type HTTPFetcher struct {
 	client   cycletls.CycleTLS
 	profiles []tlsProfile
+	options  HTTPFetcherOptions
+	rotation *profileRotation // nil unless options.RotateProfiles is set
+	delays   *profileRotation // Jitter source, nil unless options.ProfileDelay is set
+	proxy    *localProxy      // nil unless options.Resolve or options.MaxBodySize is set
+}
+
+// profileRotation draws the random choices of an HTTPFetcher: the profile
+// each request starts from, and the jitter between profile attempts.
+type profileRotation struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// newProfileRotation creates a profileRotation seeded with seed, or with the
+// current time when seed is zero.
+func newProfileRotation(seed int64) *profileRotation {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &profileRotation{rng: rand.New(rand.NewSource(seed))}
+}
+
+// next returns a random profile index in [0, n).
+func (r *profileRotation) next(n int) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rng.Intn(n)
+}
+
+// jitter returns a random duration in [d, 2d).
+func (r *profileRotation) jitter(d time.Duration) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return d + time.Duration(r.rng.Int63n(int64(d)))
 }
 
 // NewHTTPFetcher creates a new HTTPFetcher with default cycleTLS settings and profiles.
 func NewHTTPFetcher() *HTTPFetcher {
+	return NewHTTPFetcherWithOptions(HTTPFetcherOptions{})
+}
+
+// NewHTTPFetcherWithOptions creates a new HTTPFetcher using the default profiles
+// and the given request options.
+func NewHTTPFetcherWithOptions(opts HTTPFetcherOptions) *HTTPFetcher {
 	client := cycletls.Init()
-	return &HTTPFetcher{
+	f := &HTTPFetcher{
 		client:   client,
 		profiles: defaultProfiles,
+		options:  opts,
+	}
+	if opts.RotateProfiles {
+		f.rotation = newProfileRotation(opts.Seed)
+	}
+	if opts.ProfileDelay > 0 {
+		f.delays = newProfileRotation(opts.Seed)
+	}
+	if len(opts.Resolve) > 0 || opts.MaxBodySize > 0 {
+		f.proxy = newLocalProxy(opts.Resolve, opts.MaxBodySize)
+	}
+	return f
+}
+
+// Close stops the local proxy serving the Resolve overrides and the
+// MaxBodySize limit, if it was started. The fetcher must not be used
+// afterwards.
+func (f *HTTPFetcher) Close() {
+	if f.proxy != nil {
+		f.proxy.close()
 	}
 }
 
+// profileOrder returns the indexes of the profiles in the order a request
+// tries them: from the first one, or from a random one when rotating, the
+// others following as fallbacks.
+func (f *HTTPFetcher) profileOrder() []int {
+	start := 0
+	if f.rotation != nil && len(f.profiles) > 1 {
+		start = f.rotation.next(len(f.profiles))
+	}
+	order := make([]int, len(f.profiles))
+	for i := range order {
+		order[i] = (start + i) % len(f.profiles)
+	}
+	return order
+}
+
+// requestHeaders returns a fresh copy of the configured extra headers.
+func (f *HTTPFetcher) requestHeaders() map[string]string {
+	headers := make(map[string]string, len(f.options.Headers))
+	for k, v := range f.options.Headers {
+		headers[k] = v
+	}
+	return headers
+}
+
+// timeoutSeconds converts the configured timeout into cycleTLS' whole-second form.
+func (f *HTTPFetcher) timeoutSeconds() int {
+	if f.options.Timeout <= 0 {
+		return 0
+	}
+	seconds := int(f.options.Timeout / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return seconds
+}
+
 // Fetch retrieves the content from the targetURL using cycleTLS.
 // It iterates through a list of predefined JA3/User-Agent profiles,
 // attempting the request with each until one succeeds or the list is exhausted.
@@ -51,80 +205,218 @@ func NewHTTPFetcher() *HTTPFetcher {
 // after any redirects, and an error if fetching failed.
 // The caller is responsible for closing the returned io.ReadCloser.
 func (f *HTTPFetcher) Fetch(targetURL string) (io.ReadCloser, string, error) {
+	body, info, err := f.FetchWithInfo(targetURL)
+	return body, info.FinalURL, err
+}
+
+// FetchWithInfo implements the InfoFetcher interface.
+// Redirects are followed manually, one hop at a time, so that every URL
+// visited is recorded in the returned ResponseInfo.RedirectChain. At most
+// MaxRedirects hops are followed.
+func (f *HTTPFetcher) FetchWithInfo(targetURL string) (io.ReadCloser, *ResponseInfo, error) {
+	info := &ResponseInfo{FinalURL: targetURL}
+	currentURL := targetURL
+	maxRedirects := f.maxRedirects()
+
+	var lastResp cycletls.Response
+	for redirects := 0; ; redirects++ {
+		info.RedirectChain = append(info.RedirectChain, currentURL)
+		info.FinalURL = currentURL
+
+		resp, err := f.doWithProfiles(currentURL)
+		if err != nil {
+			return nil, info, err
+		}
+		lastResp = resp
+		info.StatusCode = resp.Status
+		info.Headers = responseHeaders(resp)
+
+		location := redirectLocation(resp)
+		if location == "" {
+			break
+		}
+		if redirects >= maxRedirects {
+			return nil, info, fmt.Errorf("http_fetcher: stopped after %d redirects fetching %s (last URL: %s)", maxRedirects, targetURL, currentURL)
+		}
+
+		base, err := url.Parse(currentURL)
+		if err != nil {
+			return nil, info, fmt.Errorf("http_fetcher: invalid URL '%s' in redirect chain: %w", currentURL, err)
+		}
+		next, err := base.Parse(location)
+		if err != nil {
+			return nil, info, fmt.Errorf("http_fetcher: invalid redirect location '%s' from %s: %w", location, currentURL, err)
+		}
+		currentURL = next.String()
+	}
+
+	finalURL := info.FinalURL
+
+	if lastResp.Status == 0 {
+		errMsg := fmt.Sprintf("http_fetcher: cycleTLS returned status 0 (non-TLS handshake error) for %s", finalURL)
+		if lastResp.Body != "" {
+			errMsg = fmt.Sprintf("%s, body: %s", errMsg, lastResp.Body)
+		}
+		return nil, info, fmt.Errorf("%s", errMsg)
+	}
+
+	if lastResp.Status != http.StatusOK {
+		return nil, info, fmt.Errorf("http_fetcher: bad status code fetching %s (final URL: %s): %d", targetURL, finalURL, lastResp.Status)
+	}
+
+	bodyReader := strings.NewReader(lastResp.Body)
+	bodyCloser := io.NopCloser(bodyReader)
+
+	return bodyCloser, info, nil
+}
+
+// doWithProfiles performs a single request (without following redirects),
+// trying each TLS profile in turn until one is not rejected. With a
+// ProfileDelay, it waits between two attempts so hosts rate-limiting by
+// fingerprint don't see them back to back.
+func (f *HTTPFetcher) doWithProfiles(targetURL string) (cycletls.Response, error) {
 	var lastResp cycletls.Response
 	var lastErr error
-	var success bool
-	var finalURL string
 
-	for i, profile := range f.profiles {
+	cookies := f.jarCookies(targetURL)
+
+	for attempt, i := range f.profileOrder() {
+		if attempt > 0 && f.delays != nil {
+			time.Sleep(f.delays.jitter(f.options.ProfileDelay))
+		}
+		profile := f.profiles[i]
+		// Resolve overrides and the size limit are applied by tunneling the
+		// requests through a local proxy, since cycleTLS doesn't accept a
+		// custom dialer and reads whole bodies
+		proxyURL := ""
+		var tunnel *transfer
+		if f.proxy != nil {
+			var err error
+			if proxyURL, tunnel, err = f.proxy.begin(); err != nil {
+				return lastResp, err
+			}
+		}
 		options := cycletls.Options{
-			Body:      "",
-			Ja3:       profile.ja3,
-			UserAgent: profile.userAgent,
-			Headers:   map[string]string{},
+			Body:               "",
+			Ja3:                profile.ja3,
+			UserAgent:          profile.userAgent,
+			Headers:            f.requestHeaders(),
+			Timeout:            f.timeoutSeconds(),
+			DisableRedirect:    true,
+			Cookies:            cookies,
+			InsecureSkipVerify: f.options.InsecureSkipVerify,
+			ForceHTTP1:         f.options.HTTPVersion == HTTPVersion1,
+			Proxy:              proxyURL,
 		}
 
 		resp, err := f.client.Do(targetURL, options, "GET")
+		if f.proxy != nil {
+			f.proxy.end(proxyURL)
+		}
+		if tunnel != nil && tunnel.truncated.Load() {
+			// Another profile would download it again
+			return resp, fmt.Errorf("http_fetcher: %w: %s is over %d bytes", ErrBodyTooLarge, targetURL, f.options.MaxBodySize)
+		}
 
 		lastResp = resp
 		lastErr = err
 
 		if err != nil {
-			fmt.Printf("http_fetcher: Profile #%d failed for %s: Error during Do(): %v\n", i+1, targetURL, err)
+			log.Printf("http_fetcher: Profile #%d failed for %s: Error during Do(): %v", i+1, targetURL, err)
 			continue
 		}
 
 		if resp.Status == 0 && (strings.Contains(resp.Body, "tls: protocol version not supported") || strings.Contains(resp.Body, "HANDSHAKE_FAILURE")) {
-			fmt.Printf("http_fetcher: Profile #%d failed for %s: TLS handshake error. Body: %s\n", i+1, targetURL, resp.Body)
+			log.Printf("http_fetcher: Profile #%d failed for %s: TLS handshake error. Body: %s", i+1, targetURL, resp.Body)
 			continue
 		}
 
 		if resp.Status == http.StatusForbidden {
-			fmt.Printf("http_fetcher: Profile #%d received 403 Forbidden for %s. Trying next profile.\n", i+1, targetURL)
+			log.Printf("http_fetcher: Profile #%d received 403 Forbidden for %s. Trying next profile.", i+1, targetURL)
 			continue
 		}
 
-		success = true
-		break
+		f.storeCookies(targetURL, resp.Cookies)
+		return resp, nil
 	}
 
-	if !success {
-		errMsg := fmt.Sprintf("http_fetcher: all TLS profiles failed for %s", targetURL)
-		if lastErr != nil {
-			errMsg = fmt.Sprintf("%s. Last Do() error: %v", errMsg, lastErr)
-		} else if lastResp.Status == 0 && lastResp.Body != "" {
-			errMsg = fmt.Sprintf("%s. Last response body: %s", errMsg, lastResp.Body)
-		} else if lastResp.Status == http.StatusForbidden {
-			errMsg = fmt.Sprintf("%s. Last attempt resulted in 403 Forbidden.", errMsg)
-		}
-		finalURL = lastResp.FinalUrl
-		if finalURL == "" {
-			finalURL = targetURL
-		}
-		return nil, finalURL, fmt.Errorf("%s", errMsg)
+	errMsg := fmt.Sprintf("http_fetcher: all TLS profiles failed for %s", targetURL)
+	if lastErr != nil {
+		errMsg = fmt.Sprintf("%s. Last Do() error: %v", errMsg, lastErr)
+	} else if lastResp.Status == 0 && lastResp.Body != "" {
+		errMsg = fmt.Sprintf("%s. Last response body: %s", errMsg, lastResp.Body)
+	} else if lastResp.Status == http.StatusForbidden {
+		errMsg = fmt.Sprintf("%s. Last attempt resulted in 403 Forbidden.", errMsg)
 	}
+	return lastResp, fmt.Errorf("%s", errMsg)
+}
 
-	finalURL = lastResp.FinalUrl
-	if finalURL == "" {
-		finalURL = targetURL
+// jarCookies returns the cookies from the configured jar that apply to targetURL.
+func (f *HTTPFetcher) jarCookies(targetURL string) []cycletls.Cookie {
+	if f.options.CookieJar == nil {
+		return nil
 	}
+	u, err := url.Parse(targetURL)
+	if err != nil {
+		return nil
+	}
+	var cookies []cycletls.Cookie
+	for _, c := range f.options.CookieJar.Cookies(u) {
+		cookies = append(cookies, cycletls.Cookie{Name: c.Name, Value: c.Value})
+	}
+	return cookies
+}
 
-	if lastResp.Status == 0 {
-		errMsg := fmt.Sprintf("http_fetcher: cycleTLS returned status 0 (non-TLS handshake error) for %s", finalURL)
-		if lastResp.Body != "" {
-			errMsg = fmt.Sprintf("%s, body: %s", errMsg, lastResp.Body)
-		}
-		return nil, finalURL, fmt.Errorf("%s", errMsg)
+// storeCookies saves the cookies set by a response from targetURL in the configured jar.
+func (f *HTTPFetcher) storeCookies(targetURL string, cookies []*http.Cookie) {
+	if f.options.CookieJar == nil || len(cookies) == 0 {
+		return
+	}
+	if u, err := url.Parse(targetURL); err == nil {
+		f.options.CookieJar.SetCookies(u, cookies)
 	}
+}
 
-	if lastResp.Status != http.StatusOK {
-		return nil, finalURL, fmt.Errorf("http_fetcher: bad status code fetching %s (final URL: %s): %d", targetURL, finalURL, lastResp.Status)
+// responseHeaders converts cycleTLS' flat header map into an http.Header.
+// cycleTLS joins repeated Set-Cookie values with "/,/", which are split again,
+// but only keeps the last value of any other repeated header.
+func responseHeaders(resp cycletls.Response) http.Header {
+	headers := make(http.Header, len(resp.Headers))
+	for name, value := range resp.Headers {
+		if strings.EqualFold(name, "Set-Cookie") {
+			for _, cookie := range strings.Split(value, "/,/") {
+				headers.Add(name, cookie)
+			}
+			continue
+		}
+		headers.Add(name, value)
 	}
+	return headers
+}
 
-	bodyReader := strings.NewReader(lastResp.Body)
-	bodyCloser := io.NopCloser(bodyReader)
+// redirectLocation returns the Location of a redirect response, or "" if resp is not a redirect.
+func redirectLocation(resp cycletls.Response) string {
+	switch resp.Status {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		for name, value := range resp.Headers {
+			if strings.EqualFold(name, "Location") {
+				return value
+			}
+		}
+	}
+	return ""
+}
 
-	return bodyCloser, finalURL, nil
+// maxRedirects returns the configured redirect limit, applying the default.
+func (f *HTTPFetcher) maxRedirects() int {
+	if f.options.MaxRedirects == 0 {
+		return DefaultMaxRedirects
+	}
+	if f.options.MaxRedirects < 0 {
+		return 0
+	}
+	return f.options.MaxRedirects
 }
 
 // Capabilities implements the Fetcher interface.
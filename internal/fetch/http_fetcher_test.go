@@ -4,8 +4,14 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/cookiejar"
 	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -114,4 +120,363 @@ func TestHTTPFetcher_Fetch(t *testing.T) {
 
 // Optional: Test error during request creation (if possible, e.g. invalid method)
 // This is less about the Fetch method logic and more about http.NewRequest.
-// func TestHTTPFetcher_Fetch_BadRequest(t *testing.T) { ... } 
\ No newline at end of file
+// func TestHTTPFetcher_Fetch_BadRequest(t *testing.T) { ... } 
+
+func TestHTTPFetcher_FetchWithInfo_RedirectChain(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/start":
+			http.Redirect(w, r, "/middle", http.StatusMovedPermanently)
+		case "/middle":
+			http.Redirect(w, r, "/login", http.StatusFound)
+		case "/login":
+			fmt.Fprint(w, "Login")
+		case "/loop":
+			http.Redirect(w, r, "/loop", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	fetcher := NewHTTPFetcherWithOptions(HTTPFetcherOptions{MaxRedirects: 3})
+
+	content, info, err := fetcher.FetchWithInfo(server.URL + "/start")
+	require.NoError(t, err)
+	defer content.Close()
+	require.Equal(t, []string{server.URL + "/start", server.URL + "/middle", server.URL + "/login"}, info.RedirectChain)
+	require.Equal(t, server.URL+"/login", info.FinalURL)
+	require.Equal(t, http.StatusOK, info.StatusCode)
+
+	content, info, err = fetcher.FetchWithInfo(server.URL + "/loop")
+	require.Error(t, err)
+	require.Nil(t, content)
+	require.Contains(t, err.Error(), "stopped after 3 redirects")
+	require.Len(t, info.RedirectChain, 4)
+}
+
+func TestHTTPFetcher_ConcurrentUse(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.URL.Path)
+	}))
+	defer server.Close()
+
+	fetcher := NewHTTPFetcher()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			path := fmt.Sprintf("/page-%d", i)
+			content, finalURL, err := fetcher.Fetch(server.URL + path)
+			if err != nil {
+				errs <- err
+				return
+			}
+			defer content.Close()
+			body, err := io.ReadAll(content)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if string(body) != path || finalURL != server.URL+path {
+				errs <- fmt.Errorf("request %s got body %q from %s", path, body, finalURL)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+}
+
+func TestHTTPFetcher_CookieJar(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			http.SetCookie(w, &http.Cookie{Name: "cdn_token", Value: "abc", Path: "/"})
+			fmt.Fprint(w, "page")
+		case "/asset.js":
+			token, err := r.Cookie("cdn_token")
+			if err != nil || token.Value != "abc" {
+				http.Error(w, "forbidden", http.StatusUnauthorized)
+				return
+			}
+			seeded, err := r.Cookie("seeded")
+			if err != nil || seeded.Value != "1" {
+				http.Error(w, "forbidden", http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, "asset")
+		}
+	}))
+	defer server.Close()
+
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	jar.SetCookies(serverURL, []*http.Cookie{{Name: "seeded", Value: "1"}})
+
+	withoutJar := NewHTTPFetcher()
+	_, _, err = withoutJar.Fetch(server.URL + "/")
+	require.NoError(t, err)
+	_, _, err = withoutJar.Fetch(server.URL + "/asset.js")
+	require.Error(t, err)
+
+	withJar := NewHTTPFetcherWithOptions(HTTPFetcherOptions{CookieJar: jar})
+	_, _, err = withJar.Fetch(server.URL + "/")
+	require.NoError(t, err)
+	content, _, err := withJar.Fetch(server.URL + "/asset.js")
+	require.NoError(t, err)
+	body, err := io.ReadAll(content)
+	require.NoError(t, err)
+	require.Equal(t, "asset", string(body))
+}
+
+func TestHTTPFetcher_InsecureSkipVerify(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "self-signed")
+	}))
+	defer server.Close()
+
+	// httptest's certificate is not trusted, so verification must fail by default
+	_, _, err := NewHTTPFetcher().Fetch(server.URL)
+	require.Error(t, err)
+
+	fetcher := NewHTTPFetcherWithOptions(HTTPFetcherOptions{InsecureSkipVerify: true})
+	body, _, err := fetcher.Fetch(server.URL)
+	require.NoError(t, err)
+	defer body.Close()
+	content, err := io.ReadAll(body)
+	require.NoError(t, err)
+	require.Equal(t, "self-signed", string(content))
+}
+
+func TestHTTPFetcher_RotateProfiles(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var userAgents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		userAgents = append(userAgents, r.UserAgent())
+		mu.Unlock()
+		fmt.Fprint(w, "OK")
+	}))
+	defer server.Close()
+
+	fetchAll := func(opts HTTPFetcherOptions) []string {
+		mu.Lock()
+		userAgents = nil
+		mu.Unlock()
+
+		fetcher := NewHTTPFetcherWithOptions(opts)
+		for i := 0; i < 12; i++ {
+			content, _, err := fetcher.Fetch(server.URL)
+			require.NoError(t, err)
+			content.Close()
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), userAgents...)
+	}
+
+	fixed := fetchAll(HTTPFetcherOptions{})
+	for _, userAgent := range fixed {
+		require.Equal(t, defaultProfiles[0].userAgent, userAgent)
+	}
+
+	rotated := fetchAll(HTTPFetcherOptions{RotateProfiles: true, Seed: 42})
+	seen := make(map[string]bool)
+	for _, userAgent := range rotated {
+		seen[userAgent] = true
+	}
+	require.Len(t, seen, len(defaultProfiles), "every profile should be used")
+
+	require.Equal(t, rotated, fetchAll(HTTPFetcherOptions{RotateProfiles: true, Seed: 42}), "the same seed should pick the same profiles")
+}
+
+func TestHTTPFetcher_HTTPVersion(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.Proto)
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	fetchProto := func(version string) string {
+		fetcher := NewHTTPFetcherWithOptions(HTTPFetcherOptions{InsecureSkipVerify: true, HTTPVersion: version})
+		body, _, err := fetcher.Fetch(server.URL)
+		require.NoError(t, err)
+		defer body.Close()
+		content, err := io.ReadAll(body)
+		require.NoError(t, err)
+		return string(content)
+	}
+
+	require.Equal(t, "HTTP/2.0", fetchProto(HTTPVersionAuto))
+	require.Equal(t, "HTTP/1.1", fetchProto(HTTPVersion1))
+}
+
+func TestNormalizeHTTPVersion(t *testing.T) {
+	t.Parallel()
+
+	for raw, want := range map[string]string{"": HTTPVersionAuto, "auto": HTTPVersionAuto, "1.1": HTTPVersion1, "HTTP/1.1": HTTPVersion1, "2": HTTPVersion2} {
+		version, err := NormalizeHTTPVersion(raw)
+		require.NoError(t, err, raw)
+		require.Equal(t, want, version, raw)
+	}
+	for _, raw := range []string{"3", "HTTP/3", "1.0", "spdy"} {
+		_, err := NormalizeHTTPVersion(raw)
+		require.Error(t, err, raw)
+	}
+}
+
+func TestHTTPFetcher_ProfileDelay(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var attempts []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts = append(attempts, time.Now())
+		mu.Unlock()
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	const delay = 50 * time.Millisecond
+	fetcher := NewHTTPFetcherWithOptions(HTTPFetcherOptions{ProfileDelay: delay, Seed: 7})
+	_, _, err := fetcher.Fetch(server.URL)
+	require.ErrorContains(t, err, "all TLS profiles failed")
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, attempts, len(defaultProfiles))
+	for i := 1; i < len(attempts); i++ {
+		gap := attempts[i].Sub(attempts[i-1])
+		require.GreaterOrEqual(t, gap, delay, "attempt %d", i+1)
+	}
+}
+
+func TestParseResolve(t *testing.T) {
+	t.Parallel()
+
+	overrides, err := ParseResolve([]string{"Example.com:203.0.113.7", "cdn.example.com:443:203.0.113.8", "v6.example.com:443:[2001:db8::1]"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"example.com":         "203.0.113.7",
+		"cdn.example.com:443": "203.0.113.8",
+		"v6.example.com:443":  "2001:db8::1",
+	}, overrides)
+
+	for _, raw := range []string{"example.com", ":203.0.113.7", "example.com:not-an-ip", "example.com:99999:203.0.113.7"} {
+		_, err := ParseResolve([]string{raw})
+		require.Error(t, err, raw)
+	}
+}
+
+func TestHTTPFetcher_Resolve(t *testing.T) {
+	t.Parallel()
+
+	var host, serverName string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, serverName = r.Host, r.TLS.ServerName
+		fmt.Fprint(w, "pinned")
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	// The host doesn't resolve: only the override can reach the server
+	fetcher := NewHTTPFetcherWithOptions(HTTPFetcherOptions{
+		InsecureSkipVerify: true,
+		Resolve:            map[string]string{"nextr4y.invalid": serverURL.Hostname()},
+	})
+	defer fetcher.Close()
+	body, _, err := fetcher.Fetch("https://nextr4y.invalid:" + serverURL.Port() + "/")
+	require.NoError(t, err)
+	defer body.Close()
+	content, err := io.ReadAll(body)
+	require.NoError(t, err)
+	require.Equal(t, "pinned", string(content))
+	require.Equal(t, "nextr4y.invalid:"+serverURL.Port(), host)
+	require.Equal(t, "nextr4y.invalid", serverName)
+}
+
+func TestHTTPFetcher_MaxBodySize(t *testing.T) {
+	t.Parallel()
+
+	const maxBytes = 1 << 20
+	var written atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/small" {
+			fmt.Fprint(w, "small")
+			return
+		}
+		// An endless stream, as far as the client cares
+		chunk := []byte(strings.Repeat("a", 32<<10))
+		for written.Load() < 256<<20 {
+			n, err := w.Write(chunk)
+			written.Add(int64(n))
+			if err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	fetcher := NewHTTPFetcherWithOptions(HTTPFetcherOptions{MaxBodySize: maxBytes})
+	defer fetcher.Close()
+
+	_, _, err := fetcher.Fetch(server.URL + "/huge")
+	require.ErrorIs(t, err, ErrBodyTooLarge)
+	require.Contains(t, err.Error(), "/huge is over 1048576 bytes")
+	// The transfer was cut, only socket buffers are past the limit
+	require.Less(t, written.Load(), int64(64<<20))
+
+	body, _, err := fetcher.Fetch(server.URL + "/small")
+	require.NoError(t, err)
+	defer body.Close()
+	content, err := io.ReadAll(body)
+	require.NoError(t, err)
+	require.Equal(t, "small", string(content))
+}
+
+func TestHTTPFetcher_RepeatedHeaders(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Set-Cookie", "a=1")
+		w.Header().Add("Set-Cookie", "b=2")
+		w.Header().Add("Link", "</a.css>; rel=preload")
+		w.Header().Add("Link", "</b.js>; rel=preload")
+		fmt.Fprint(w, "ok")
+	}))
+	defer server.Close()
+
+	fetcher := NewHTTPFetcher()
+	body, info, err := fetcher.FetchWithInfo(server.URL)
+	require.NoError(t, err)
+	defer body.Close()
+
+	require.Equal(t, []string{"a=1", "b=2"}, info.Headers.Values("Set-Cookie"))
+	// cycleTLS only reports the last value of other repeated headers
+	require.Equal(t, []string{"</b.js>; rel=preload"}, info.Headers.Values("Link"))
+}
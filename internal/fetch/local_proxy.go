@@ -0,0 +1,207 @@
+package fetch
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// newLocalProxy creates a localProxy dialing the hosts of overrides, keyed as
+// returned by ParseResolve, at their IP, and cutting the tunnels relaying more
+// than maxBytes of response (0 for no limit). It only starts listening on
+// first use.
+func newLocalProxy(overrides map[string]string, maxBytes int64) *localProxy {
+	p := &localProxy{
+		overrides: make(map[string]string, len(overrides)),
+		maxBytes:  maxBytes,
+		transfers: make(map[string]*transfer),
+	}
+	for key, ip := range overrides {
+		p.overrides[strings.ToLower(key)] = ip
+	}
+	return p
+}
+
+// localProxy is a local HTTP CONNECT proxy the HTTPFetcher tunnels its
+// requests through, since cycleTLS has no dialer hook and buffers whole
+// response bodies. It dials the hosts of its overrides at the given IP
+// instead of resolving them, so the TLS handshake (and its SNI) and the Host
+// header still carry the original host name; other hosts are dialed
+// unchanged. With maxBytes, it stops relaying a response once past the limit,
+// so a huge body is never downloaded whole.
+type localProxy struct {
+	overrides map[string]string // "host" or "host:port", lowercase, to IP
+	maxBytes  int64             // Response bytes relayed per request before the tunnel is cut, 0 for no limit
+
+	mu        sync.Mutex
+	transfers map[string]*transfer // Requests in flight, by the proxy user of their URL
+
+	once     sync.Once
+	listener net.Listener
+	err      error
+}
+
+// transfer tracks the tunnel of a single request through a localProxy.
+type transfer struct {
+	truncated atomic.Bool // The response was over the proxy's maxBytes and was cut
+}
+
+// transferSlack is added to maxBytes when relaying a response, for its
+// headers, the TLS handshake and records and the chunked or HTTP/2 framing,
+// none of which count towards the body size.
+func (p *localProxy) transferSlack() int64 {
+	return 64<<10 + p.maxBytes/64
+}
+
+// url starts the proxy on first use and returns its URL.
+func (p *localProxy) url() (string, error) {
+	p.once.Do(func() {
+		p.listener, p.err = net.Listen("tcp", "127.0.0.1:0")
+		if p.err != nil {
+			p.err = fmt.Errorf("http_fetcher: failed to start the local proxy: %w", p.err)
+			return
+		}
+		go p.serve()
+	})
+	if p.err != nil {
+		return "", p.err
+	}
+	return "http://" + p.listener.Addr().String(), nil
+}
+
+// begin registers a request and returns the proxy URL to send it through,
+// which identifies it in the CONNECT requests, and its transfer. The caller
+// must call end with the same URL once the request is done.
+func (p *localProxy) begin() (string, *transfer, error) {
+	proxyURL, err := p.url()
+	if err != nil {
+		return "", nil, err
+	}
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "", nil, fmt.Errorf("http_fetcher: failed to identify a request to the local proxy: %w", err)
+	}
+	user := hex.EncodeToString(id)
+	t := &transfer{}
+	p.mu.Lock()
+	p.transfers[user] = t
+	p.mu.Unlock()
+	return strings.Replace(proxyURL, "://", "://"+user+"@", 1), t, nil
+}
+
+// end unregisters the request sent through proxyURL.
+func (p *localProxy) end(proxyURL string) {
+	user := strings.TrimPrefix(proxyURL, "http://")
+	user, _, _ = strings.Cut(user, "@")
+	p.mu.Lock()
+	delete(p.transfers, user)
+	p.mu.Unlock()
+}
+
+// close stops the proxy, if it was started.
+func (p *localProxy) close() {
+	p.once.Do(func() {}) // Never start it after this
+	if p.listener != nil {
+		p.listener.Close()
+	}
+}
+
+// target returns the address actually dialed for address ("host:port").
+func (p *localProxy) target(address string) string {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return address
+	}
+	host = strings.ToLower(host)
+	if ip, ok := p.overrides[net.JoinHostPort(host, port)]; ok {
+		return net.JoinHostPort(ip, port)
+	}
+	if ip, ok := p.overrides[host]; ok {
+		return net.JoinHostPort(ip, port)
+	}
+	return address
+}
+
+// transferOf returns the transfer of the request a CONNECT request belongs
+// to, from the proxy user of its URL, or nil if it is unknown.
+func (p *localProxy) transferOf(req *http.Request) *transfer {
+	credentials, ok := strings.CutPrefix(req.Header.Get("Proxy-Authorization"), "Basic ")
+	if !ok {
+		return nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(credentials)
+	if err != nil {
+		return nil
+	}
+	user, _, _ := strings.Cut(string(decoded), ":")
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.transfers[user]
+}
+
+func (p *localProxy) serve() {
+	for {
+		conn, err := p.listener.Accept()
+		if err != nil {
+			return // Closed
+		}
+		go p.handle(conn)
+	}
+}
+
+// handle answers a single CONNECT request and relays the tunnel.
+func (p *localProxy) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	req, err := http.ReadRequest(reader)
+	if err != nil {
+		return
+	}
+	if req.Method != http.MethodConnect {
+		fmt.Fprint(conn, "HTTP/1.1 405 Method Not Allowed\r\nConnection: close\r\n\r\n")
+		return
+	}
+
+	upstream, err := net.DialTimeout("tcp", p.target(req.Host), 30*time.Second)
+	if err != nil {
+		fmt.Fprint(conn, "HTTP/1.1 502 Bad Gateway\r\nConnection: close\r\n\r\n")
+		return
+	}
+	defer upstream.Close()
+	if _, err := fmt.Fprint(conn, "HTTP/1.1 200 Connection Established\r\n\r\n"); err != nil {
+		return
+	}
+
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(upstream, reader) // Anything the client sent after the request is buffered in reader
+		done <- struct{}{}
+	}()
+	go func() {
+		if p.maxBytes <= 0 {
+			io.Copy(conn, upstream)
+		} else if limit := p.maxBytes + p.transferSlack(); copyLimited(conn, upstream, limit) {
+			if t := p.transferOf(req); t != nil {
+				t.truncated.Store(true)
+			}
+		}
+		done <- struct{}{}
+	}()
+	<-done // Either side closing ends the tunnel
+}
+
+// copyLimited copies from src to dst until EOF or limit bytes, and reports
+// whether src had more than limit bytes.
+func copyLimited(dst io.Writer, src io.Reader, limit int64) bool {
+	n, _ := io.CopyN(dst, src, limit+1)
+	return n > limit
+}
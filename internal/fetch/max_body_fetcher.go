@@ -0,0 +1,89 @@
+package fetch
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// DefaultMaxBodySize is the largest response body read by default, 10MB.
+const DefaultMaxBodySize int64 = 10 << 20
+
+// ErrBodyTooLarge is returned while reading a response body larger than
+// the limit of a MaxBodyFetcher.
+var ErrBodyTooLarge = errors.New("response too large")
+
+// MaxBodyFetcher decorates a Fetcher so that reading a response body fails
+// with ErrBodyTooLarge past maxBytes, instead of handing a huge page or
+// chunk to the HTML parser and the JS engine.
+type MaxBodyFetcher struct {
+	Fetcher
+	maxBytes int64
+}
+
+// NewMaxBodyFetcher wraps fetcher, limiting the bodies it returns to
+// maxBytes bytes.
+func NewMaxBodyFetcher(fetcher Fetcher, maxBytes int64) *MaxBodyFetcher {
+	return &MaxBodyFetcher{
+		Fetcher:  fetcher,
+		maxBytes: maxBytes,
+	}
+}
+
+// Fetch implements the Fetcher interface.
+func (f *MaxBodyFetcher) Fetch(targetURL string) (io.ReadCloser, string, error) {
+	reader, finalURL, err := f.Fetcher.Fetch(targetURL)
+	if err != nil {
+		return reader, finalURL, err
+	}
+	return f.limit(reader, finalURL), finalURL, nil
+}
+
+// FetchWithInfo implements the InfoFetcher interface.
+func (f *MaxBodyFetcher) FetchWithInfo(targetURL string) (io.ReadCloser, *ResponseInfo, error) {
+	reader, info, err := FetchWithInfo(f.Fetcher, targetURL)
+	if err != nil {
+		return reader, info, err
+	}
+	finalURL := targetURL
+	if info != nil {
+		finalURL = info.FinalURL
+	}
+	return f.limit(reader, finalURL), info, nil
+}
+
+func (f *MaxBodyFetcher) limit(reader io.ReadCloser, targetURL string) io.ReadCloser {
+	return &limitedBody{
+		ReadCloser: reader,
+		limited:    io.LimitReader(reader, f.maxBytes+1),
+		maxBytes:   f.maxBytes,
+		targetURL:  targetURL,
+	}
+}
+
+// limitedBody reads at most maxBytes bytes of a body, and fails with
+// ErrBodyTooLarge when there are more.
+type limitedBody struct {
+	io.ReadCloser
+	limited   io.Reader // Reads one byte past maxBytes, to detect larger bodies
+	maxBytes  int64
+	read      int64
+	targetURL string
+}
+
+// Read implements the io.Reader interface.
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.read > b.maxBytes {
+		return 0, b.tooLarge()
+	}
+	n, err := b.limited.Read(p)
+	b.read += int64(n)
+	if b.read > b.maxBytes {
+		return n - int(b.read-b.maxBytes), b.tooLarge()
+	}
+	return n, err
+}
+
+func (b *limitedBody) tooLarge() error {
+	return fmt.Errorf("max_body_fetcher: %w: %s is over %d bytes", ErrBodyTooLarge, b.targetURL, b.maxBytes)
+}
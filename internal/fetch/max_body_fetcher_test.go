@@ -0,0 +1,72 @@
+package fetch
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// bodyFetcher returns the same body for every URL.
+type bodyFetcher struct {
+	body string
+}
+
+func (f bodyFetcher) Fetch(targetURL string) (io.ReadCloser, string, error) {
+	return io.NopCloser(strings.NewReader(f.body)), targetURL, nil
+}
+
+func (f bodyFetcher) Capabilities() FetcherCapabilities {
+	return FetcherCapabilities{}
+}
+
+func TestMaxBodyFetcher(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		body     string
+		maxBytes int64
+		wantErr  bool
+	}{
+		{name: "under the limit", body: "hello", maxBytes: 10},
+		{name: "exactly the limit", body: "hello", maxBytes: 5},
+		{name: "over the limit", body: "hello world", maxBytes: 5, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fetcher := NewMaxBodyFetcher(bodyFetcher{body: tt.body}, tt.maxBytes)
+			reader, _, err := fetcher.Fetch("https://example.com/")
+			require.NoError(t, err)
+			defer reader.Close()
+
+			body, err := io.ReadAll(reader)
+			if !tt.wantErr {
+				require.NoError(t, err)
+				require.Equal(t, tt.body, string(body))
+				return
+			}
+			require.ErrorIs(t, err, ErrBodyTooLarge)
+			require.Contains(t, err.Error(), "response too large")
+			require.Equal(t, tt.body[:tt.maxBytes], string(body))
+		})
+	}
+}
+
+func TestMaxBodyFetcher_FetchWithInfo(t *testing.T) {
+	t.Parallel()
+
+	fetcher := NewMaxBodyFetcher(bodyFetcher{body: strings.Repeat("a", 100)}, 10)
+	reader, info, err := fetcher.FetchWithInfo("https://example.com/big.js")
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/big.js", info.FinalURL)
+	defer reader.Close()
+
+	_, err = io.ReadAll(reader)
+	require.ErrorIs(t, err, ErrBodyTooLarge)
+	require.Contains(t, err.Error(), "https://example.com/big.js")
+}
@@ -0,0 +1,57 @@
+package fetch
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimitedFetcher decorates a Fetcher so that every request first waits
+// for a token from a shared limiter. Sharing one limiter between several
+// fetchers (or scans) enforces a single global request budget.
+type RateLimitedFetcher struct {
+	Fetcher
+	ctx     context.Context
+	limiter *rate.Limiter
+}
+
+// NewRateLimitedFetcher wraps fetcher with limiter. Waiting for a token
+// blocks until one is available or ctx is done.
+func NewRateLimitedFetcher(ctx context.Context, fetcher Fetcher, limiter *rate.Limiter) *RateLimitedFetcher {
+	return &RateLimitedFetcher{
+		Fetcher: fetcher,
+		ctx:     ctx,
+		limiter: limiter,
+	}
+}
+
+// NewLimiter creates a token-bucket limiter allowing requestsPerSecond
+// requests per second, with bursts of at most one request.
+func NewLimiter(requestsPerSecond float64) *rate.Limiter {
+	return rate.NewLimiter(rate.Limit(requestsPerSecond), 1)
+}
+
+// Fetch implements the Fetcher interface.
+func (f *RateLimitedFetcher) Fetch(targetURL string) (io.ReadCloser, string, error) {
+	if err := f.wait(); err != nil {
+		return nil, targetURL, err
+	}
+	return f.Fetcher.Fetch(targetURL)
+}
+
+// FetchWithInfo implements the InfoFetcher interface.
+func (f *RateLimitedFetcher) FetchWithInfo(targetURL string) (io.ReadCloser, *ResponseInfo, error) {
+	if err := f.wait(); err != nil {
+		return nil, &ResponseInfo{FinalURL: targetURL}, err
+	}
+	return FetchWithInfo(f.Fetcher, targetURL)
+}
+
+func (f *RateLimitedFetcher) wait() error {
+	if err := f.limiter.Wait(f.ctx); err != nil {
+		return fmt.Errorf("rate_limited_fetcher: waiting for rate limit: %w", err)
+	}
+	return nil
+}
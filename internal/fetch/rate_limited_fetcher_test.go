@@ -0,0 +1,64 @@
+package fetch
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// countingFetcher returns an empty body and counts calls.
+type countingFetcher struct {
+	calls atomic.Int32
+}
+
+func (f *countingFetcher) Fetch(targetURL string) (io.ReadCloser, string, error) {
+	f.calls.Add(1)
+	return io.NopCloser(strings.NewReader("")), targetURL, nil
+}
+
+func (f *countingFetcher) Capabilities() FetcherCapabilities {
+	return FetcherCapabilities{}
+}
+
+func TestRateLimitedFetcher_SharesBudget(t *testing.T) {
+	t.Parallel()
+
+	inner := &countingFetcher{}
+	limiter := NewLimiter(20)
+	a := NewRateLimitedFetcher(context.Background(), inner, limiter)
+	b := NewRateLimitedFetcher(context.Background(), inner, limiter)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, _, err := a.Fetch("https://example.com/a")
+		require.NoError(t, err)
+		_, _, err = b.Fetch("https://example.com/b")
+		require.NoError(t, err)
+	}
+
+	// 6 requests at 20/s with a burst of 1 need at least 5 intervals of 50ms.
+	require.GreaterOrEqual(t, time.Since(start), 240*time.Millisecond)
+	require.Equal(t, int32(6), inner.calls.Load())
+}
+
+func TestRateLimitedFetcher_StopsOnCancel(t *testing.T) {
+	t.Parallel()
+
+	inner := &countingFetcher{}
+	ctx, cancel := context.WithCancel(context.Background())
+	fetcher := NewRateLimitedFetcher(ctx, inner, NewLimiter(0.1))
+
+	_, _, err := fetcher.Fetch("https://example.com/first")
+	require.NoError(t, err)
+
+	cancel()
+	_, _, err = fetcher.Fetch("https://example.com/second")
+
+	require.Error(t, err)
+	require.Equal(t, int32(1), inner.calls.Load())
+}
@@ -0,0 +1,53 @@
+package fetch
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParseResolve converts curl-style --resolve values into the address
+// overrides of HTTPFetcherOptions.Resolve. Each value is "host:ip", applying
+// to every port of host, or "host:port:ip", applying to that port only.
+// IPv6 addresses may be bracketed ("host:443:[::1]").
+func ParseResolve(values []string) (map[string]string, error) {
+	overrides := make(map[string]string, len(values))
+	for _, v := range values {
+		host, rest, found := strings.Cut(strings.TrimSpace(v), ":")
+		if !found || host == "" {
+			return nil, fmt.Errorf("invalid resolve entry '%s', expected 'host:ip' or 'host:port:ip'", v)
+		}
+		key := strings.ToLower(host)
+		ip := strings.Trim(rest, "[]")
+		if net.ParseIP(ip) == nil {
+			port, portIP, found := strings.Cut(rest, ":")
+			ip = strings.Trim(portIP, "[]")
+			if n, err := strconv.Atoi(port); !found || err != nil || n <= 0 || n > 65535 || net.ParseIP(ip) == nil {
+				return nil, fmt.Errorf("invalid resolve entry '%s', expected 'host:ip' or 'host:port:ip' with a valid IP address", v)
+			}
+			key = net.JoinHostPort(key, port)
+		}
+		overrides[key] = ip
+	}
+	return overrides, nil
+}
+
+// hostResolverRules converts the overrides into Chrome's --host-resolver-rules
+// syntax, e.g. "MAP example.com 203.0.113.7".
+func hostResolverRules(overrides map[string]string) string {
+	rules := make([]string, 0, len(overrides))
+	for key, ip := range overrides {
+		if host, port, err := net.SplitHostPort(key); err == nil {
+			rules = append(rules, fmt.Sprintf("MAP %s %s", net.JoinHostPort(host, port), net.JoinHostPort(ip, port)))
+			continue
+		}
+		if strings.Contains(ip, ":") {
+			ip = "[" + ip + "]"
+		}
+		rules = append(rules, fmt.Sprintf("MAP %s %s", key, ip))
+	}
+	sort.Strings(rules)
+	return strings.Join(rules, ", ")
+}
@@ -0,0 +1,49 @@
+package fetch
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// TimingFetcher decorates a Fetcher to measure the wall-clock duration of
+// every request, including failed ones. It is safe for concurrent use.
+type TimingFetcher struct {
+	Fetcher
+	mu    sync.Mutex
+	count int
+	total time.Duration
+}
+
+// NewTimingFetcher wraps fetcher with a TimingFetcher.
+func NewTimingFetcher(fetcher Fetcher) *TimingFetcher {
+	return &TimingFetcher{Fetcher: fetcher}
+}
+
+// Fetch implements the Fetcher interface.
+func (f *TimingFetcher) Fetch(targetURL string) (io.ReadCloser, string, error) {
+	defer f.record(time.Now())
+	return f.Fetcher.Fetch(targetURL)
+}
+
+// FetchWithInfo implements the InfoFetcher interface.
+func (f *TimingFetcher) FetchWithInfo(targetURL string) (io.ReadCloser, *ResponseInfo, error) {
+	defer f.record(time.Now())
+	return FetchWithInfo(f.Fetcher, targetURL)
+}
+
+// Stats returns the number of requests made so far and their cumulative
+// duration. Concurrent requests overlap, so the total may exceed the elapsed time.
+func (f *TimingFetcher) Stats() (count int, total time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.count, f.total
+}
+
+func (f *TimingFetcher) record(start time.Time) {
+	elapsed := time.Since(start)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.count++
+	f.total += elapsed
+}
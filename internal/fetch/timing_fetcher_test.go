@@ -0,0 +1,48 @@
+package fetch
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// sleepingFetcher returns an empty body after a fixed delay.
+type sleepingFetcher struct {
+	delay time.Duration
+}
+
+func (f sleepingFetcher) Fetch(targetURL string) (io.ReadCloser, string, error) {
+	time.Sleep(f.delay)
+	return io.NopCloser(strings.NewReader("")), targetURL, nil
+}
+
+func (f sleepingFetcher) Capabilities() FetcherCapabilities {
+	return FetcherCapabilities{}
+}
+
+func TestTimingFetcher_Stats(t *testing.T) {
+	t.Parallel()
+
+	fetcher := NewTimingFetcher(sleepingFetcher{delay: 20 * time.Millisecond})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, _ = fetcher.Fetch("https://example.com/")
+		}()
+	}
+	wg.Wait()
+	_, info, err := fetcher.FetchWithInfo("https://example.com/")
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/", info.FinalURL)
+
+	count, total := fetcher.Stats()
+	require.Equal(t, 4, count)
+	require.GreaterOrEqual(t, total, 80*time.Millisecond)
+}
@@ -0,0 +1,128 @@
+package mcpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxAPIRequestBytes bounds the size of a POST /scan request body.
+const maxAPIRequestBytes = 1 << 20
+
+// apiScanRequest is the body accepted by POST /scan.
+type apiScanRequest struct {
+	URL     string `json:"url"`
+	BaseURL string `json:"base_url"`
+	Format  string `json:"format"`
+}
+
+// apiError is the body returned by the REST API on failure.
+type apiError struct {
+	Error string `json:"error"`
+}
+
+// registerAPIRoutes adds the plain REST endpoints to mux.
+func (s *MCPServer) registerAPIRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/scan", s.handleAPIScan)
+	mux.HandleFunc("/healthz", handleAPIHealthz)
+}
+
+// handleAPIHealthz reports that the server is up.
+func handleAPIHealthz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeAPIError(w, http.StatusMethodNotAllowed, "method not allowed, use GET")
+		return
+	}
+	writeAPIJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleAPIScan runs a scan for POST /scan and returns the ScanResult as JSON,
+// or as plain text when "format" is "text". Partial results of a failed scan
+// are still returned, with the error in the X-Nextr4y-Scan-Error header.
+func (s *MCPServer) handleAPIScan(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeAPIError(w, http.StatusMethodNotAllowed, "method not allowed, use POST")
+		return
+	}
+
+	req, err := decodeAPIScanRequest(w, r)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	slog.Info("Received API scan request", "target", req.URL, "format", req.Format)
+
+	result, err := s.runScan(r.Context(), req.URL, s.scanOptions(req.BaseURL, false))
+	if errors.Is(err, ErrServerBusy) || errors.Is(err, ErrServerShuttingDown) {
+		writeAPIError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	if result == nil {
+		writeAPIError(w, http.StatusBadGateway, fmt.Sprintf("error scanning target: %v", err))
+		return
+	}
+	if err != nil {
+		w.Header().Set("X-Nextr4y-Scan-Error", err.Error())
+	}
+
+	if req.Format == "text" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, formatTextResult(result))
+		return
+	}
+	writeAPIJSON(w, http.StatusOK, result)
+}
+
+// decodeAPIScanRequest parses and validates a POST /scan body.
+func decodeAPIScanRequest(w http.ResponseWriter, r *http.Request) (*apiScanRequest, error) {
+	var req apiScanRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAPIRequestBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		return nil, fmt.Errorf("invalid JSON body: %v", err)
+	}
+
+	req.URL = strings.TrimSpace(req.URL)
+	if req.URL == "" {
+		return nil, fmt.Errorf("missing required field 'url'")
+	}
+	if _, err := url.Parse(req.URL); err != nil {
+		return nil, fmt.Errorf("invalid 'url': %v", err)
+	}
+	if req.BaseURL != "" {
+		if u, err := url.Parse(req.BaseURL); err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("invalid 'base_url', expected an absolute URL")
+		}
+	}
+	switch req.Format {
+	case "":
+		req.Format = "json"
+	case "json", "text":
+	default:
+		return nil, fmt.Errorf("invalid 'format' '%s', use 'json' or 'text'", req.Format)
+	}
+	return &req, nil
+}
+
+// writeAPIJSON writes v as an indented JSON response.
+func writeAPIJSON(w http.ResponseWriter, status int, v interface{}) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error converting response to JSON: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(data)
+}
+
+// writeAPIError writes a JSON error response.
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	writeAPIJSON(w, status, apiError{Error: message})
+}
@@ -0,0 +1,77 @@
+package mcpserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPI_Healthz(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	NewMCPServer("localhost", 0).registerAPIRoutes(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"status":"ok"}`, rec.Body.String())
+}
+
+func TestAPI_ScanValidation(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+		wantError  string
+	}{
+		{name: "Wrong method", method: http.MethodGet, wantStatus: http.StatusMethodNotAllowed, wantError: "use POST"},
+		{name: "Invalid JSON", method: http.MethodPost, body: `{"url":`, wantStatus: http.StatusBadRequest, wantError: "invalid JSON body"},
+		{name: "Unknown field", method: http.MethodPost, body: `{"url":"example.com","target":"x"}`, wantStatus: http.StatusBadRequest, wantError: "unknown field"},
+		{name: "Missing URL", method: http.MethodPost, body: `{"format":"json"}`, wantStatus: http.StatusBadRequest, wantError: "missing required field 'url'"},
+		{name: "Relative base URL", method: http.MethodPost, body: `{"url":"example.com","base_url":"/cdn"}`, wantStatus: http.StatusBadRequest, wantError: "invalid 'base_url'"},
+		{name: "Unknown format", method: http.MethodPost, body: `{"url":"example.com","format":"xml"}`, wantStatus: http.StatusBadRequest, wantError: "invalid 'format'"},
+	}
+
+	mux := http.NewServeMux()
+	NewMCPServer("localhost", 0).registerAPIRoutes(mux)
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(tc.method, "/scan", strings.NewReader(tc.body)))
+
+			require.Equal(t, tc.wantStatus, rec.Code)
+			require.Contains(t, rec.Body.String(), tc.wantError)
+		})
+	}
+}
+
+func TestAcquireScanSlot_RejectsWhenBusy(t *testing.T) {
+	t.Parallel()
+
+	s := NewMCPServerWithOptions("localhost", 0, Options{MaxConcurrentScans: 1, ScanQueueTimeout: 20 * time.Millisecond})
+
+	release, err := s.acquireScanSlot(context.Background())
+	require.NoError(t, err)
+
+	_, err = s.acquireScanSlot(context.Background())
+	require.ErrorIs(t, err, ErrServerBusy)
+
+	release()
+	release, err = s.acquireScanSlot(context.Background())
+	require.NoError(t, err)
+	release()
+}
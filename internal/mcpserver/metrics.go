@@ -0,0 +1,155 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rodrigopv/nextr4y"
+	"github.com/rodrigopv/nextr4y/internal/scanner"
+)
+
+// scanDurationBuckets are the upper bounds, in seconds, of the scan duration
+// histogram buckets.
+var scanDurationBuckets = []float64{1, 2.5, 5, 10, 20, 30, 60, 120}
+
+// Error types reported in the nextr4y_scan_errors_total metric.
+const (
+	scanErrorBusy      = "busy"       // Rejected, no scan slot became available
+	scanErrorShutdown  = "shutdown"   // Rejected, the server is shutting down
+	scanErrorCanceled  = "canceled"   // The client went away or the request timed out
+	scanErrorNotNextJS = "not_nextjs" // The target has no __NEXT_DATA__ nor Next.js scripts
+	scanErrorSetup     = "setup"      // The scan failed without any result (e.g. invalid URL)
+	scanErrorPartial   = "partial"    // The scan failed but returned partial results
+)
+
+// scanMetrics tracks scan volume, duration and errors, and serves them in
+// the Prometheus text exposition format. It is safe for concurrent use.
+type scanMetrics struct {
+	mu            sync.Mutex
+	total         int64
+	inFlight      int64
+	errors        map[string]int64
+	bucketCounts  []int64 // Non-cumulative count of scans per duration bucket
+	durationSum   float64
+	durationCount int64
+}
+
+// newScanMetrics creates a scanMetrics with all counters at zero.
+func newScanMetrics() *scanMetrics {
+	return &scanMetrics{
+		errors:       make(map[string]int64),
+		bucketCounts: make([]int64, len(scanDurationBuckets)),
+	}
+}
+
+// rejected records a scan request that never ran.
+func (m *scanMetrics) rejected(errorType string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.total++
+	m.errors[errorType]++
+}
+
+// started records the start of a scan. The returned function must be called
+// when it ends, with its error type ("" on success).
+func (m *scanMetrics) started() func(errorType string) {
+	start := time.Now()
+	m.mu.Lock()
+	m.total++
+	m.inFlight++
+	m.mu.Unlock()
+
+	return func(errorType string) {
+		seconds := time.Since(start).Seconds()
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.inFlight--
+		if errorType != "" {
+			m.errors[errorType]++
+		}
+		m.durationSum += seconds
+		m.durationCount++
+		for i, bound := range scanDurationBuckets {
+			if seconds <= bound {
+				m.bucketCounts[i]++
+				break
+			}
+		}
+	}
+}
+
+// ServeHTTP serves the metrics for GET /metrics.
+func (m *scanMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeAPIError(w, http.StatusMethodNotAllowed, "method not allowed, use GET")
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	fmt.Fprint(w, m.render())
+}
+
+// render returns the metrics in the Prometheus text exposition format.
+func (m *scanMetrics) render() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var sb strings.Builder
+	sb.WriteString("# HELP nextr4y_scans_total Scan requests received, including rejected ones.\n")
+	sb.WriteString("# TYPE nextr4y_scans_total counter\n")
+	fmt.Fprintf(&sb, "nextr4y_scans_total %d\n", m.total)
+
+	sb.WriteString("# HELP nextr4y_scans_in_flight Scans currently running.\n")
+	sb.WriteString("# TYPE nextr4y_scans_in_flight gauge\n")
+	fmt.Fprintf(&sb, "nextr4y_scans_in_flight %d\n", m.inFlight)
+
+	sb.WriteString("# HELP nextr4y_scan_errors_total Failed scan requests, by error type.\n")
+	sb.WriteString("# TYPE nextr4y_scan_errors_total counter\n")
+	errorTypes := make([]string, 0, len(m.errors))
+	for errorType := range m.errors {
+		errorTypes = append(errorTypes, errorType)
+	}
+	sort.Strings(errorTypes)
+	for _, errorType := range errorTypes {
+		fmt.Fprintf(&sb, "nextr4y_scan_errors_total{type=%q} %d\n", errorType, m.errors[errorType])
+	}
+
+	sb.WriteString("# HELP nextr4y_scan_duration_seconds Duration of the scans that ran.\n")
+	sb.WriteString("# TYPE nextr4y_scan_duration_seconds histogram\n")
+	var cumulative int64
+	for i, bound := range scanDurationBuckets {
+		cumulative += m.bucketCounts[i]
+		fmt.Fprintf(&sb, "nextr4y_scan_duration_seconds_bucket{le=%q} %d\n", strconv.FormatFloat(bound, 'f', -1, 64), cumulative)
+	}
+	fmt.Fprintf(&sb, "nextr4y_scan_duration_seconds_bucket{le=\"+Inf\"} %d\n", m.durationCount)
+	fmt.Fprintf(&sb, "nextr4y_scan_duration_seconds_sum %s\n", strconv.FormatFloat(m.durationSum, 'f', -1, 64))
+	fmt.Fprintf(&sb, "nextr4y_scan_duration_seconds_count %d\n", m.durationCount)
+	return sb.String()
+}
+
+// scanErrorType classifies the outcome of runScan for the error metric.
+// It returns "" when the scan succeeded.
+func scanErrorType(result *nextr4y.ScanResult, err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, ErrServerBusy):
+		return scanErrorBusy
+	case errors.Is(err, ErrServerShuttingDown):
+		return scanErrorShutdown
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return scanErrorCanceled
+	case errors.Is(err, scanner.ErrNextDataNotFound):
+		return scanErrorNotNextJS
+	case result == nil:
+		return scanErrorSetup
+	default:
+		return scanErrorPartial
+	}
+}
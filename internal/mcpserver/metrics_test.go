@@ -0,0 +1,62 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigopv/nextr4y"
+	"github.com/rodrigopv/nextr4y/internal/scanner"
+)
+
+func TestScanErrorType(t *testing.T) {
+	t.Parallel()
+
+	result := &nextr4y.ScanResult{}
+	testCases := []struct {
+		name   string
+		result *nextr4y.ScanResult
+		err    error
+		want   string
+	}{
+		{name: "Success", result: result, want: ""},
+		{name: "Busy", err: ErrServerBusy, want: scanErrorBusy},
+		{name: "Canceled", err: fmt.Errorf("scan: %w", context.Canceled), want: scanErrorCanceled},
+		{name: "Not Next.js", result: result, err: scanner.ErrNextDataNotFound, want: scanErrorNotNextJS},
+		{name: "No result", err: errors.New("invalid URL"), want: scanErrorSetup},
+		{name: "Partial result", result: result, err: errors.New("manifest processing failed"), want: scanErrorPartial},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.want, scanErrorType(tc.result, tc.err), tc.name)
+	}
+}
+
+func TestScanMetrics_Render(t *testing.T) {
+	t.Parallel()
+
+	metrics := newScanMetrics()
+	metrics.rejected(scanErrorBusy)
+	metrics.started()("")
+	metrics.started()(scanErrorPartial)
+	metrics.started() // Still running
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	require.Contains(t, body, "nextr4y_scans_total 4\n")
+	require.Contains(t, body, "nextr4y_scans_in_flight 1\n")
+	require.Contains(t, body, "nextr4y_scan_errors_total{type=\"busy\"} 1\n")
+	require.Contains(t, body, "nextr4y_scan_errors_total{type=\"partial\"} 1\n")
+	require.Contains(t, body, "nextr4y_scan_duration_seconds_bucket{le=\"1\"} 2\n")
+	require.Contains(t, body, "nextr4y_scan_duration_seconds_bucket{le=\"2.5\"} 2\n")
+	require.Contains(t, body, "nextr4y_scan_duration_seconds_bucket{le=\"+Inf\"} 2\n")
+	require.Contains(t, body, "nextr4y_scan_duration_seconds_count 2\n")
+}
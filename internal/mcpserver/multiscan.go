@@ -0,0 +1,122 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/rodrigopv/nextr4y"
+	"github.com/rodrigopv/nextr4y/internal/fetch"
+)
+
+const (
+	// MaxScanURLs is the maximum number of targets of a single scan tool call.
+	MaxScanURLs = 10
+	// multiScanConcurrency bounds the scans one tool call runs at once, so a
+	// single client can't take every scan slot of the server.
+	multiScanConcurrency = 3
+)
+
+// multiScanResult is one entry of the array returned for a "urls" scan.
+type multiScanResult struct {
+	URL    string              `json:"url"`
+	Result *nextr4y.ScanResult `json:"result,omitempty"`
+	Error  string              `json:"error,omitempty"`
+}
+
+// scanToolTargets validates the "url" and "urls" arguments of the scan tool,
+// exactly one of which must be given, and returns the targets to scan.
+// multi reports whether they came from "urls".
+func scanToolTargets(args map[string]interface{}) (targets []string, multi bool, err error) {
+	targetURL, _ := args["url"].(string)
+	rawURLs, hasURLs := args["urls"]
+
+	switch {
+	case targetURL != "" && hasURLs:
+		return nil, false, errors.New("provide either 'url' or 'urls', not both")
+	case targetURL != "":
+		return []string{targetURL}, false, nil
+	case !hasURLs:
+		return nil, false, errors.New("missing or invalid target URL: provide 'url' or 'urls'")
+	}
+
+	items, ok := rawURLs.([]interface{})
+	if !ok || len(items) == 0 {
+		return nil, false, errors.New("'urls' must be a non-empty array of URLs")
+	}
+	if len(items) > MaxScanURLs {
+		return nil, false, fmt.Errorf("too many URLs: at most %d can be scanned in one call", MaxScanURLs)
+	}
+	for i, item := range items {
+		u, ok := item.(string)
+		if !ok || strings.TrimSpace(u) == "" {
+			return nil, false, fmt.Errorf("'urls[%d]' is not a valid URL", i)
+		}
+		targets = append(targets, strings.TrimSpace(u))
+	}
+	return targets, true, nil
+}
+
+// scanMany scans every target with opts, at most multiScanConcurrency at once
+// (and within the server-wide scan slots), and returns the results in order.
+// The scans share the opts.RateLimit budget.
+func (s *MCPServer) scanMany(ctx context.Context, targets []string, opts nextr4y.Options) []multiScanResult {
+	if opts.RateLimit > 0 && opts.RateLimiter == nil {
+		opts.RateLimiter = fetch.NewLimiter(opts.RateLimit)
+	}
+	results := make([]multiScanResult, len(targets))
+	slots := make(chan struct{}, multiScanConcurrency)
+	var wg sync.WaitGroup
+
+	for i, target := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			result, err := s.runScan(ctx, target, opts)
+			results[i] = multiScanResult{URL: target, Result: result}
+			if err != nil {
+				results[i].Error = err.Error()
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// multiScanToolResult renders the results of a "urls" scan in the requested format.
+func multiScanToolResult(format string, targets []string, results []multiScanResult) *mcp.CallToolResult {
+	if format != "json" {
+		sections := make([]string, 0, len(results))
+		for _, r := range results {
+			var text string
+			if r.Result != nil {
+				text = formatTextResult(r.Result)
+			} else {
+				text = fmt.Sprintf("Scan Results for: %s\n", r.URL)
+			}
+			if r.Error != "" {
+				text += fmt.Sprintf("Error: %s\n", r.Error)
+			}
+			sections = append(sections, text)
+		}
+		return mcp.NewToolResultText(strings.Join(sections, "\n---\n\n"))
+	}
+
+	var nextJS, failed int
+	for _, r := range results {
+		if r.Error != "" {
+			failed++
+		}
+		if r.Result != nil && r.Result.IsNextJS {
+			nextJS++
+		}
+	}
+	summary := fmt.Sprintf("Scan results for %d targets are attached as a JSON array (%d using Next.js, %d with errors).", len(results), nextJS, failed)
+	return jsonToolResult(summary, "scan", strings.Join(targets, ","), results)
+}
@@ -0,0 +1,84 @@
+package mcpserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigopv/nextr4y"
+)
+
+func TestScanToolTargets(t *testing.T) {
+	t.Parallel()
+
+	tooMany := make([]interface{}, MaxScanURLs+1)
+	for i := range tooMany {
+		tooMany[i] = "https://example.com"
+	}
+
+	testCases := []struct {
+		name      string
+		args      map[string]interface{}
+		want      []string
+		wantMulti bool
+		wantError string
+	}{
+		{name: "Single URL", args: map[string]interface{}{"url": "https://example.com"}, want: []string{"https://example.com"}},
+		{name: "Several URLs", args: map[string]interface{}{"urls": []interface{}{"https://a.example.com", " https://b.example.com "}}, want: []string{"https://a.example.com", "https://b.example.com"}, wantMulti: true},
+		{name: "Both", args: map[string]interface{}{"url": "https://example.com", "urls": []interface{}{"https://a.example.com"}}, wantError: "not both"},
+		{name: "Neither", args: map[string]interface{}{}, wantError: "provide 'url' or 'urls'"},
+		{name: "Empty array", args: map[string]interface{}{"urls": []interface{}{}}, wantError: "non-empty array"},
+		{name: "Not an array", args: map[string]interface{}{"urls": "https://example.com"}, wantError: "non-empty array"},
+		{name: "Invalid item", args: map[string]interface{}{"urls": []interface{}{"https://example.com", 42}}, wantError: "'urls[1]'"},
+		{name: "Too many", args: map[string]interface{}{"urls": tooMany}, wantError: "too many URLs"},
+	}
+
+	for _, tc := range testCases {
+		targets, multi, err := scanToolTargets(tc.args)
+		if tc.wantError != "" {
+			require.ErrorContains(t, err, tc.wantError, tc.name)
+			continue
+		}
+		require.NoError(t, err, tc.name)
+		require.Equal(t, tc.want, targets, tc.name)
+		require.Equal(t, tc.wantMulti, multi, tc.name)
+	}
+}
+
+func TestHandleScanToolRequest_RejectsBaseURLWithURLs(t *testing.T) {
+	t.Parallel()
+
+	var request mcp.CallToolRequest
+	request.Params.Arguments = map[string]interface{}{
+		"urls":     []interface{}{"https://a.example.com", "https://b.example.com"},
+		"base_url": "https://cdn.example.com/",
+	}
+
+	result, err := NewMCPServer("localhost", 0).handleScanToolRequest(context.Background(), request)
+
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+	require.Contains(t, result.Content[0].(mcp.TextContent).Text, "'base_url' cannot be combined with 'urls'")
+}
+
+func TestScanMany_SharesRateLimit(t *testing.T) {
+	t.Parallel()
+
+	fetcher := &recordingFetcher{}
+	s := NewMCPServerWithOptions("localhost", 0, Options{ScanOptions: nextr4y.Options{RateLimit: 20}})
+	s.fetcher = fetcher
+	targets := []string{"https://example.com", "https://example.com", "https://example.com"}
+
+	results := s.scanMany(context.Background(), targets, s.scanOptions("", true))
+
+	require.Len(t, results, 3)
+	// The concurrent scans take turns at 20 requests per second, instead of
+	// each starting with a budget of its own
+	require.GreaterOrEqual(t, len(fetcher.times), 3)
+	for i := 1; i < len(fetcher.times); i++ {
+		require.GreaterOrEqual(t, fetcher.times[i].Sub(fetcher.times[i-1]), 40*time.Millisecond, "request %d", i)
+	}
+}
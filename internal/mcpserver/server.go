@@ -3,35 +3,121 @@ package mcpserver
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/rodrigopv/nextr4y"
 	"github.com/rodrigopv/nextr4y/internal/fetch"
 	"github.com/rodrigopv/nextr4y/internal/scanner"
 	"github.com/rodrigopv/nextr4y/internal/versiondetect"
 )
 
+// Options configures optional MCPServer features.
+type Options struct {
+	// EnableHTTPAPI also serves the plain REST endpoints (POST /scan and
+	// GET /healthz) next to the MCP SSE endpoints, on the same address.
+	EnableHTTPAPI bool
+
+	// MaxConcurrentScans bounds the scans running at once across all clients.
+	// Excess requests wait up to ScanQueueTimeout for a slot and are then
+	// rejected. Zero uses DefaultMaxConcurrentScans.
+	MaxConcurrentScans int
+
+	// ScanQueueTimeout is how long a request may wait for a scan slot.
+	// Zero uses DefaultScanQueueTimeout.
+	ScanQueueTimeout time.Duration
+
+	// EnableMetrics serves Prometheus metrics (scan volume, duration, errors
+	// and in-flight scans) at GET /metrics, on the same address.
+	EnableMetrics bool
+
+	// ShutdownTimeout is how long in-flight scans may run once a shutdown
+	// signal is received. Zero uses DefaultShutdownTimeout.
+	ShutdownTimeout time.Duration
+
+	// ScanOptions are the options every scan starts from, e.g. the timeout
+	// and headers of the serve command's config file. The parameters of a
+	// request (base URL, depth) are set on top of them.
+	ScanOptions nextr4y.Options
+}
+
+const (
+	// DefaultMaxConcurrentScans is the default limit of in-flight scans.
+	DefaultMaxConcurrentScans = 4
+	// DefaultScanQueueTimeout is the default wait for a free scan slot.
+	DefaultScanQueueTimeout = 30 * time.Second
+	// DefaultShutdownTimeout is the default wait for in-flight scans on shutdown.
+	DefaultShutdownTimeout = 30 * time.Second
+)
+
+// Depths accepted by the "depth" argument of the scan tool.
+const (
+	scanDepthLight = "light" // Next.js and versions only, the build manifest and routes are skipped
+	scanDepthFull  = "full"  // The whole pipeline, the default
+)
+
+// ErrServerBusy is returned when no scan slot frees up within the queue timeout.
+var ErrServerBusy = errors.New("server busy: too many concurrent scans, try again later")
+
 // MCPServer represents an MCP server instance
 type MCPServer struct {
 	host      string
 	port      int
 	mcpServer *server.MCPServer
+	options   Options
+	fetcher   fetch.Fetcher                 // Shared by all scans, nil to create one per scan from options.ScanOptions
+	detector  versiondetect.VersionDetector // Shared by all scans
+	scanSlots chan struct{}                 // Semaphore bounding concurrent scans
+	metrics   *scanMetrics                  // Collected even when not served
+
+	scansMu      sync.Mutex
+	shuttingDown bool           // Set once a graceful shutdown started
+	activeScans  sync.WaitGroup // Scans the shutdown waits for
 }
 
 // NewMCPServer creates a new MCP server instance
 func NewMCPServer(host string, port int) *MCPServer {
+	return NewMCPServerWithOptions(host, port, Options{})
+}
+
+// NewMCPServerWithOptions creates a new MCP server instance with optional features.
+func NewMCPServerWithOptions(host string, port int, opts Options) *MCPServer {
+	if opts.MaxConcurrentScans <= 0 {
+		opts.MaxConcurrentScans = DefaultMaxConcurrentScans
+	}
+	if opts.ScanQueueTimeout <= 0 {
+		opts.ScanQueueTimeout = DefaultScanQueueTimeout
+	}
+	if opts.ShutdownTimeout <= 0 {
+		opts.ShutdownTimeout = DefaultShutdownTimeout
+	}
+	detector := opts.ScanOptions.VersionDetector
+	if detector == nil {
+		detector = nextr4y.DefaultVersionDetector(opts.ScanOptions)
+	}
 	return &MCPServer{
-		host: host,
-		port: port,
+		host:      host,
+		port:      port,
+		options:   opts,
+		fetcher:   opts.ScanOptions.Fetcher,
+		detector:  detector,
+		scanSlots: make(chan struct{}, opts.MaxConcurrentScans),
+		metrics:   newScanMetrics(),
 	}
 }
 
 // Start starts the MCP server
 func (s *MCPServer) Start() error {
 	addr := fmt.Sprintf("%s:%d", s.host, s.port)
-	log.Printf("Starting MCP server on %s\n", addr)
+	slog.Info("Starting MCP server", "addr", addr)
 	
 	// Initialize MCP server
 	err := s.InitMCPServer()
@@ -45,7 +131,7 @@ func (s *MCPServer) Start() error {
 	}
 	
 	// Use the MCP server
-	log.Printf("Starting MCP server with mark3labs/mcp-go implementation")
+	slog.Info("Starting MCP server with mark3labs/mcp-go implementation")
 	return s.StartMCPServer()
 }
 
@@ -73,41 +159,105 @@ func (s *MCPServer) handleScanRequest(params map[string]interface{}) (interface{
 		return nil, fmt.Errorf("missing or invalid target URL")
 	}
 
-	// Extract options
-	options := make(map[string]interface{})
-	if format, ok := params["format"].(string); ok {
-		options["format"] = format
+	customBaseURL, _ := params["base_url"].(string)
+
+	slog.Info("Received scan request", "target", targetURL)
+
+	result, err := s.runScan(context.Background(), targetURL, s.scanOptions(customBaseURL, false))
+	if result != nil {
+		// Partial results carry the error in ExecutionError
+		return result, nil
 	}
-	if baseURL, ok := params["base_url"].(string); ok {
-		options["base_url"] = baseURL
+	return nil, err
+}
+
+// scanOptions returns the options of a scan requested by a client: the
+// server's ScanOptions, with the base URL of the request if it gave one, and
+// skipping the routes for light scans.
+func (s *MCPServer) scanOptions(baseURL string, skipRoutes bool) nextr4y.Options {
+	opts := s.options.ScanOptions
+	if baseURL != "" {
+		opts.BaseURL = baseURL
 	}
+	if skipRoutes {
+		opts.SkipRoutes = true
+	}
+	return opts
+}
 
-	log.Printf("Received scan request for target: %s", targetURL)
+// runScan executes a scan for any of the server's handlers. When the scan
+// fails but still produced partial results, the error is also recorded in
+// the result's ExecutionError. It returns ErrServerBusy if no scan slot
+// became available in time, and ErrServerShuttingDown during a shutdown.
+func (s *MCPServer) runScan(ctx context.Context, targetURL string, opts nextr4y.Options) (*nextr4y.ScanResult, error) {
+	if !s.beginScan() {
+		slog.Warn("Rejecting scan request", "target", targetURL, "error", ErrServerShuttingDown)
+		s.metrics.rejected(scanErrorType(nil, ErrServerShuttingDown))
+		return nil, ErrServerShuttingDown
+	}
+	defer s.endScan()
 
-	// Create scanner and perform scan
-	fetcher := fetch.NewHTTPFetcher()
-	versionDetector := &versiondetect.HeuristicAssetScannerDetector{}
-	customBaseURL, _ := options["base_url"].(string)
-	scr := scanner.NewScanner(fetcher, versionDetector, customBaseURL)
+	release, err := s.acquireScanSlot(ctx)
+	if err != nil {
+		slog.Warn("Rejecting scan request", "target", targetURL, "error", err)
+		s.metrics.rejected(scanErrorType(nil, err))
+		return nil, err
+	}
+	defer release()
 
-	// Execute the scan
-	result, err := scr.ScanTarget(targetURL)
+	start := time.Now()
+	done := s.metrics.started()
+	if s.fetcher != nil {
+		opts.Fetcher = s.fetcher
+	}
+	opts.VersionDetector = s.detector
+	result, err := nextr4y.Scan(ctx, targetURL, opts)
+	errorType := scanErrorType(result, err)
+	done(errorType)
+
+	attrs := []any{"target", targetURL, "duration", time.Since(start)}
+	if result != nil {
+		attrs = append(attrs, "nextjs", result.IsNextJS, "requests", result.FetchCount)
+	}
 	if err != nil {
-		log.Printf("Scan error: %v", err)
-		// Still return partial results if available
+		slog.Warn("Scan request failed", append(attrs, "error", err, "error_type", errorType)...)
 		if result != nil {
 			result.ExecutionError = err
-			return result, nil
 		}
-		return nil, err
+	} else {
+		slog.Info("Scan request finished", attrs...)
+	}
+	return result, err
+}
+
+// acquireScanSlot waits for a free scan slot, up to the queue timeout or
+// until ctx is done. The returned function releases the slot.
+func (s *MCPServer) acquireScanSlot(ctx context.Context) (func(), error) {
+	release := func() { <-s.scanSlots }
+
+	select {
+	case s.scanSlots <- struct{}{}:
+		return release, nil
+	default:
 	}
 
-	return result, nil
+	slog.Info("All scan slots busy, queueing request", "slots", cap(s.scanSlots))
+	timer := time.NewTimer(s.options.ScanQueueTimeout)
+	defer timer.Stop()
+
+	select {
+	case s.scanSlots <- struct{}{}:
+		return release, nil
+	case <-timer.C:
+		return nil, ErrServerBusy
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 }
 
 // InitMCPServer initializes the MCP server with mcp-go
 func (s *MCPServer) InitMCPServer() error {
-	log.Println("Initializing MCP server...")
+	slog.Info("Initializing MCP server")
 	
 	// Create a new MCP server
 	mcpServer := server.NewMCPServer(
@@ -121,8 +271,12 @@ func (s *MCPServer) InitMCPServer() error {
 	scanTool := mcp.NewTool("nextr4y_scan",
 		mcp.WithDescription("Scan a Next.js site and extract information about its internal structure"),
 		mcp.WithString("url",
-			mcp.Required(),
-			mcp.Description("The URL of the target Next.js site to scan"),
+			mcp.Description("The URL of the target Next.js site to scan (required unless 'urls' is given)"),
+		),
+		mcp.WithArray("urls",
+			mcp.Description(fmt.Sprintf("Several target URLs to scan in one call, instead of 'url' (at most %d); an array of results is returned", MaxScanURLs)),
+			mcp.Items(map[string]interface{}{"type": "string"}),
+			mcp.MaxItems(MaxScanURLs),
 		),
 		mcp.WithString("format",
 			mcp.Description("Output format (text or json)"),
@@ -131,15 +285,30 @@ func (s *MCPServer) InitMCPServer() error {
 		mcp.WithString("base_url",
 			mcp.Description("Override the auto-detected base URL for asset resolution"),
 		),
+		mcp.WithString("depth",
+			mcp.Description("Scan depth: 'full' (default) runs the whole pipeline, 'light' only detects Next.js and its versions, skipping route enumeration, which is much faster"),
+			mcp.Enum(scanDepthLight, scanDepthFull),
+		),
 	)
 	
 	// Register the scan tool handler
 	mcpServer.AddTool(scanTool, s.handleScanToolRequest)
 	
+	// Create the lighter versions tool, which runs a light scan: the build
+	// manifest is neither fetched nor evaluated and no route is listed
+	versionsTool := mcp.NewTool("nextr4y_versions",
+		mcp.WithDescription("Detect whether a site uses Next.js and which Next.js and React versions it runs, skipping route enumeration, which is much faster than a full scan"),
+		mcp.WithString("url",
+			mcp.Required(),
+			mcp.Description("The URL of the target site"),
+		),
+	)
+	mcpServer.AddTool(versionsTool, s.handleVersionsToolRequest)
+	
 	// Set the MCP server in the MCPServer struct
 	s.mcpServer = mcpServer
 	
-	log.Println("MCP server initialized successfully")
+	slog.Info("MCP server initialized successfully")
 	return nil
 }
 
@@ -150,22 +319,41 @@ func (s *MCPServer) StartMCPServer() error {
 	}
 	
 	addr := fmt.Sprintf("%s:%d", s.host, s.port)
-	log.Printf("Starting MCP server on %s\n", addr)
-	
-	// Create an SSE server for HTTP communication
-	sseServer := server.NewSSEServer(s.mcpServer)
+	slog.Info("Starting MCP server", "addr", addr)
 	
+	// Create an SSE server for HTTP communication. It is given the HTTP server
+	// so its Shutdown also closes the listener.
+	srv := &http.Server{Addr: addr}
+	sseServer := server.NewSSEServer(s.mcpServer, server.WithHTTPServer(srv))
+	srv.Handler = sseServer
+
+	if s.options.EnableHTTPAPI || s.options.EnableMetrics {
+		// Serve the extra endpoints next to the SSE endpoints on the same listener
+		mux := http.NewServeMux()
+		mux.Handle("/", sseServer)
+		if s.options.EnableHTTPAPI {
+			s.registerAPIRoutes(mux)
+			slog.Info("HTTP API enabled", "scan", "POST http://"+addr+"/scan", "health", "GET http://"+addr+"/healthz")
+		}
+		if s.options.EnableMetrics {
+			mux.Handle("/metrics", s.metrics)
+			slog.Info("Metrics enabled", "metrics", "GET http://"+addr+"/metrics")
+		}
+		srv.Handler = mux
+	}
+
 	// Start the HTTP server
-	return sseServer.Start(addr)
+	return s.serveUntilSignal(srv, sseServer)
 }
 
 // handleScanToolRequest handles scan tool requests from MCP clients
 func (s *MCPServer) handleScanToolRequest(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Extract parameters
-	targetURL, ok := request.Params.Arguments["url"].(string)
-	if !ok || targetURL == "" {
-		return mcp.NewToolResultError("Missing or invalid target URL"), nil
+	targets, multi, err := scanToolTargets(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
+	targetURL := targets[0]
 	
 	// Extract optional parameters
 	format := "json"
@@ -177,32 +365,33 @@ func (s *MCPServer) handleScanToolRequest(ctx context.Context, request mcp.CallT
 	if url, ok := request.Params.Arguments["base_url"].(string); ok {
 		baseURL = url
 	}
+
+	depth := scanDepthFull
+	if d, ok := request.Params.Arguments["depth"].(string); ok && d != "" {
+		depth = d
+	}
+	if depth != scanDepthLight && depth != scanDepthFull {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid 'depth' %q: use '%s' or '%s'", depth, scanDepthLight, scanDepthFull)), nil
+	}
+	opts := s.scanOptions(baseURL, depth == scanDepthLight)
 	
-	log.Printf("Received scan request for target: %s (format: %s)", targetURL, format)
-	
-	// Create scanner and perform scan
-	fetcher := fetch.NewHTTPFetcher()
-	versionDetector := &versiondetect.HeuristicAssetScannerDetector{}
-	scr := scanner.NewScanner(fetcher, versionDetector, baseURL)
+	if multi {
+		if baseURL != "" {
+			return mcp.NewToolResultError("'base_url' cannot be combined with 'urls'"), nil
+		}
+		slog.Info("Received scan request", "targets", len(targets), "format", format, "depth", depth)
+		return multiScanToolResult(format, targets, s.scanMany(ctx, targets, opts)), nil
+	}
+
+	slog.Info("Received scan request", "target", targetURL, "format", format, "depth", depth)
 	
 	// Execute the scan
-	result, err := scr.ScanTarget(targetURL)
+	result, err := s.runScan(ctx, targetURL, opts)
 	if err != nil {
-		log.Printf("Scan error: %v", err)
 		// Still return partial results if available
 		if result != nil {
-			result.ExecutionError = err
-			
-			// Convert the result to JSON for returning
-			jsonData, jsonErr := json.MarshalIndent(result, "", "  ")
-			if jsonErr != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("Error scanning target: %v, and error converting results: %v", err, jsonErr)), nil
-			}
-			
 			// Return partial results with error message
-			return mcp.NewToolResultText(
-				fmt.Sprintf("Scan completed with errors:\n%v\n\nPartial results:\n%s", err, string(jsonData)),
-			), nil
+			return jsonToolResult(fmt.Sprintf("Scan completed with errors:\n%v\n\nPartial results are attached as JSON.", err), "scan", targetURL, result), nil
 		}
 		
 		return mcp.NewToolResultError(fmt.Sprintf("Error scanning target: %v", err)), nil
@@ -210,33 +399,48 @@ func (s *MCPServer) handleScanToolRequest(ctx context.Context, request mcp.CallT
 	
 	// Process the result based on the requested format
 	if format == "json" {
-		// Convert to JSON
-		jsonData, jsonErr := json.MarshalIndent(result, "", "  ")
-		if jsonErr != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Error converting results to JSON: %v", jsonErr)), nil
-		}
-		
-		return mcp.NewToolResultText(string(jsonData)), nil
+		summary := fmt.Sprintf("Scan results for %s are attached as JSON (Next.js: %v, %d routes).", result.BaseURL, result.IsNextJS, len(result.Routes))
+		return jsonToolResult(summary, "scan", targetURL, result), nil
 	} else {
-		// Format as text (would normally call scanner.PrintResults to get the text representation)
-		// For demonstration, we'll create a simple text version here
-		var text string
-		text += fmt.Sprintf("Target: %s\n", result.BaseURL)
-		text += fmt.Sprintf("Is Next.js: %v\n", result.IsNextJS)
-		if result.IsNextJS {
-			text += fmt.Sprintf("Build ID: %s\n", result.BuildID)
-			text += fmt.Sprintf("Next.js Version: %s\n", result.DetectedNextVersion)
-			text += fmt.Sprintf("React Version: %s\n", result.DetectedReactVersion)
-			text += fmt.Sprintf("Asset Prefix: %s\n", result.AssetPrefix)
-			text += fmt.Sprintf("Asset Base URL: %s\n", result.AssetBaseURL)
-			text += fmt.Sprintf("Routes found: %d\n", len(result.Routes))
-			
-			// Add routes
-			for route, assets := range result.Routes {
-				text += fmt.Sprintf("  - %s (%d assets)\n", route, len(assets))
-			}
-		}
-		
-		return mcp.NewToolResultText(text), nil
+		return mcp.NewToolResultText(formatTextResult(result)), nil
+	}
+}
+
+// handleVersionsToolRequest handles versions tool requests from MCP clients
+func (s *MCPServer) handleVersionsToolRequest(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	targetURL, ok := request.Params.Arguments["url"].(string)
+	if !ok || targetURL == "" {
+		return mcp.NewToolResultError("Missing or invalid target URL"), nil
 	}
+
+	slog.Info("Received versions request", "target", targetURL)
+
+	result, err := s.runScan(ctx, targetURL, s.scanOptions("", true))
+	if result == nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Error scanning target: %v", err)), nil
+	}
+
+	return jsonToolResult(fmt.Sprintf("Versions detected for %s are attached as JSON.", targetURL), "versions", targetURL, nextr4y.Versions(result)), nil
+}
+
+// jsonToolResult returns v as an embedded application/json resource, so MCP
+// clients receive typed data instead of having to parse a text blob. The
+// summary is sent as a text content block for clients that only show text.
+func jsonToolResult(summary string, kind string, targetURL string, v interface{}) *mcp.CallToolResult {
+	jsonData, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Error converting results to JSON: %v", err))
+	}
+	return mcp.NewToolResultResource(summary, mcp.TextResourceContents{
+		URI:      fmt.Sprintf("nextr4y://%s?url=%s", kind, url.QueryEscape(targetURL)),
+		MIMEType: "application/json",
+		Text:     string(jsonData),
+	})
+}
+
+// formatTextResult renders a scan result as plain text, like the CLI's text output.
+func formatTextResult(result *nextr4y.ScanResult) string {
+	var sb strings.Builder
+	scanner.RenderText(result, &sb, false)
+	return sb.String()
 } 
\ No newline at end of file
@@ -0,0 +1,138 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigopv/nextr4y"
+	"github.com/rodrigopv/nextr4y/internal/fetch"
+)
+
+// recordingFetcher serves a Next.js page at its only known URL, fails for
+// any other, and records every requested URL and when it was requested.
+type recordingFetcher struct {
+	mu    sync.Mutex
+	urls  []string
+	times []time.Time
+}
+
+func (f *recordingFetcher) Fetch(targetURL string) (io.ReadCloser, string, error) {
+	f.mu.Lock()
+	f.urls = append(f.urls, targetURL)
+	f.times = append(f.times, time.Now())
+	f.mu.Unlock()
+	if targetURL != "https://example.com" {
+		return nil, targetURL, errors.New("stub: not found")
+	}
+	page := `<html><head><script src="/_next/static/chunks/main-abc123.js"></script></head><body>
+<script id="__NEXT_DATA__" type="application/json">{"props":{"pageProps":{}},"page":"/","buildId":"V1StGXR8_Z5jdHi6B-myT"}</script>
+</body></html>`
+	return io.NopCloser(strings.NewReader(page)), targetURL, nil
+}
+
+func (f *recordingFetcher) Capabilities() fetch.FetcherCapabilities {
+	return fetch.FetcherCapabilities{}
+}
+
+func TestJSONToolResult_EmbedsTypedJSON(t *testing.T) {
+	t.Parallel()
+
+	info := nextr4y.VersionInfo{IsNextJS: true, NextVersion: "14.1.0", ReactVersion: "18.2.0", Confidence: nextr4y.ConfidenceHigh}
+
+	result := jsonToolResult("summary", "versions", "https://example.com/?a=b", info)
+
+	require.False(t, result.IsError)
+	require.Len(t, result.Content, 2)
+	require.Equal(t, "summary", result.Content[0].(mcp.TextContent).Text)
+
+	resource := result.Content[1].(mcp.EmbeddedResource).Resource.(mcp.TextResourceContents)
+	require.Equal(t, "application/json", resource.MIMEType)
+	require.Equal(t, "nextr4y://versions?url=https%3A%2F%2Fexample.com%2F%3Fa%3Db", resource.URI)
+
+	var decoded nextr4y.VersionInfo
+	require.NoError(t, json.Unmarshal([]byte(resource.Text), &decoded))
+	require.Equal(t, info, decoded)
+}
+
+func TestHandleScanToolRequest_RejectsInvalidDepth(t *testing.T) {
+	t.Parallel()
+
+	var request mcp.CallToolRequest
+	request.Params.Arguments = map[string]interface{}{
+		"url":   "https://example.com",
+		"depth": "deep",
+	}
+
+	result, err := NewMCPServer("localhost", 0).handleScanToolRequest(context.Background(), request)
+
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+	require.Contains(t, result.Content[0].(mcp.TextContent).Text, `invalid 'depth' "deep"`)
+}
+
+func TestHandleVersionsToolRequest_RunsLightScan(t *testing.T) {
+	t.Parallel()
+
+	fetcher := &recordingFetcher{}
+	s := NewMCPServer("localhost", 0)
+	s.fetcher = fetcher
+
+	var request mcp.CallToolRequest
+	request.Params.Arguments = map[string]interface{}{"url": "https://example.com"}
+
+	result, err := s.handleVersionsToolRequest(context.Background(), request)
+
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	var info nextr4y.VersionInfo
+	resource := result.Content[1].(mcp.EmbeddedResource).Resource.(mcp.TextResourceContents)
+	require.NoError(t, json.Unmarshal([]byte(resource.Text), &info))
+	require.True(t, info.IsNextJS)
+
+	// The build manifest is never requested
+	require.NotEmpty(t, fetcher.urls)
+	for _, u := range fetcher.urls {
+		require.NotContains(t, u, "_buildManifest.js")
+	}
+}
+
+func TestHandleScanToolRequest_UsesScanOptions(t *testing.T) {
+	t.Parallel()
+
+	headers := make(chan http.Header, 1)
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case headers <- r.Header.Clone():
+		default:
+		}
+		w.Write([]byte(`<html><body><script id="__NEXT_DATA__" type="application/json">{"buildId":"b1","props":{}}</script></body></html>`))
+	}))
+	defer target.Close()
+
+	// No shared fetcher: each scan builds its own from the defaults
+	s := NewMCPServerWithOptions("localhost", 0, Options{ScanOptions: nextr4y.Options{
+		Headers:      map[string]string{"X-Team": "red"},
+		SkipVersions: true,
+	}})
+
+	var request mcp.CallToolRequest
+	request.Params.Arguments = map[string]interface{}{"url": target.URL, "depth": scanDepthLight}
+	result, err := s.handleScanToolRequest(context.Background(), request)
+
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	require.Equal(t, "red", (<-headers).Get("X-Team"))
+	require.True(t, s.scanOptions("", false).SkipVersions)
+	require.False(t, s.options.ScanOptions.SkipRoutes, "requests don't change the defaults")
+}
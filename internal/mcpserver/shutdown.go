@@ -0,0 +1,95 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ErrServerShuttingDown is returned for scans requested once a graceful
+// shutdown has started.
+var ErrServerShuttingDown = errors.New("server shutting down: not accepting new scans")
+
+// beginScan registers a scan so a graceful shutdown waits for it. It returns
+// false once the shutdown has started; otherwise endScan must be called when
+// the scan is done.
+func (s *MCPServer) beginScan() bool {
+	s.scansMu.Lock()
+	defer s.scansMu.Unlock()
+	if s.shuttingDown {
+		return false
+	}
+	s.activeScans.Add(1)
+	return true
+}
+
+// endScan marks a scan registered with beginScan as done.
+func (s *MCPServer) endScan() {
+	s.activeScans.Done()
+}
+
+// waitForScans stops new scans from being registered and waits for the
+// registered ones to finish, or until ctx is done.
+func (s *MCPServer) waitForScans(ctx context.Context) error {
+	s.scansMu.Lock()
+	s.shuttingDown = true
+	s.scansMu.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		s.activeScans.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// serveUntilSignal runs srv until it fails or the process receives SIGINT or
+// SIGTERM, in which case the server is shut down gracefully: new scans are
+// rejected, in-flight scans get up to ShutdownTimeout to finish, then the SSE
+// sessions and the listener are closed. A second signal exits immediately.
+func (s *MCPServer) serveUntilSignal(srv *http.Server, sseServer *server.SSEServer) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+	stop() // Restore the default behavior, so a second signal kills the process
+
+	slog.Info("Shutdown signal received, rejecting new scans and waiting for in-flight scans", "timeout", s.options.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.options.ShutdownTimeout)
+	defer cancel()
+
+	if err := s.waitForScans(shutdownCtx); err != nil {
+		slog.Warn("Shutdown timeout reached with scans still running, stopping anyway")
+	} else {
+		slog.Info("All in-flight scans finished")
+	}
+
+	slog.Info("Closing client sessions and the listener")
+	if err := sseServer.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down MCP server: %w", err)
+	}
+	slog.Info("MCP server stopped")
+	return nil
+}
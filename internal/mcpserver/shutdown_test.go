@@ -0,0 +1,30 @@
+package mcpserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigopv/nextr4y"
+)
+
+func TestWaitForScans_DrainsInFlightScans(t *testing.T) {
+	t.Parallel()
+
+	s := NewMCPServer("localhost", 0)
+	require.True(t, s.beginScan())
+
+	// The scan is still running: the wait times out, and new scans are rejected
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, s.waitForScans(ctx), context.DeadlineExceeded)
+	require.False(t, s.beginScan())
+
+	_, err := s.runScan(context.Background(), "https://example.com", nextr4y.Options{})
+	require.ErrorIs(t, err, ErrServerShuttingDown)
+
+	s.endScan()
+	require.NoError(t, s.waitForScans(context.Background()))
+}
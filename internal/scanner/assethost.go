@@ -0,0 +1,92 @@
+package scanner
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/rodrigopv/nextr4y/internal/fetch"
+)
+
+// NormalizeAssetHost validates an asset host override, a host name with an
+// optional port ("www.example.com", "static.example.com:8443"), and returns
+// it lowercased.
+func NormalizeAssetHost(rawHost string) (string, error) {
+	host := strings.TrimSpace(rawHost)
+	u, err := url.Parse("//" + host)
+	if err != nil {
+		return "", fmt.Errorf("invalid asset host '%s': %w", rawHost, err)
+	}
+	if u.Host == "" || u.Host != host || u.User != nil {
+		return "", fmt.Errorf("invalid asset host '%s': must be a host name with an optional port, without scheme nor path", rawHost)
+	}
+	return strings.ToLower(host), nil
+}
+
+// assetHostFetcher wraps the Fetcher used for the build manifest and the
+// assets when they are served from another host than the page (e.g. a
+// "static.example.com" asset prefix). It counts the requests to that host and
+// their failures, so a host blocking the scanner can be told apart from chunks
+// that simply don't contain a version, and fetches from override instead
+// when set. It is safe for concurrent use.
+type assetHostFetcher struct {
+	fetch.Fetcher
+	assetHost string
+	override  string // Host requested instead of assetHost, may be ""
+
+	mu       sync.Mutex
+	attempts int
+	failures int
+}
+
+// Fetch implements the Fetcher interface. URLs on other hosts than the asset
+// host are fetched unchanged and not counted.
+func (f *assetHostFetcher) Fetch(targetURL string) (io.ReadCloser, string, error) {
+	u, err := url.Parse(targetURL)
+	if err != nil || !strings.EqualFold(u.Host, f.assetHost) {
+		return f.Fetcher.Fetch(targetURL)
+	}
+
+	reader, finalURL, err := f.Fetcher.Fetch(f.requestURL(targetURL))
+	f.mu.Lock()
+	f.attempts++
+	if err != nil {
+		f.failures++
+	}
+	f.mu.Unlock()
+	return reader, finalURL, err
+}
+
+// requestURL returns the URL actually requested for targetURL: on the
+// override host when targetURL is on the asset host and an override is set,
+// targetURL itself otherwise. It may be called on a nil assetHostFetcher.
+func (f *assetHostFetcher) requestURL(targetURL string) string {
+	if f == nil || f.override == "" {
+		return targetURL
+	}
+	u, err := url.Parse(targetURL)
+	if err != nil || !strings.EqualFold(u.Host, f.assetHost) {
+		return targetURL
+	}
+	u.Host = f.override
+	return u.String()
+}
+
+// blocked reports whether the asset host was requested and every request
+// failed, along with the number of requests.
+func (f *assetHostFetcher) blocked() (bool, int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.attempts > 0 && f.failures == f.attempts, f.attempts
+}
+
+// formatAssetHost renders the asset host for text output, e.g.
+// "static.example.com (blocked: every request failed)".
+func formatAssetHost(result *ScanResult) string {
+	if result.AssetHostBlocked {
+		return result.AssetHost + " (blocked: every request failed)"
+	}
+	return result.AssetHost
+}
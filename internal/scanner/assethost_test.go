@@ -0,0 +1,63 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigopv/nextr4y/internal/versiondetect"
+)
+
+func TestScanTarget_AssetHost(t *testing.T) {
+	t.Parallel()
+
+	const target = "https://www.example.com/"
+	pages := map[string]string{
+		target: `<html><body><script src="https://static.example.com/_next/static/chunks/main-1.js"></script>` +
+			`<script id="__NEXT_DATA__" type="application/json">{"buildId":"b1","assetPrefix":"https://static.example.com","props":{}}</script></body></html>`,
+		target + "_next/static/b1/_buildManifest.js": sampleManifestJS,
+		target + "_next/static/chunks/main-1.js":     `console.log("main")`,
+	}
+
+	testCases := []struct {
+		name         string
+		override     string
+		wantBlocked  bool
+		wantManifest bool
+	}{
+		{name: "blocked", wantBlocked: true},
+		{name: "override", override: "WWW.example.com", wantManifest: true},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			scr := NewScannerWithOptions(&stubFetcher{pages: pages}, &versiondetect.HeuristicAssetScannerDetector{}, Options{AssetHostOverride: tc.override})
+
+			result, _ := scr.ScanTarget(target)
+
+			require.NotNil(t, result)
+			require.Equal(t, "static.example.com", result.AssetHost)
+			require.Equal(t, tc.wantBlocked, result.AssetHostBlocked)
+			require.Equal(t, tc.wantManifest, result.ManifestFound)
+		})
+	}
+}
+
+func TestNormalizeAssetHost(t *testing.T) {
+	t.Parallel()
+
+	for raw, want := range map[string]string{
+		"www.example.com":          "www.example.com",
+		" Static.Example.com:8443": "static.example.com:8443",
+	} {
+		host, err := NormalizeAssetHost(raw)
+		require.NoError(t, err, raw)
+		require.Equal(t, want, host)
+	}
+	for _, raw := range []string{"", "https://www.example.com", "www.example.com/assets", "user@www.example.com"} {
+		_, err := NormalizeAssetHost(raw)
+		require.Error(t, err, raw)
+	}
+}
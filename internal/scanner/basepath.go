@@ -0,0 +1,50 @@
+package scanner
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// normalizeBasePath returns basePath ("docs", "/docs/") as a path starting
+// with a slash and without a trailing one ("/docs"), or "" for the root.
+func normalizeBasePath(basePath string) string {
+	trimmed := strings.Trim(strings.TrimSpace(basePath), "/")
+	if trimmed == "" {
+		return ""
+	}
+	return path.Clean("/" + trimmed)
+}
+
+// inferBasePath returns the basePath of an app served under a subpath
+// ("/docs"), read from the page scripts served from the page's origin under
+// "<basePath>/_next/static/". It returns "" when the chunks are served from
+// the root or from another host. Only meaningful without an asset prefix,
+// which also moves the chunks.
+func inferBasePath(htmlContent string, pageURL *url.URL) string {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent))
+	if err != nil {
+		return ""
+	}
+
+	basePath := ""
+	doc.Find("script[src]").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		src, _ := s.Attr("src")
+		srcURL, err := url.Parse(src)
+		if err != nil {
+			return true
+		}
+		resolved := pageURL.ResolveReference(srcURL)
+		if !strings.EqualFold(resolved.Host, pageURL.Host) {
+			return true
+		}
+		if i := strings.Index(resolved.Path, "/_next/static/"); i >= 0 {
+			basePath = normalizeBasePath(resolved.Path[:i])
+			return false
+		}
+		return true
+	})
+	return basePath
+}
@@ -0,0 +1,40 @@
+package scanner
+
+import (
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanTarget_BasePath(t *testing.T) {
+	t.Parallel()
+
+	basePathHTML, err := os.ReadFile("testdata/base_path.html")
+	require.NoError(t, err)
+
+	const target = "https://www.example.com/docs/guide/getting-started"
+	const manifestURL = "https://www.example.com/docs/_next/static/dOcSbUiLd42/_buildManifest.js"
+	withNextDataBasePath := strings.Replace(string(basePathHTML), `"buildId":`, `"basePath":"/docs/","buildId":`, 1)
+
+	for name, page := range map[string]string{"inferred from scripts": string(basePathHTML), "from __NEXT_DATA__": withNextDataBasePath} {
+		fetcher := &stubFetcher{pages: map[string]string{target: page, manifestURL: sampleManifestJS}}
+		scr := NewScannerWithOptions(fetcher, stubDetector{}, Options{})
+
+		result, err := scr.ScanTarget(target)
+
+		require.NoError(t, err, name)
+		require.Equal(t, "/docs", result.BasePath, name)
+		require.Equal(t, "https://www.example.com/docs/", result.AssetBaseURL, name)
+		require.True(t, result.ManifestExecOK, name)
+		require.Contains(t, result.AllAssets, "https://www.example.com/docs/_next/static/chunks/pages/index-abc.js", name)
+	}
+
+	// Chunks served from the root don't make a base path
+	rootHTML := strings.ReplaceAll(string(basePathHTML), `"/docs/_next/`, `"/_next/`)
+	pageURL, err := url.Parse(target)
+	require.NoError(t, err)
+	require.Empty(t, inferBasePath(rootHTML, pageURL))
+}
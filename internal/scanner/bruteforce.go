@@ -0,0 +1,150 @@
+package scanner
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rodrigopv/nextr4y/internal/fetch"
+)
+
+//go:embed wordlists/nextjs.txt
+var defaultWordlist string
+
+// DefaultBruteforceConcurrency is the number of paths probed in parallel by Bruteforce.
+const DefaultBruteforceConcurrency = 8
+
+// DiscoveredRoute is a wordlist path the target answered with a 200 or a redirect.
+type DiscoveredRoute struct {
+	Path       string // Probed path (e.g. "/api/health")
+	StatusCode int    // Status of the final response (3xx when redirects are not followed)
+	RedirectTo string // URL the path redirects to, empty if it was answered directly
+}
+
+// ParseWordlist reads one path per line, skipping blank lines and "#"
+// comments. Paths are made absolute ("api/x" becomes "/api/x").
+func ParseWordlist(r io.Reader) ([]string, error) {
+	var paths []string
+	seen := make(map[string]bool)
+	lines := bufio.NewScanner(r)
+	for lines.Scan() {
+		line := strings.TrimSpace(lines.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !strings.HasPrefix(line, "/") {
+			line = "/" + line
+		}
+		if !seen[line] {
+			seen[line] = true
+			paths = append(paths, line)
+		}
+	}
+	return paths, lines.Err()
+}
+
+// DefaultWordlist returns the embedded list of common Next.js paths.
+func DefaultWordlist() []string {
+	paths, _ := ParseWordlist(strings.NewReader(defaultWordlist))
+	return paths
+}
+
+// probePath requests a single path and reports how the target answered it.
+// ok is false for errors and any status other than 200 or a redirect.
+func probePath(fetcher fetch.Fetcher, origin *url.URL, routePath string) (route DiscoveredRoute, ok bool) {
+	probeURL := origin.ResolveReference(&url.URL{Path: routePath}).String()
+	reader, info, err := fetch.FetchWithInfo(fetcher, probeURL)
+	if err == nil {
+		reader.Close()
+	}
+
+	route = DiscoveredRoute{Path: routePath, StatusCode: info.StatusCode}
+	switch {
+	case len(info.RedirectChain) > 1:
+		// Followed: the first hop is where the path itself points to
+		route.RedirectTo = info.RedirectChain[1]
+		return route, true
+	case info.StatusCode >= 300 && info.StatusCode < 400:
+		// Not followed (redirects disabled)
+		if info.Headers != nil {
+			route.RedirectTo = info.Headers.Get("Location")
+		}
+		return route, true
+	case err == nil:
+		route.StatusCode = http.StatusOK // Not every fetcher reports the status
+		return route, true
+	}
+	return route, false
+}
+
+// bruteforceRoutes probes every path of the wordlist on the target origin, at
+// most concurrency at once, and returns those answered with a 200 or a
+// redirect, sorted by path. A random path is probed first: if the target
+// answers it the same way (e.g. a catch-all page or a login redirect), the
+// matching answers are dropped as false positives.
+func bruteforceRoutes(fetcher fetch.Fetcher, baseURL *url.URL, paths []string, concurrency int, progress ProgressFunc) []DiscoveredRoute {
+	if concurrency <= 0 {
+		concurrency = DefaultBruteforceConcurrency
+	}
+	origin := &url.URL{Scheme: baseURL.Scheme, Host: baseURL.Host}
+
+	baselinePath := fmt.Sprintf("/nextr4y-%d", time.Now().UnixNano())
+	baseline, catchAll := probePath(fetcher, origin, baselinePath)
+	if catchAll {
+		log.Printf("Bruteforce: the target answers unknown paths too (%s), ignoring identical answers", formatDiscoveredRoute(baseline))
+	}
+
+	var routes []DiscoveredRoute
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	done := 0
+	sem := make(chan struct{}, concurrency)
+
+	for _, routePath := range paths {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(routePath string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			route, ok := probePath(fetcher, origin, routePath)
+
+			mu.Lock()
+			defer mu.Unlock()
+			done++
+			if progress != nil {
+				progress("Probing path", done, len(paths))
+			}
+			if !ok || (catchAll && route.StatusCode == baseline.StatusCode && route.RedirectTo == baseline.RedirectTo) {
+				return
+			}
+			log.Printf("Bruteforce: found %s (%s)", routePath, formatDiscoveredRoute(route))
+			routes = append(routes, route)
+		}(routePath)
+	}
+	wg.Wait()
+
+	sort.Slice(routes, func(i, j int) bool { return routes[i].Path < routes[j].Path })
+	return routes
+}
+
+// formatDiscoveredRoute describes how a discovered route answered, e.g.
+// "200" or "redirect to https://example.com/login".
+func formatDiscoveredRoute(route DiscoveredRoute) string {
+	switch {
+	case route.RedirectTo != "":
+		return "redirect to " + route.RedirectTo
+	case route.StatusCode >= 300:
+		return fmt.Sprintf("%d redirect", route.StatusCode)
+	default:
+		return fmt.Sprintf("%d", route.StatusCode)
+	}
+}
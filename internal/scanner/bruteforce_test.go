@@ -0,0 +1,104 @@
+package scanner
+
+import (
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigopv/nextr4y/internal/fetch"
+)
+
+// redirectingFetcher serves pages like stubFetcher, following the redirects
+// listed in redirects (source URL to location) first. With catchAll set,
+// every unknown URL redirects to it.
+type redirectingFetcher struct {
+	stubFetcher
+	redirects map[string]string
+	catchAll  string
+}
+
+func (f *redirectingFetcher) Fetch(targetURL string) (io.ReadCloser, string, error) {
+	body, info, err := f.FetchWithInfo(targetURL)
+	return body, info.FinalURL, err
+}
+
+func (f *redirectingFetcher) FetchWithInfo(targetURL string) (io.ReadCloser, *fetch.ResponseInfo, error) {
+	info := &fetch.ResponseInfo{RedirectChain: []string{targetURL}}
+	location, ok := f.redirects[targetURL]
+	if _, isPage := f.pages[targetURL]; !ok && !isPage && f.catchAll != "" && targetURL != f.catchAll {
+		location, ok = f.catchAll, true
+	}
+	if ok {
+		info.RedirectChain = append(info.RedirectChain, location)
+		targetURL = location
+	}
+	body, finalURL, err := f.stubFetcher.Fetch(targetURL)
+	info.FinalURL = finalURL
+	info.StatusCode = 200
+	if err != nil {
+		info.StatusCode = 404
+	}
+	return body, info, err
+}
+
+func TestBruteforceRoutes(t *testing.T) {
+	t.Parallel()
+
+	baseURL, err := url.Parse("https://example.com/app/")
+	require.NoError(t, err)
+	wordlist := []string{"/api/health", "/api/auth/session", "/api/revalidate", "/api/hello"}
+
+	t.Run("reports 200s and redirects", func(t *testing.T) {
+		t.Parallel()
+
+		fetcher := &redirectingFetcher{
+			stubFetcher: stubFetcher{pages: map[string]string{
+				"https://example.com/api/health": `{"status":"ok"}`,
+				"https://example.com/login":      "<html></html>",
+			}},
+			redirects: map[string]string{
+				"https://example.com/api/auth/session": "https://example.com/login",
+				"https://example.com/api/revalidate":   "https://example.com/gone",
+			},
+		}
+
+		routes := bruteforceRoutes(fetcher, baseURL, wordlist, 2, nil)
+
+		require.Equal(t, []DiscoveredRoute{
+			{Path: "/api/auth/session", StatusCode: 200, RedirectTo: "https://example.com/login"},
+			{Path: "/api/health", StatusCode: 200},
+			{Path: "/api/revalidate", StatusCode: 404, RedirectTo: "https://example.com/gone"},
+		}, routes)
+	})
+
+	t.Run("ignores answers shared with unknown paths", func(t *testing.T) {
+		t.Parallel()
+
+		fetcher := &redirectingFetcher{
+			stubFetcher: stubFetcher{pages: map[string]string{
+				"https://example.com/api/health": `{"status":"ok"}`,
+				"https://example.com/login":      "<html></html>",
+			}},
+			catchAll: "https://example.com/login",
+		}
+
+		routes := bruteforceRoutes(fetcher, baseURL, wordlist, 2, nil)
+
+		require.Equal(t, []DiscoveredRoute{{Path: "/api/health", StatusCode: 200}}, routes)
+	})
+}
+
+func TestParseWordlist(t *testing.T) {
+	t.Parallel()
+
+	paths, err := ParseWordlist(strings.NewReader("# comment\n\n/api/health\napi/status\n  /api/health  \n"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"/api/health", "/api/status"}, paths)
+
+	defaults := DefaultWordlist()
+	require.Contains(t, defaults, "/api/auth/session")
+	require.NotContains(t, defaults, "")
+}
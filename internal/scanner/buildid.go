@@ -0,0 +1,95 @@
+package scanner
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Build ID formats reported in ScanResult.BuildIDType. The classification is
+// a heuristic based on the shape of the ID only.
+const (
+	BuildIDTypeSHA       = "sha"       // Hex digest, usually the git commit (generateBuildId returning the SHA)
+	BuildIDTypeNanoID    = "nanoid"    // 21-character nanoid, the Next.js default
+	BuildIDTypeTimestamp = "timestamp" // Unix epoch or compact date-time
+	BuildIDTypeCustom    = "custom"    // Anything else
+)
+
+var (
+	buildIDSHARegex    = regexp.MustCompile(`^[0-9a-f]{7,64}$`)
+	buildIDNanoIDRegex = regexp.MustCompile(`^[A-Za-z0-9_-]{21}$`)
+	buildIDDigitsRegex = regexp.MustCompile(`^\d+$`)
+)
+
+// buildIDDateLayouts are the compact date-time formats recognized in build IDs.
+var buildIDDateLayouts = []string{"20060102150405", "200601021504", "20060102"}
+
+// Build dates outside this range are treated as coincidences, not timestamps.
+var minBuildIDDate = time.Date(2016, time.October, 1, 0, 0, 0, 0, time.UTC) // Next.js 1.0
+
+// buildIDClockSkew is how far in the future a build date may be.
+const buildIDClockSkew = 24 * time.Hour
+
+// classifyBuildID guesses how a build ID was generated from its format. For
+// timestamp IDs it also returns the approximate build date, in RFC 3339;
+// otherwise buildDate is empty.
+func classifyBuildID(buildID string) (idType string, buildDate string) {
+	if buildIDDigitsRegex.MatchString(buildID) {
+		if date, ok := parseBuildIDTimestamp(buildID); ok {
+			return BuildIDTypeTimestamp, date.Format(time.RFC3339)
+		}
+		return BuildIDTypeCustom, "" // A plain number, not a hex digest
+	}
+	switch {
+	case buildIDSHARegex.MatchString(buildID):
+		return BuildIDTypeSHA, ""
+	case buildIDNanoIDRegex.MatchString(buildID):
+		return BuildIDTypeNanoID, ""
+	default:
+		return BuildIDTypeCustom, ""
+	}
+}
+
+// parseBuildIDTimestamp reads an all-digit build ID as a Unix time in
+// seconds or milliseconds, or as a compact UTC date-time, and reports
+// whether it falls in a plausible range.
+func parseBuildIDTimestamp(digits string) (time.Time, bool) {
+	var candidates []time.Time
+	if n, err := strconv.ParseInt(digits, 10, 64); err == nil {
+		switch len(digits) {
+		case 10:
+			candidates = append(candidates, time.Unix(n, 0).UTC())
+		case 13:
+			candidates = append(candidates, time.UnixMilli(n).UTC())
+		}
+	}
+	for _, layout := range buildIDDateLayouts {
+		if len(digits) == len(layout) {
+			if t, err := time.Parse(layout, digits); err == nil {
+				candidates = append(candidates, t)
+			}
+		}
+	}
+
+	latest := time.Now().Add(buildIDClockSkew)
+	for _, t := range candidates {
+		if !t.Before(minBuildIDDate) && t.Before(latest) {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// formatBuildID renders the build ID with its inferred format for text
+// output, e.g. "1700000000 (timestamp, built ~2023-11-14)".
+func formatBuildID(result *ScanResult) string {
+	switch {
+	case result.BuildIDType == "":
+		return result.BuildID
+	case result.BuildIDDate != "":
+		return fmt.Sprintf("%s (%s, built ~%s)", result.BuildID, result.BuildIDType, result.BuildIDDate[:len("2006-01-02")])
+	default:
+		return fmt.Sprintf("%s (%s)", result.BuildID, result.BuildIDType)
+	}
+}
@@ -0,0 +1,34 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyBuildID(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		buildID  string
+		wantType string
+		wantDate string
+	}{
+		{buildID: "3f9a1c2", wantType: BuildIDTypeSHA},
+		{buildID: "e83c6ea2b0f94d0a8ad1b6d1d1ab6a3a9b2f7c41", wantType: BuildIDTypeSHA},
+		{buildID: "V1StGXR8_Z5jdHi6B-myT", wantType: BuildIDTypeNanoID},
+		{buildID: "1700000000", wantType: BuildIDTypeTimestamp, wantDate: "2023-11-14T22:13:20Z"},
+		{buildID: "1700000000123", wantType: BuildIDTypeTimestamp, wantDate: "2023-11-14T22:13:20Z"},
+		{buildID: "20240115123045", wantType: BuildIDTypeTimestamp, wantDate: "2024-01-15T12:30:45Z"},
+		{buildID: "1234", wantType: BuildIDTypeCustom},          // Too short for a date
+		{buildID: "9999999999999", wantType: BuildIDTypeCustom}, // Far in the future
+		{buildID: "production-42", wantType: BuildIDTypeCustom},
+		{buildID: "3F9A1C2", wantType: BuildIDTypeCustom}, // Digests are lowercase
+	}
+
+	for _, tc := range testCases {
+		idType, date := classifyBuildID(tc.buildID)
+		require.Equal(t, tc.wantType, idType, tc.buildID)
+		require.Equal(t, tc.wantDate, date, tc.buildID)
+	}
+}
@@ -0,0 +1,69 @@
+package scanner
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// jsSizeCollector records the size of every distinct JS chunk fetched during
+// the scan, reusing the downloads of version detection and hashing. It is
+// safe for concurrent use.
+type jsSizeCollector struct {
+	mu    sync.Mutex
+	sizes map[string]int64
+}
+
+// newJSSizeCollector creates an empty jsSizeCollector.
+func newJSSizeCollector() *jsSizeCollector {
+	return &jsSizeCollector{sizes: make(map[string]int64)}
+}
+
+// inspect implements assetInspector. Non-JS assets (CSS, fonts) are ignored.
+func (c *jsSizeCollector) inspect(assetURL string, body []byte) {
+	u, err := url.Parse(assetURL)
+	if err != nil || !strings.HasSuffix(u.Path, ".js") {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sizes[assetURL] = int64(len(body))
+}
+
+// Totals returns the number of distinct JS chunks seen and their total size in bytes.
+func (c *jsSizeCollector) Totals() (chunks int, totalBytes int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, size := range c.sizes {
+		totalBytes += size
+	}
+	return len(c.sizes), totalBytes
+}
+
+// formatByteSize renders a size in bytes with decimal units, as Next.js'
+// build output does: "512 B", "48.3 kB", "1.2 MB".
+func formatByteSize(size int64) string {
+	const unit = 1000
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	value := float64(size) / unit
+	for _, suffix := range []string{"kB", "MB"} {
+		if value < unit {
+			return fmt.Sprintf("%.1f %s", value, suffix)
+		}
+		value /= unit
+	}
+	return fmt.Sprintf("%.1f GB", value)
+}
+
+// formatJSBundle summarizes the JS chunks fetched during a scan, e.g.
+// "42 chunks, 1.2 MB".
+func formatJSBundle(result *ScanResult) string {
+	noun := "chunks"
+	if result.ChunkCount == 1 {
+		noun = "chunk"
+	}
+	return fmt.Sprintf("%d %s, %s", result.ChunkCount, noun, formatByteSize(result.TotalJSBytes))
+}
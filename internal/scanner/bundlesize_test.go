@@ -0,0 +1,42 @@
+package scanner
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigopv/nextr4y/internal/versiondetect"
+)
+
+func TestScanTarget_JSBundleSize(t *testing.T) {
+	t.Parallel()
+
+	const target = "https://www.example.com/"
+	fetcher := &stubFetcher{pages: map[string]string{
+		target: `<html><head><link rel="stylesheet" href="/_next/static/css/app.css"></head><body>` +
+			`<script src="/_next/static/chunks/main-1.js"></script><script src="/_next/static/chunks/pages/_app-2.js?v=3"></script>` +
+			`<script id="__NEXT_DATA__" type="application/json">{"buildId":"b1","props":{}}</script></body></html>`,
+		target + "_next/static/chunks/main-1.js":           strings.Repeat("a", 1500),
+		target + "_next/static/chunks/pages/_app-2.js?v=3": strings.Repeat("b", 500),
+		target + "_next/static/css/app.css":                strings.Repeat("c", 800),
+	}}
+	scr := NewScannerWithOptions(fetcher, &versiondetect.HeuristicAssetScannerDetector{}, Options{})
+
+	result, err := scr.ScanTarget(target)
+
+	require.Error(t, err) // The build manifest is missing
+	require.Equal(t, 2, result.ChunkCount)
+	require.Equal(t, int64(2000), result.TotalJSBytes)
+	require.Equal(t, "2 chunks, 2.0 kB", formatJSBundle(result))
+}
+
+func TestFormatByteSize(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "0 B", formatByteSize(0))
+	require.Equal(t, "999 B", formatByteSize(999))
+	require.Equal(t, "48.3 kB", formatByteSize(48_300))
+	require.Equal(t, "1.2 MB", formatByteSize(1_234_567))
+	require.Equal(t, "3.0 GB", formatByteSize(3_000_000_000))
+}
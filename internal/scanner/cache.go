@@ -0,0 +1,187 @@
+package scanner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultCacheTTL is how long a cached result is reused by default.
+const DefaultCacheTTL = 24 * time.Hour
+
+// ResultCache stores complete scan results by host, build ID and scan
+// options. Since the routes and versions of a deployment don't change while
+// its build ID stays the same, a scan finding a cached build ID returns the
+// cached result instead of fetching the build manifest and the assets again.
+// The options identify the scanner settings changing what a scan reports,
+// so a scan never reuses the result of a scan that skipped or added steps
+// (e.g. --no-versions or --hash-assets).
+type ResultCache interface {
+	// Get returns the result cached for the build ID of host scanned with
+	// options, if any.
+	Get(host, buildID, options string) (*ScanResult, bool)
+
+	// Put stores the result of a scan of host with options.
+	Put(host, options string, result *ScanResult) error
+}
+
+// DirCache is a ResultCache keeping the last result of each host and set of
+// scan options in a JSON file of a directory, so it is shared between runs
+// of the CLI.
+type DirCache struct {
+	dir string
+	ttl time.Duration
+}
+
+// NewDirCache creates a DirCache storing its files in dir, created when the
+// first result is stored. Results older than ttl are ignored; zero uses
+// DefaultCacheTTL and a negative ttl never expires them.
+func NewDirCache(dir string, ttl time.Duration) *DirCache {
+	if ttl == 0 {
+		ttl = DefaultCacheTTL
+	}
+	return &DirCache{dir: dir, ttl: ttl}
+}
+
+// cacheEntry is the content of a cache file.
+type cacheEntry struct {
+	Host     string
+	Options  string
+	BuildID  string
+	StoredAt time.Time
+	Result   json.RawMessage
+}
+
+// path returns the cache file of host and options.
+func (c *DirCache) path(host, options string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(host) + "\x00" + options))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:16])+".json")
+}
+
+// Get implements ResultCache. Unreadable files are treated as misses.
+func (c *DirCache) Get(host, buildID, options string) (*ScanResult, bool) {
+	data, err := os.ReadFile(c.path(host, options))
+	if err != nil {
+		return nil, false
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+	if !strings.EqualFold(entry.Host, host) || entry.Options != options || entry.BuildID != buildID {
+		return nil, false
+	}
+	if c.ttl > 0 && time.Since(entry.StoredAt) > c.ttl {
+		return nil, false
+	}
+
+	// Only results without an ExecutionError are stored, so none is lost
+	result, err := DecodeResult(entry.Result)
+	if err != nil {
+		return nil, false
+	}
+	return result, true
+}
+
+// Put implements ResultCache, replacing the previous result of host scanned
+// with options.
+func (c *DirCache) Put(host, options string, result *ScanResult) error {
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("cache: failed to marshal result: %w", err)
+	}
+	data, err := json.Marshal(cacheEntry{Host: host, Options: options, BuildID: result.BuildID, StoredAt: time.Now(), Result: resultJSON})
+	if err != nil {
+		return fmt.Errorf("cache: failed to marshal entry: %w", err)
+	}
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return fmt.Errorf("cache: failed to create '%s': %w", c.dir, err)
+	}
+
+	// Written to a temporary file first, so concurrent scans never read a
+	// partial entry
+	tmp, err := os.CreateTemp(c.dir, ".entry-*")
+	if err != nil {
+		return fmt.Errorf("cache: failed to create entry: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("cache: failed to write entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("cache: failed to write entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path(host, options)); err != nil {
+		return fmt.Errorf("cache: failed to store entry: %w", err)
+	}
+	return nil
+}
+
+// cacheOptions returns the scanner settings changing what a scan reports
+// beyond its page, as a key of the results cached by ResultCache. Settings
+// only changing how the scan runs, such as concurrency, are left out.
+func (s *Scanner) cacheOptions() string {
+	var pattern string
+	if s.assetPattern != nil {
+		pattern = s.assetPattern.String()
+	}
+	wordlist := sha256.Sum256([]byte(strings.Join(s.wordlist, "\n")))
+	return fmt.Sprintf("base=%s asset-host=%s render=%t scheme=%s hash=%t discover=%t metadata=%t raw=%t endpoints=%t keys=%t instrumentation=%t asset-pattern=%q data=%t/%d image=%t preview=%t bruteforce=%t/%x no-versions=%t no-routes=%t errors=%t debug=%t",
+		s.customBaseURL, s.assetHostOverride, s.renderFetcher != nil, s.scheme, s.hashAssets, s.discover, s.metadata,
+		s.includeRaw, s.extractEndpoints, s.extractKeys, s.detectInstr, pattern, s.probeData, s.maxDataProbes,
+		s.probeImage, s.probePreview, s.bruteforce, wordlist[:8], s.skipVersions, s.skipRoutes, s.includeErrors, s.debug)
+}
+
+// reuseCachedResult copies into result, the result of a scan of the page
+// just fetched, the fields of cached derived from the build: the manifest,
+// routes, assets, versions and the optional phases that fetch them. Every
+// field read from the page and its headers is left as found by the new
+// fetch, since they change between pages (e.g. locales) and between
+// requests (headers) of the same build.
+func reuseCachedResult(result, cached *ScanResult) {
+	result.AssetHostBlocked = cached.AssetHostBlocked
+	result.AssetHosts = cached.AssetHosts
+	result.Routes = cached.Routes
+	result.SpecialPages = cached.SpecialPages
+	result.RouteDetails = cached.RouteDetails
+	result.AllAssets = cached.AllAssets
+	result.ManifestFound = cached.ManifestFound
+	result.ManifestExecOK = cached.ManifestExecOK
+	result.BuildManifestRaw = cached.BuildManifestRaw
+	result.DetectedNextVersion = cached.DetectedNextVersion
+	result.NextVersionFallback = cached.NextVersionFallback
+	result.DetectedReactVersion = cached.DetectedReactVersion
+	result.ReactReconcilerVersion = cached.ReactReconcilerVersion
+	result.VersionCandidates = cached.VersionCandidates
+	result.WebpackVersion = cached.WebpackVersion
+	result.ChunkCount = cached.ChunkCount
+	result.TotalJSBytes = cached.TotalJSBytes
+	result.StylingLibraries = cached.StylingLibraries
+	result.AssetHashes = cached.AssetHashes
+	result.AssetHashErrors = cached.AssetHashErrors
+	result.AssetErrors = cached.AssetErrors
+	result.DiscoveredEndpoints = cached.DiscoveredEndpoints
+	result.EmbeddedKeys = cached.EmbeddedKeys
+	result.Instrumentation = cached.Instrumentation
+	result.InstrumentationSignals = cached.InstrumentationSignals
+	result.TelemetryEndpoints = cached.TelemetryEndpoints
+	result.RobotsDisallow = cached.RobotsDisallow
+	result.SitemapURLs = cached.SitemapURLs
+	result.ResolvedRoutes = cached.ResolvedRoutes
+	result.DataRoutes = cached.DataRoutes
+	result.ImageOptimizer = cached.ImageOptimizer
+	result.ImageOptimizerExternal = cached.ImageOptimizerExternal
+	result.PreviewModeEnabled = cached.PreviewModeEnabled
+	result.PreviewEndpoints = cached.PreviewEndpoints
+	result.FaviconFound = cached.FaviconFound
+	result.WebManifestURL = cached.WebManifestURL
+	result.AppName = cached.AppName
+	result.ThemeColor = cached.ThemeColor
+	result.DiscoveredRoutes = cached.DiscoveredRoutes
+}
@@ -0,0 +1,117 @@
+package scanner
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanTarget_Cache(t *testing.T) {
+	t.Parallel()
+
+	const target = "https://www.example.com/"
+	page := func(buildID string) string {
+		return `<html><body><script id="__NEXT_DATA__" type="application/json">{"buildId":"` + buildID + `","props":{}}</script></body></html>`
+	}
+	cache := NewDirCache(t.TempDir(), 0)
+
+	first, err := NewScannerWithOptions(&stubFetcher{pages: map[string]string{
+		target: page("b1"),
+		target + "_next/static/b1/_buildManifest.js": sampleManifestJS,
+	}}, stubDetector{}, Options{Cache: cache}).ScanTarget(target)
+	require.NoError(t, err)
+	require.False(t, first.FromCache)
+	require.NotEmpty(t, first.Routes)
+
+	// Same build ID: the manifest is not fetched again
+	pageOnly := &stubFetcher{pages: map[string]string{target: page("b1")}}
+	cached, err := NewScannerWithOptions(pageOnly, stubDetector{}, Options{Cache: cache}).ScanTarget(target)
+	require.NoError(t, err)
+	require.True(t, cached.FromCache)
+	require.Equal(t, SchemaVersion, cached.SchemaVersion)
+	require.Equal(t, first.Routes, cached.Routes)
+	require.Equal(t, 1, cached.FetchCount)
+	require.Contains(t, formatTiming(cached), "rest of the result from cache")
+
+	// A reduced scan neither reuses the full result nor replaces it
+	reduced, err := NewScannerWithOptions(&stubFetcher{pages: map[string]string{
+		target: page("b1"),
+		target + "_next/static/b1/_buildManifest.js": sampleManifestJS,
+	}}, stubDetector{}, Options{Cache: cache, SkipVersions: true}).ScanTarget(target)
+	require.NoError(t, err)
+	require.False(t, reduced.FromCache)
+	require.Equal(t, VersionSkipped, reduced.DetectedNextVersion)
+	cached, err = NewScannerWithOptions(pageOnly, stubDetector{}, Options{Cache: cache}).ScanTarget(target)
+	require.NoError(t, err)
+	require.True(t, cached.FromCache)
+	require.NotEqual(t, VersionSkipped, cached.DetectedNextVersion)
+
+	refreshed, err := NewScannerWithOptions(pageOnly, stubDetector{}, Options{Cache: cache, RefreshCache: true}).ScanTarget(target)
+	require.Error(t, err)
+	require.False(t, refreshed.FromCache)
+
+	// New deployment
+	redeployed, err := NewScannerWithOptions(&stubFetcher{pages: map[string]string{target: page("b2")}}, stubDetector{}, Options{Cache: cache}).ScanTarget(target)
+	require.Error(t, err)
+	require.False(t, redeployed.FromCache)
+	require.Empty(t, redeployed.Routes)
+}
+
+func TestScanTarget_CacheKeepsPageHeaders(t *testing.T) {
+	t.Parallel()
+
+	const target = "https://www.example.com/"
+	page := `<html><body><script id="__NEXT_DATA__" type="application/json">{"buildId":"b1","props":{}}</script></body></html>`
+	cache := NewDirCache(t.TempDir(), 0)
+	scan := func(csp string) *ScanResult {
+		t.Helper()
+		fetcher := &statusFetcher{
+			stubFetcher: stubFetcher{pages: map[string]string{
+				target: page,
+				target + "_next/static/b1/_buildManifest.js": sampleManifestJS,
+			}},
+			headers: map[string]http.Header{target: {"Content-Security-Policy": {csp}}},
+		}
+		result, err := NewScannerWithOptions(fetcher, stubDetector{}, Options{Cache: cache}).ScanTarget(target)
+		require.NoError(t, err)
+		return result
+	}
+
+	first := scan("script-src 'self' 'unsafe-inline'")
+	require.False(t, first.FromCache)
+	require.True(t, first.CSP.UnsafeInline)
+
+	// The CSP changed without a new deployment: it is read from the new page
+	cached := scan("script-src 'self' 'nonce-abc'")
+	require.True(t, cached.FromCache)
+	require.Equal(t, first.Routes, cached.Routes)
+	require.False(t, cached.CSP.UnsafeInline)
+	require.True(t, cached.CSP.Nonce)
+	require.Equal(t, []string{"'self'", "'nonce-abc'"}, cached.CSP.Directives["script-src"])
+}
+
+func TestDirCache_TTL(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	result := &ScanResult{BaseURL: "https://example.com/", IsNextJS: true, BuildID: "b1"}
+	require.NoError(t, NewDirCache(dir, time.Hour).Put("example.com", "", result))
+
+	cached, ok := NewDirCache(dir, time.Hour).Get("EXAMPLE.com", "b1", "")
+	require.True(t, ok)
+	require.Equal(t, "b1", cached.BuildID)
+
+	_, ok = NewDirCache(dir, time.Nanosecond).Get("example.com", "b1", "")
+	require.False(t, ok, "expired results are not reused")
+
+	_, ok = NewDirCache(dir, -1).Get("example.com", "b1", "")
+	require.True(t, ok, "a negative TTL never expires results")
+
+	_, ok = NewDirCache(dir, time.Hour).Get("other.example.com", "b1", "")
+	require.False(t, ok)
+
+	_, ok = NewDirCache(dir, time.Hour).Get("example.com", "b1", "hash=true")
+	require.False(t, ok, "results are only reused with the same options")
+}
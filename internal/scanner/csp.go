@@ -0,0 +1,117 @@
+package scanner
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// CSPInfo describes the Content Security Policy sent with the target page.
+type CSPInfo struct {
+	ReportOnly    bool                // Only Content-Security-Policy-Report-Only was sent: violations are reported, not blocked
+	Directives    map[string][]string // Sources of each directive (e.g. "script-src" -> ["'self'", "'nonce-abc'"])
+	UnsafeInline  bool                // 'unsafe-inline' is allowed by some directive
+	UnsafeEval    bool                // 'unsafe-eval' is allowed by some directive
+	StrictDynamic bool                // 'strict-dynamic' is used, as in the nonce-based policies recommended for Next.js
+	Nonce         bool                // Scripts or styles are allowed through a 'nonce-...' source
+}
+
+// parseCSP reads the Content-Security-Policy headers of a response, falling
+// back to Content-Security-Policy-Report-Only. It returns nil when neither
+// is set. Several policies (repeated headers or comma-separated values) are
+// merged into one directive map; within a policy, only the first occurrence
+// of a directive counts, as browsers do.
+func parseCSP(headers http.Header) *CSPInfo {
+	csp := &CSPInfo{}
+	values := headers.Values("Content-Security-Policy")
+	if len(values) == 0 {
+		values = headers.Values("Content-Security-Policy-Report-Only")
+		csp.ReportOnly = true
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	csp.Directives = make(map[string][]string)
+	for _, value := range values {
+		for _, policy := range strings.Split(value, ",") {
+			seen := make(map[string]bool)
+			for _, directive := range strings.Split(policy, ";") {
+				fields := strings.Fields(directive)
+				if len(fields) == 0 {
+					continue
+				}
+				name := strings.ToLower(fields[0])
+				if seen[name] {
+					continue
+				}
+				seen[name] = true
+				csp.Directives[name] = appendUnique(csp.Directives[name], fields[1:]...)
+			}
+		}
+	}
+
+	for _, sources := range csp.Directives {
+		for _, source := range sources {
+			switch lower := strings.ToLower(source); {
+			case lower == "'unsafe-inline'":
+				csp.UnsafeInline = true
+			case lower == "'unsafe-eval'":
+				csp.UnsafeEval = true
+			case lower == "'strict-dynamic'":
+				csp.StrictDynamic = true
+			case strings.HasPrefix(lower, "'nonce-"):
+				csp.Nonce = true
+			}
+		}
+	}
+	return csp
+}
+
+// appendUnique appends the values not already in list.
+func appendUnique(list []string, values ...string) []string {
+	for _, value := range values {
+		found := false
+		for _, existing := range list {
+			if existing == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, value)
+		}
+	}
+	return list
+}
+
+// formatCSP summarizes a CSP for text output, e.g.
+// "enforced, 6 directives (strict-dynamic, nonce, unsafe-inline)".
+func formatCSP(csp *CSPInfo) string {
+	if csp == nil {
+		return "none"
+	}
+	mode := "enforced"
+	if csp.ReportOnly {
+		mode = "report-only"
+	}
+	var flags []string
+	for _, flag := range []struct {
+		set  bool
+		name string
+	}{
+		{csp.StrictDynamic, "strict-dynamic"},
+		{csp.Nonce, "nonce"},
+		{csp.UnsafeInline, "unsafe-inline"},
+		{csp.UnsafeEval, "unsafe-eval"},
+	} {
+		if flag.set {
+			flags = append(flags, flag.name)
+		}
+	}
+	summary := fmt.Sprintf("%s, %d directives", mode, len(csp.Directives))
+	if len(flags) > 0 {
+		summary += " (" + strings.Join(flags, ", ") + ")"
+	}
+	return summary
+}
@@ -0,0 +1,67 @@
+package scanner
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCSP(t *testing.T) {
+	t.Parallel()
+
+	t.Run("No policy", func(t *testing.T) {
+		t.Parallel()
+		require.Nil(t, parseCSP(http.Header{}))
+		require.Nil(t, parseCSP(nil))
+	})
+
+	t.Run("Nonce-based policy", func(t *testing.T) {
+		t.Parallel()
+		headers := http.Header{"Content-Security-Policy": {
+			"default-src 'self'; script-src 'self' 'nonce-abc123' 'strict-dynamic'; style-src 'self' 'unsafe-inline'; upgrade-insecure-requests",
+		}}
+
+		csp := parseCSP(headers)
+
+		require.NotNil(t, csp)
+		require.False(t, csp.ReportOnly)
+		require.Equal(t, map[string][]string{
+			"default-src":               {"'self'"},
+			"script-src":                {"'self'", "'nonce-abc123'", "'strict-dynamic'"},
+			"style-src":                 {"'self'", "'unsafe-inline'"},
+			"upgrade-insecure-requests": nil,
+		}, csp.Directives)
+		require.True(t, csp.Nonce)
+		require.True(t, csp.StrictDynamic)
+		require.True(t, csp.UnsafeInline)
+		require.False(t, csp.UnsafeEval)
+		require.Equal(t, "enforced, 4 directives (strict-dynamic, nonce, unsafe-inline)", formatCSP(csp))
+	})
+
+	t.Run("Repeated directives and several policies", func(t *testing.T) {
+		t.Parallel()
+		headers := http.Header{"Content-Security-Policy": {
+			"Script-Src 'self'; script-src 'unsafe-eval'",
+			"script-src https://cdn.example.com, img-src *",
+		}}
+
+		csp := parseCSP(headers)
+
+		// The second script-src of the first policy is ignored
+		require.Equal(t, []string{"'self'", "https://cdn.example.com"}, csp.Directives["script-src"])
+		require.Equal(t, []string{"*"}, csp.Directives["img-src"])
+		require.False(t, csp.UnsafeEval)
+	})
+
+	t.Run("Report-only policy", func(t *testing.T) {
+		t.Parallel()
+		headers := http.Header{"Content-Security-Policy-Report-Only": {"script-src 'unsafe-eval'; report-uri /csp"}}
+
+		csp := parseCSP(headers)
+
+		require.True(t, csp.ReportOnly)
+		require.True(t, csp.UnsafeEval)
+		require.Equal(t, "report-only, 2 directives (unsafe-eval)", formatCSP(csp))
+	})
+}
@@ -0,0 +1,136 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+)
+
+// DefaultMaxDataProbes is the default number of routes probed by ProbeData.
+const DefaultMaxDataProbes = 20
+
+// dataRouteURL returns the URL at which a Pages Router route's props are
+// served as JSON: /_next/data/<buildId>[/<locale>]/<route>.json, with "/"
+// served as index.json.
+func dataRouteURL(origin *url.URL, buildID, locale, routePath string) string {
+	if routePath == "/" {
+		routePath = "/index"
+	}
+	dataPath := path.Join("/_next/data", buildID, locale, routePath) + ".json"
+	return origin.ResolveReference(&url.URL{Path: dataPath}).String()
+}
+
+// dataProbePaths returns the concrete path to probe for each route, up to
+// max routes: static routes as-is, dynamic routes through the first example
+// resolved from the sitemap. API routes and unresolved dynamic routes are
+// skipped since they have no data file.
+func dataProbePaths(result *ScanResult, max int) map[string]string {
+	templates := make([]string, 0, len(result.Routes))
+	for routePath := range result.Routes {
+		templates = append(templates, routePath)
+	}
+	sort.Strings(templates)
+
+	probes := make(map[string]string)
+	for _, routePath := range templates {
+		if len(probes) >= max {
+			log.Printf("Data probe limit (%d routes) reached, skipping the rest", max)
+			break
+		}
+		if classifyRoute(routePath) == RouteTypeAPI {
+			continue
+		}
+		if !dynamicSegmentRegex.MatchString(routePath) {
+			probes[routePath] = routePath
+			continue
+		}
+		if examples := result.ResolvedRoutes[routePath]; len(examples) > 0 {
+			if example, err := url.Parse(examples[0]); err == nil {
+				if probePath, ok := appRoutePath(example.Path, result.BasePath, result.LocalePrefixes); ok {
+					probes[routePath] = probePath
+				}
+			}
+		}
+	}
+	return probes
+}
+
+// probeDataRoutes fetches the _next/data JSON of a sample of the routes and
+// records in result.DataRoutes whether each responded with page props, which
+// confirms the route is live and rendered with getStaticProps or
+// getServerSideProps. Failures are only logged.
+func (s *Scanner) probeDataRoutes(result *ScanResult, baseURL *url.URL) {
+	origin := &url.URL{Scheme: baseURL.Scheme, Host: baseURL.Host}
+	max := s.maxDataProbes
+	if max <= 0 {
+		max = DefaultMaxDataProbes
+	}
+	locale := ""
+	if len(result.Locales) > 0 {
+		locale = result.DefaultLocale
+	}
+
+	probes := dataProbePaths(result, max)
+	result.DataRoutes = make(map[string]bool, len(probes))
+	done := 0
+	for routePath, probePath := range probes {
+		done++
+		if s.progress != nil {
+			s.progress("Probing data route", done, len(probes))
+		}
+
+		dataURL := dataRouteURL(origin, result.BuildID, locale, probePath)
+		body, err := fetchText(s.fetcher, dataURL)
+		if err != nil {
+			log.Printf("Data probe: %s is not served: %v", dataURL, err)
+			result.DataRoutes[routePath] = false
+			continue
+		}
+		var data struct {
+			PageProps map[string]json.RawMessage `json:"pageProps"`
+		}
+		if err := json.Unmarshal([]byte(body), &data); err != nil || data.PageProps == nil {
+			log.Printf("Data probe: %s did not return page props", dataURL)
+			result.DataRoutes[routePath] = false
+			continue
+		}
+		keys := make([]string, 0, len(data.PageProps))
+		for key := range data.PageProps {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		log.Printf("Data probe: %s returned page props {%s}", dataURL, strings.Join(keys, ", "))
+		result.DataRoutes[routePath] = true
+	}
+}
+
+// countLive returns the number of probed routes whose data JSON is served.
+func countLive(dataRoutes map[string]bool) int {
+	live := 0
+	for _, ok := range dataRoutes {
+		if ok {
+			live++
+		}
+	}
+	return live
+}
+
+// formatDataRoutes renders the probed routes for text output, e.g.
+// "2/3 live (/, /about)".
+func formatDataRoutes(dataRoutes map[string]bool) string {
+	var live []string
+	for routePath, ok := range dataRoutes {
+		if ok {
+			live = append(live, routePath)
+		}
+	}
+	sort.Strings(live)
+	if len(live) == 0 {
+		return fmt.Sprintf("0/%d live", len(dataRoutes))
+	}
+	return fmt.Sprintf("%d/%d live (%s)", len(live), len(dataRoutes), strings.Join(live, ", "))
+}
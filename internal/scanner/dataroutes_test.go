@@ -0,0 +1,48 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataProbePaths(t *testing.T) {
+	t.Parallel()
+
+	result := &ScanResult{
+		Routes: map[string][]string{
+			"/":              {},
+			"/about":         {},
+			"/api/users":     {},
+			"/blog/[slug]":   {},
+			"/docs/[...all]": {},
+		},
+		ResolvedRoutes: map[string][]string{"/blog/[slug]": {"https://example.com/fr/blog/hello"}},
+		LocalePrefixes: []string{"/fr"},
+	}
+
+	require.Equal(t, map[string]string{"/": "/", "/about": "/about", "/blog/[slug]": "/blog/hello"}, dataProbePaths(result, 10))
+	require.Len(t, dataProbePaths(result, 1), 1)
+}
+
+func TestScanTarget_ProbeData(t *testing.T) {
+	t.Parallel()
+
+	const target = "https://www.example.com/"
+	manifest := `self.__BUILD_MANIFEST={"/":["static/chunks/pages/index-abc.js"],"/about":["static/chunks/pages/about-def.js"],sortedPages:["/","/about"]};`
+	fetcher := &stubFetcher{pages: map[string]string{
+		target: `<html><body><script id="__NEXT_DATA__" type="application/json">{"buildId":"b1","props":{},"locales":["en","fr"],"defaultLocale":"en"}</script></body></html>`,
+		target + "_next/static/b1/_buildManifest.js": manifest,
+		target + "_next/data/b1/en/index.json":       `{"pageProps":{"posts":[]},"__N_SSG":true}`,
+	}}
+	scr := NewScannerWithOptions(fetcher, stubDetector{}, Options{ProbeData: true})
+
+	result, err := scr.ScanTarget(target)
+
+	require.NoError(t, err)
+	require.Equal(t, map[string]bool{"/": true, "/about": false}, result.DataRoutes)
+
+	report, err := RenderMarkdown(result)
+	require.NoError(t, err)
+	require.Contains(t, report, "## Data Routes\n\n- `/`: live\n- `/about`: not served\n")
+}
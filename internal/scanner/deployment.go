@@ -0,0 +1,52 @@
+package scanner
+
+import (
+	"net/http"
+	"strings"
+)
+
+// DeploymentTypeStaticExport is reported in ScanResult.DeploymentType for
+// sites built with `output: 'export'` (or `next export`) and served as plain
+// files, without a Next.js server.
+const DeploymentTypeStaticExport = "static-export"
+
+// hasRSCPayload reports whether the page carries the inline React Server
+// Components payload App Router pages push onto self.__next_f. These pages
+// have no __NEXT_DATA__.
+func hasRSCPayload(htmlContent string) bool {
+	return strings.Contains(htmlContent, "self.__next_f")
+}
+
+// hasNextServerHeaders reports whether the response carries headers only a
+// Next.js server (or its hosting platform) sets, which rules out a static export.
+func hasNextServerHeaders(headers http.Header) bool {
+	if strings.Contains(strings.ToLower(headers.Get("X-Powered-By")), "next.js") {
+		return true
+	}
+	for name, values := range headers {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-nextjs-") || strings.HasPrefix(lower, "x-middleware-") || lower == "x-matched-path" {
+			return true
+		}
+		if lower == "vary" {
+			for _, v := range values {
+				if strings.Contains(v, "RSC") || strings.Contains(v, "Next-Router-State-Tree") {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// isStaticExport reports whether a Next.js page was served from a static
+// export. Pages Router exports flag __NEXT_DATA__ with "nextExport"; App
+// Router exports have no __NEXT_DATA__, so they are recognized by their
+// _next/static scripts or RSC payload being served without any Next.js
+// server header.
+func isStaticExport(nextData *NextData, hasAppRouterSignal bool, headers http.Header) bool {
+	if nextData != nil {
+		return nextData.NextExport
+	}
+	return hasAppRouterSignal && !hasNextServerHeaders(headers)
+}
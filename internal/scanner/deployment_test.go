@@ -0,0 +1,58 @@
+package scanner
+
+import (
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanTarget_StaticExport(t *testing.T) {
+	t.Parallel()
+
+	staticExportHTML, err := os.ReadFile("testdata/static_export.html")
+	require.NoError(t, err)
+
+	const target = "https://docs.example.com/"
+	testCases := []struct {
+		name string
+		html string
+	}{
+		{name: "App Router export", html: string(staticExportHTML)},
+		{name: "RSC payload only", html: `<html><body><script>(self.__next_f=self.__next_f||[]).push([0])</script></body></html>`},
+	}
+
+	for _, tc := range testCases {
+		fetcher := &stubFetcher{pages: map[string]string{target: tc.html}}
+		scr := NewScanner(fetcher, stubDetector{}, "")
+
+		result, err := scr.ScanTarget(target)
+
+		require.NoError(t, err, tc.name)
+		require.True(t, result.IsNextJS, tc.name)
+		require.Equal(t, DeploymentTypeStaticExport, result.DeploymentType, tc.name)
+	}
+}
+
+func TestIsStaticExport(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		nextData *NextData
+		headers  http.Header
+		want     bool
+	}{
+		{name: "Pages Router export", nextData: &NextData{BuildID: "b1", NextExport: true}, want: true},
+		{name: "Pages Router server", nextData: &NextData{BuildID: "b1"}, want: false},
+		{name: "App Router export", headers: http.Header{"Content-Type": {"text/html"}}, want: true},
+		{name: "App Router server", headers: http.Header{"Vary": {"RSC, Next-Router-State-Tree, Next-Router-Prefetch"}}, want: false},
+		{name: "Powered by Next.js", headers: http.Header{"X-Powered-By": {"Next.js"}}, want: false},
+		{name: "Cached by Next.js", headers: http.Header{"X-Nextjs-Cache": {"HIT"}}, want: false},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.want, isStaticExport(tc.nextData, true, tc.headers), tc.name)
+	}
+}
@@ -0,0 +1,155 @@
+package scanner
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/rodrigopv/nextr4y/internal/fetch"
+)
+
+// maxSitemapFiles bounds how many sitemap documents are fetched per scan,
+// including the children of sitemap index files.
+const maxSitemapFiles = 50
+
+// sitemapDocument covers both <urlset> sitemaps and <sitemapindex> files.
+type sitemapDocument struct {
+	XMLName  xml.Name
+	URLs     []sitemapLoc `xml:"url"`
+	Sitemaps []sitemapLoc `xml:"sitemap"`
+}
+
+type sitemapLoc struct {
+	Loc string `xml:"loc"`
+}
+
+// discoverServerPaths fetches /robots.txt and the sitemaps it declares (or
+// /sitemap.xml) from the target origin and records their paths in result.
+// Sitemap index files are followed one level deep. Failures are only logged.
+func (s *Scanner) discoverServerPaths(result *ScanResult, baseURL *url.URL) {
+	origin := &url.URL{Scheme: baseURL.Scheme, Host: baseURL.Host}
+
+	var sitemapFiles []string
+	robotsURL := origin.ResolveReference(&url.URL{Path: "/robots.txt"}).String()
+	if body, err := fetchText(s.fetcher, robotsURL); err != nil {
+		log.Printf("Discovery: could not fetch %s: %v", robotsURL, err)
+	} else {
+		result.RobotsDisallow, sitemapFiles = parseRobotsTxt(body)
+		log.Printf("Discovery: robots.txt lists %d disallowed paths and %d sitemaps", len(result.RobotsDisallow), len(sitemapFiles))
+	}
+	if len(sitemapFiles) == 0 {
+		sitemapFiles = []string{origin.ResolveReference(&url.URL{Path: "/sitemap.xml"}).String()}
+	}
+
+	seen := make(map[string]bool)
+	fetched := 0
+	for depth := 0; depth < 2 && len(sitemapFiles) > 0; depth++ {
+		var children []string
+		for _, sitemapURL := range sitemapFiles {
+			if fetched >= maxSitemapFiles {
+				log.Printf("Discovery: sitemap limit (%d files) reached, skipping the rest", maxSitemapFiles)
+				break
+			}
+			fetched++
+
+			body, err := fetchText(s.fetcher, sitemapURL)
+			if err != nil {
+				log.Printf("Discovery: could not fetch %s: %v", sitemapURL, err)
+				continue
+			}
+			pages, nested, err := parseSitemap(body)
+			if err != nil {
+				log.Printf("Discovery: could not parse %s: %v", sitemapURL, err)
+				continue
+			}
+			for _, page := range pages {
+				if !seen[page] {
+					seen[page] = true
+					result.SitemapURLs = append(result.SitemapURLs, page)
+				}
+			}
+			children = append(children, nested...)
+		}
+		// Only the first level of a sitemap index is followed
+		sitemapFiles = children
+	}
+	sort.Strings(result.SitemapURLs)
+	log.Printf("Discovery: sitemaps list %d URLs", len(result.SitemapURLs))
+}
+
+// fetchText fetches a URL and returns its body as a string.
+func fetchText(fetcher fetch.Fetcher, targetURL string) (string, error) {
+	reader, _, err := fetcher.Fetch(targetURL)
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+// parseRobotsTxt returns the unique Disallow paths (for any user agent) and
+// the Sitemap URLs declared in a robots.txt file.
+func parseRobotsTxt(body string) (disallow []string, sitemaps []string) {
+	seen := make(map[string]bool)
+	lineScanner := bufio.NewScanner(strings.NewReader(body))
+	for lineScanner.Scan() {
+		line := lineScanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		field, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(field)) {
+		case "disallow":
+			if !seen[value] {
+				seen[value] = true
+				disallow = append(disallow, value)
+			}
+		case "sitemap":
+			sitemaps = append(sitemaps, value)
+		}
+	}
+	sort.Strings(disallow)
+	return disallow, sitemaps
+}
+
+// parseSitemap returns the page URLs of a <urlset> sitemap, or the child
+// sitemap URLs of a <sitemapindex>.
+func parseSitemap(body string) (pages []string, nested []string, err error) {
+	var doc sitemapDocument
+	if err := xml.Unmarshal([]byte(body), &doc); err != nil {
+		return nil, nil, fmt.Errorf("invalid sitemap XML: %w", err)
+	}
+	switch doc.XMLName.Local {
+	case "urlset":
+		for _, u := range doc.URLs {
+			if loc := strings.TrimSpace(u.Loc); loc != "" {
+				pages = append(pages, loc)
+			}
+		}
+	case "sitemapindex":
+		for _, sm := range doc.Sitemaps {
+			if loc := strings.TrimSpace(sm.Loc); loc != "" {
+				nested = append(nested, loc)
+			}
+		}
+	default:
+		return nil, nil, fmt.Errorf("unexpected root element <%s>", doc.XMLName.Local)
+	}
+	return pages, nested, nil
+}
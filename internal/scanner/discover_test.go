@@ -0,0 +1,33 @@
+package scanner
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoverServerPaths(t *testing.T) {
+	t.Parallel()
+
+	fetcher := &stubFetcher{pages: map[string]string{
+		"https://example.com/robots.txt": "User-agent: *\nDisallow: /admin/ # staff only\nDisallow: /api/\nDisallow:\nUser-agent: Googlebot\nDisallow: /admin/\nSitemap: https://example.com/sitemap-index.xml\n",
+		"https://example.com/sitemap-index.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>https://example.com/sitemap-pages.xml</loc></sitemap>
+  <sitemap><loc>https://example.com/sitemap-missing.xml</loc></sitemap>
+</sitemapindex>`,
+		"https://example.com/sitemap-pages.xml": `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>https://example.com/pricing</loc></url>
+  <url><loc> https://example.com/ </loc></url>
+</urlset>`,
+	}}
+	scr := NewScannerWithOptions(fetcher, stubDetector{}, Options{Discover: true})
+	baseURL, _ := url.Parse("https://example.com/en/home")
+
+	var result ScanResult
+	scr.discoverServerPaths(&result, baseURL)
+
+	require.Equal(t, []string{"/admin/", "/api/"}, result.RobotsDisallow)
+	require.Equal(t, []string{"https://example.com/", "https://example.com/pricing"}, result.SitemapURLs)
+}
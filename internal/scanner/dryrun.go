@@ -0,0 +1,74 @@
+package scanner
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// planRequests returns the URLs a scan would request after the initial page
+// (listed first, since it was already fetched to build the plan), in the
+// order of the scan phases. Requests that depend on the contents of the
+// build manifest or of robots.txt (manifest chunks, declared sitemaps, data
+// routes) can't be known in advance and are left out.
+func (s *Scanner) planRequests(result *ScanResult, htmlContent string, baseURL, assetBaseURL *url.URL, initialScriptURLs map[string]bool, assetHost *assetHostFetcher) []string {
+	planned := []string{baseURL.String()}
+	seen := map[string]bool{baseURL.String(): true}
+	add := func(targetURL string) {
+		if !seen[targetURL] {
+			seen[targetURL] = true
+			planned = append(planned, targetURL)
+		}
+	}
+
+	if result.BuildID != "" && !s.skipRoutes {
+		candidates := candidateManifestURLs(assetBaseURL, result.AssetPrefix, result.BuildID, s.customBaseURL)
+		for _, candidateURL := range candidates {
+			add(assetHost.requestURL(candidateURL))
+		}
+		add(assetHost.requestURL(strings.TrimSuffix(candidates[0], "_buildManifest.js") + "_middlewareManifest.js"))
+	}
+
+	if !s.skipVersions {
+		scripts := make([]string, 0, len(initialScriptURLs))
+		for scriptURL := range initialScriptURLs {
+			scripts = append(scripts, assetHost.requestURL(scriptURL))
+		}
+		sort.Strings(scripts)
+		for _, scriptURL := range scripts {
+			add(scriptURL)
+		}
+	}
+
+	origin := &url.URL{Scheme: baseURL.Scheme, Host: baseURL.Host}
+	if s.discover {
+		add(origin.ResolveReference(&url.URL{Path: "/robots.txt"}).String())
+		add(origin.ResolveReference(&url.URL{Path: "/sitemap.xml"}).String())
+	}
+	if s.metadata {
+		add(origin.ResolveReference(&url.URL{Path: "/favicon.ico"}).String())
+		for _, manifestURL := range webManifestCandidates(htmlContent, baseURL) {
+			add(manifestURL)
+		}
+	}
+	if s.probeImage {
+		for _, probeURL := range imageProbeURLs(origin, result.BasePath, htmlContent) {
+			add(probeURL)
+		}
+	}
+	if s.probePreview {
+		for _, probeURL := range previewProbeURLs(origin, result.BasePath) {
+			add(probeURL)
+		}
+	}
+	if s.bruteforce {
+		wordlist := s.wordlist
+		if len(wordlist) == 0 {
+			wordlist = DefaultWordlist()
+		}
+		for _, routePath := range wordlist {
+			add(origin.ResolveReference(&url.URL{Path: routePath}).String())
+		}
+	}
+	return planned
+}
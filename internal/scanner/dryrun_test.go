@@ -0,0 +1,39 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigopv/nextr4y/internal/versiondetect"
+)
+
+func TestScanTarget_DryRun(t *testing.T) {
+	t.Parallel()
+
+	const target = "https://www.example.com/"
+	fetcher := &stubFetcher{pages: map[string]string{
+		target: `<html><head><link rel="manifest" href="/app.webmanifest"></head><body><script src="/_next/static/chunks/main-1.js"></script>` +
+			`<script id="__NEXT_DATA__" type="application/json">{"buildId":"b1","props":{}}</script></body></html>`,
+		target + "_next/static/b1/_buildManifest.js": sampleManifestJS,
+		target + "_next/static/chunks/main-1.js":     `console.log("main")`,
+	}}
+	scr := NewScannerWithOptions(fetcher, &versiondetect.HeuristicAssetScannerDetector{}, Options{DryRun: true, Metadata: true, Bruteforce: true, Wordlist: []string{"/admin"}})
+
+	result, err := scr.ScanTarget(target)
+
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		target,
+		target + "_next/static/b1/_buildManifest.js",
+		target + "_next/static/b1/_middlewareManifest.js",
+		target + "_next/static/chunks/main-1.js",
+		target + "favicon.ico",
+		target + "app.webmanifest",
+		target + "manifest.json",
+		target + "manifest.webmanifest",
+		target + "admin",
+	}, result.PlannedRequests)
+	require.Equal(t, 1, result.FetchCount, "only the page should be fetched")
+	require.False(t, result.ManifestFound)
+}
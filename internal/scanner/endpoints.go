@@ -0,0 +1,98 @@
+package scanner
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// quotedURLRegex matches absolute http(s) URLs inside JS string literals.
+var quotedURLRegex = regexp.MustCompile("[\"'`](https?://[^\"'`\\s<>]+)[\"'`]")
+
+// quotedPathRegex matches absolute paths inside JS string literals.
+var quotedPathRegex = regexp.MustCompile("[\"'`](/[A-Za-z0-9_\\-./~%:@?=&]+)[\"'`]")
+
+// endpointNoiseHosts are hosts that show up in every framework bundle
+// (documentation links, XML namespaces) and are never the app's own API.
+var endpointNoiseHosts = map[string]bool{
+	"www.w3.org":  true,
+	"reactjs.org": true,
+	"react.dev":   true,
+	"nextjs.org":  true,
+	"fb.me":       true,
+	"schema.org":  true,
+}
+
+// isAPIPath reports whether a path looks like an API or GraphQL endpoint.
+func isAPIPath(p string) bool {
+	lower := strings.ToLower(p)
+	return lower == "/api" || strings.Contains(lower, "/api/") || strings.Contains(lower, "graphql")
+}
+
+// extractEndpoints returns the external URLs and internal API/GraphQL paths
+// referenced as string literals in a JS chunk.
+func extractEndpoints(content []byte) []string {
+	seen := make(map[string]bool)
+	var endpoints []string
+	add := func(endpoint string) {
+		if !seen[endpoint] {
+			seen[endpoint] = true
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+
+	for _, m := range quotedURLRegex.FindAllSubmatch(content, -1) {
+		raw := string(m[1])
+		if strings.ContainsAny(raw, "${}") {
+			continue // Template literal or placeholder, not a usable URL
+		}
+		u, err := url.Parse(raw)
+		if err != nil || !strings.Contains(u.Host, ".") || endpointNoiseHosts[strings.ToLower(u.Hostname())] {
+			continue
+		}
+		add(raw)
+	}
+	for _, m := range quotedPathRegex.FindAllSubmatch(content, -1) {
+		if p := string(m[1]); isAPIPath(p) && !strings.HasPrefix(p, "//") {
+			add(p)
+		}
+	}
+	return endpoints
+}
+
+// endpointCollector accumulates the endpoints found in fetched assets.
+// It is safe for concurrent use.
+type endpointCollector struct {
+	mu        sync.Mutex
+	endpoints map[string]bool
+}
+
+// newEndpointCollector creates an empty endpointCollector.
+func newEndpointCollector() *endpointCollector {
+	return &endpointCollector{endpoints: make(map[string]bool)}
+}
+
+// inspect implements assetInspector.
+func (c *endpointCollector) inspect(_ string, body []byte) {
+	found := extractEndpoints(body)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, endpoint := range found {
+		c.endpoints[endpoint] = true
+	}
+}
+
+// Endpoints returns the unique endpoints collected so far, sorted.
+func (c *endpointCollector) Endpoints() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	endpoints := make([]string, 0, len(c.endpoints))
+	for endpoint := range c.endpoints {
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Strings(endpoints)
+	return endpoints
+}
@@ -0,0 +1,39 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigopv/nextr4y/internal/versiondetect"
+)
+
+func TestExtractEndpoints(t *testing.T) {
+	t.Parallel()
+
+	chunk := `var a="https://api.example.com/v1",b='/api/users',c=` + "`/graphql`" + `;` +
+		`fetch("/content/graphql?x=1");var d="https://reactjs.org/docs/error-decoder.html",e="http://www.w3.org/2000/svg";` +
+		`var f="/about",g="https://cdn.example.com/${path}",h="https://api.example.com/v1",i="//cdn.example.com/api/x";`
+
+	endpoints := extractEndpoints([]byte(chunk))
+
+	// Framework links, template literals, plain pages and duplicates are skipped
+	require.Equal(t, []string{"https://api.example.com/v1", "/api/users", "/graphql", "/content/graphql?x=1"}, endpoints)
+}
+
+func TestScanTarget_ExtractEndpoints(t *testing.T) {
+	t.Parallel()
+
+	const target = "https://www.example.com/"
+	fetcher := &stubFetcher{pages: map[string]string{
+		target: `<html><body><script src="/_next/static/chunks/main-1.js"></script>` +
+			`<script id="__NEXT_DATA__" type="application/json">{"buildId":"b1","props":{}}</script></body></html>`,
+		target + "_next/static/chunks/main-1.js": `fetch("https://graphql.example.com/graphql");fetch("/api/session")`,
+	}}
+	scr := NewScannerWithOptions(fetcher, &versiondetect.HeuristicAssetScannerDetector{}, Options{ExtractEndpoints: true})
+
+	result, err := scr.ScanTarget(target)
+
+	require.Error(t, err) // The build manifest is missing
+	require.Equal(t, []string{"/api/session", "https://graphql.example.com/graphql"}, result.DiscoveredEndpoints)
+}
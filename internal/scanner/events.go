@@ -0,0 +1,32 @@
+package scanner
+
+// ProgressEventType identifies the kind of a ProgressEvent.
+type ProgressEventType string
+
+// Types of the events sent to Options.Events.
+const (
+	EventHTMLFetched     ProgressEventType = "html_fetched"     // The target page was fetched, URL is its final URL
+	EventManifestFetched ProgressEventType = "manifest_fetched" // The build manifest was fetched from URL
+	EventAssetScanned    ProgressEventType = "asset_scanned"    // An asset was fetched for analysis, Found reports whether it was served
+	EventDone            ProgressEventType = "done"             // The scan finished, Err holds its error if any
+)
+
+// ProgressEvent is a typed step of a scan, for frontends that track its
+// progress without parsing the logs.
+type ProgressEvent struct {
+	Type  ProgressEventType
+	URL   string // Page, manifest or asset URL, empty for EventDone
+	Found bool   // EventAssetScanned: the asset was downloaded, false if its fetch failed
+	Err   error  // EventDone: the error returned by ScanTarget, nil on success
+}
+
+// EventFunc receives the ProgressEvents of a scan. It may be called from
+// several goroutines.
+type EventFunc func(event ProgressEvent)
+
+// emit sends event to the configured EventFunc, if any.
+func (s *Scanner) emit(event ProgressEvent) {
+	if s.events != nil {
+		s.events(event)
+	}
+}
@@ -0,0 +1,49 @@
+package scanner
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigopv/nextr4y/internal/versiondetect"
+)
+
+func TestScanTarget_Events(t *testing.T) {
+	t.Parallel()
+
+	const target = "https://www.example.com/"
+	pages := map[string]string{
+		target: `<html><body><script src="/_next/static/chunks/main-1.js"></script><script src="/_next/static/chunks/missing-2.js"></script>` +
+			`<script id="__NEXT_DATA__" type="application/json">{"buildId":"b1","props":{}}</script></body></html>`,
+		target + "_next/static/b1/_buildManifest.js": sampleManifestJS,
+		target + "_next/static/chunks/main-1.js":     `console.log("main")`,
+	}
+
+	var mu sync.Mutex
+	var events []ProgressEvent
+	scr := NewScannerWithOptions(&stubFetcher{pages: pages}, &versiondetect.HeuristicAssetScannerDetector{}, Options{
+		Events: func(event ProgressEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, event)
+		},
+	})
+
+	_, err := scr.ScanTarget(target)
+
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(events), 4)
+	require.Equal(t, ProgressEvent{Type: EventHTMLFetched, URL: target}, events[0])
+	require.Equal(t, ProgressEvent{Type: EventManifestFetched, URL: target + "_next/static/b1/_buildManifest.js"}, events[1])
+	require.Equal(t, ProgressEvent{Type: EventDone}, events[len(events)-1])
+
+	scanned := make(map[string]bool)
+	for _, event := range events[2 : len(events)-1] {
+		require.Equal(t, EventAssetScanned, event.Type)
+		scanned[event.URL] = event.Found
+	}
+	require.Equal(t, true, scanned[target+"_next/static/chunks/main-1.js"])
+	require.Contains(t, scanned, target+"_next/static/chunks/missing-2.js")
+	require.False(t, scanned[target+"_next/static/chunks/missing-2.js"])
+}
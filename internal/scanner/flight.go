@@ -0,0 +1,44 @@
+package scanner
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// flightPushMarker starts every inline script App Router pages use to stream
+// their React Server Components ("flight") payload.
+const flightPushMarker = "self.__next_f.push("
+
+// flightDataChunk is the type of the self.__next_f entries holding flight
+// data ([1, "..."]), as opposed to the bootstrap ([0]), form state ([2, ...])
+// and binary ([3, "..."]) entries.
+const flightDataChunk = 1
+
+// extractFlightPayload concatenates the flight data chunks pushed onto
+// self.__next_f by the page, in order. It returns "" for pages without an
+// App Router payload.
+func extractFlightPayload(htmlContent string) string {
+	var payload strings.Builder
+	rest := htmlContent
+	for {
+		i := strings.Index(rest, flightPushMarker)
+		if i < 0 {
+			break
+		}
+		rest = rest[i+len(flightPushMarker):]
+
+		// Decode only the pushed array; the decoder stops at its end, so
+		// "])" inside the payload strings can't cut it short
+		var entry []json.RawMessage
+		if err := json.NewDecoder(strings.NewReader(rest)).Decode(&entry); err != nil || len(entry) < 2 {
+			continue
+		}
+		var chunkType int
+		var data string
+		if json.Unmarshal(entry[0], &chunkType) != nil || chunkType != flightDataChunk || json.Unmarshal(entry[1], &data) != nil {
+			continue
+		}
+		payload.WriteString(data)
+	}
+	return payload.String()
+}
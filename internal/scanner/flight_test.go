@@ -0,0 +1,44 @@
+package scanner
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractFlightPayload(t *testing.T) {
+	t.Parallel()
+
+	html := `<script>(self.__next_f=self.__next_f||[]).push([0]);self.__next_f.push([2,null])</script>` +
+		`<script>self.__next_f.push([1,"1:I[2846,[],\"\"]\n"])</script>` +
+		`<script>self.__next_f.push([1,"0:[\"$\",\"$L1\",null,{\"x\":\"])\"}]\n"])</script>` +
+		`<script>self.__next_f.push([1,"truncated</script>`
+
+	require.Equal(t, "1:I[2846,[],\"\"]\n0:[\"$\",\"$L1\",null,{\"x\":\"])\"}]\n", extractFlightPayload(html))
+	require.Empty(t, extractFlightPayload(`<html><body>No payload</body></html>`))
+}
+
+func TestScanTarget_FlightPayloadRaw(t *testing.T) {
+	t.Parallel()
+
+	staticExportHTML, err := os.ReadFile("testdata/static_export.html")
+	require.NoError(t, err)
+
+	const target = "https://docs.example.com/"
+	for _, includeRaw := range []bool{false, true} {
+		fetcher := &stubFetcher{pages: map[string]string{target: string(staticExportHTML)}}
+		scr := NewScannerWithOptions(fetcher, stubDetector{}, Options{IncludeRaw: includeRaw})
+
+		result, err := scr.ScanTarget(target)
+
+		require.NoError(t, err)
+		if !includeRaw {
+			require.Empty(t, result.FlightPayloadRaw)
+			continue
+		}
+		require.True(t, strings.HasPrefix(result.FlightPayloadRaw, `1:HL["/_next/static/css/4c2ba23b6e8f2a50.css","style"]`+"\n"))
+		require.Contains(t, result.FlightPayloadRaw, `"buildId":"Xq3kP9mZtYc2_aR8vLw1n"`)
+	}
+}
@@ -0,0 +1,73 @@
+package scanner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"sync"
+
+	"github.com/rodrigopv/nextr4y/internal/fetch"
+)
+
+// DefaultHashConcurrency is the number of assets downloaded in parallel when hashing.
+const DefaultHashConcurrency = 8
+
+// hashAssets downloads every asset and returns its hex-encoded SHA-256 digest.
+// At most concurrency downloads run at once. Assets that cannot be fetched or
+// read are left out of hashes and reported in failures with the reason.
+// progress, if not nil, is called after each asset.
+func hashAssets(fetcher fetch.Fetcher, assets map[string]bool, concurrency int, progress ProgressFunc) (hashes map[string]string, failures map[string]string) {
+	if concurrency <= 0 {
+		concurrency = DefaultHashConcurrency
+	}
+	hashes = make(map[string]string, len(assets))
+	failures = make(map[string]string)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	done := 0
+	sem := make(chan struct{}, concurrency)
+
+	for assetURL := range assets {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(assetURL string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			digest, err := hashAsset(fetcher, assetURL)
+
+			mu.Lock()
+			defer mu.Unlock()
+			done++
+			if progress != nil {
+				progress("Hashing asset", done, len(assets))
+			}
+			if err != nil {
+				log.Printf("Asset hashing: failed to hash %s: %v", assetURL, err)
+				failures[assetURL] = err.Error()
+				return
+			}
+			hashes[assetURL] = digest
+		}(assetURL)
+	}
+	wg.Wait()
+
+	return hashes, failures
+}
+
+// hashAsset fetches a single asset and returns its hex-encoded SHA-256 digest.
+func hashAsset(fetcher fetch.Fetcher, assetURL string) (string, error) {
+	reader, _, err := fetcher.Fetch(assetURL)
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, reader); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,30 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashAssets(t *testing.T) {
+	t.Parallel()
+
+	fetcher := &stubFetcher{pages: map[string]string{
+		"https://example.com/_next/static/chunks/a.js": "console.log('a')",
+		"https://example.com/_next/static/chunks/b.js": "",
+	}}
+	assets := map[string]bool{
+		"https://example.com/_next/static/chunks/a.js":       true,
+		"https://example.com/_next/static/chunks/b.js":       true,
+		"https://example.com/_next/static/chunks/missing.js": true,
+	}
+
+	hashes, failures := hashAssets(fetcher, assets, 2, nil)
+
+	require.Equal(t, map[string]string{
+		"https://example.com/_next/static/chunks/a.js": "4c5a91fbf17028639cb5031430db0a06c27a6f9c382f7a75cafc78819aad6e9f",
+		"https://example.com/_next/static/chunks/b.js": "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+	}, hashes)
+	require.Len(t, failures, 1)
+	require.Contains(t, failures["https://example.com/_next/static/chunks/missing.js"], "404")
+}
@@ -0,0 +1,72 @@
+package scanner
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/rodrigopv/nextr4y/internal/fetch"
+	"github.com/rodrigopv/nextr4y/internal/versiondetect"
+)
+
+// stubFetcher serves canned bodies keyed by URL and fails for anything else.
+type stubFetcher struct {
+	pages map[string]string
+}
+
+func (f *stubFetcher) Fetch(targetURL string) (io.ReadCloser, string, error) {
+	body, ok := f.pages[targetURL]
+	if !ok {
+		return nil, targetURL, fmt.Errorf("stub: bad status code fetching %s: 404", targetURL)
+	}
+	return io.NopCloser(strings.NewReader(body)), targetURL, nil
+}
+
+func (f *stubFetcher) Capabilities() fetch.FetcherCapabilities {
+	return fetch.FetcherCapabilities{}
+}
+
+// stubDetector returns fixed versions without fetching anything.
+type stubDetector struct{}
+
+func (stubDetector) Detect(string, map[string]bool, *url.URL, fetch.Fetcher) versiondetect.Result {
+	return versiondetect.Result{NextVersion: "Unknown", ReactVersion: "Unknown"}
+}
+
+const sampleManifestJS = `self.__BUILD_MANIFEST=function(s,c){return{__rewrites:{afterFiles:[],beforeFiles:[],fallback:[]},"/":[s,"static/chunks/pages/index-abc.js"],"/_error":["static/chunks/pages/_error-def.js"],sortedPages:["/","/_app","/_error"]}}("static/chunks/1-xyz.js");self.__BUILD_MANIFEST_CB&&self.__BUILD_MANIFEST_CB();`
+
+// statusFetcher serves pages like stubFetcher, answering the URLs of
+// statuses with that status instead, and reports image/png as the content
+// type of the pages under /_next/image.
+type statusFetcher struct {
+	stubFetcher
+	statuses map[string]int
+	headers  map[string]http.Header // Extra response headers by URL
+}
+
+func (f *statusFetcher) Fetch(targetURL string) (io.ReadCloser, string, error) {
+	body, info, err := f.FetchWithInfo(targetURL)
+	return body, info.FinalURL, err
+}
+
+func (f *statusFetcher) FetchWithInfo(targetURL string) (io.ReadCloser, *fetch.ResponseInfo, error) {
+	info := &fetch.ResponseInfo{FinalURL: targetURL, RedirectChain: []string{targetURL}, Headers: http.Header{}}
+	for name, values := range f.headers[targetURL] {
+		info.Headers[name] = values
+	}
+	if status, ok := f.statuses[targetURL]; ok {
+		info.StatusCode = status
+		return nil, info, fmt.Errorf("stub: bad status code fetching %s: %d", targetURL, status)
+	}
+	body, _, err := f.stubFetcher.Fetch(targetURL)
+	info.StatusCode = 200
+	if err != nil {
+		info.StatusCode = 404
+	}
+	if strings.Contains(targetURL, "/_next/image?") {
+		info.Headers.Set("Content-Type", "image/png")
+	}
+	return body, info, err
+}
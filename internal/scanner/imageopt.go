@@ -0,0 +1,149 @@
+package scanner
+
+import (
+	"fmt"
+	"log"
+	"mime"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/rodrigopv/nextr4y/internal/fetch"
+)
+
+// States of the image optimizer reported in ScanResult.ImageOptimizer.
+const (
+	ImageOptimizerEnabled  = "enabled"
+	ImageOptimizerDisabled = "disabled"
+	ImageOptimizerUnknown  = "unknown"
+)
+
+// Parameters of the probe requests: 64 is one of the default
+// images.imageSizes and 75 the default quality, so a stock optimizer accepts
+// them, while no configuration allows a width of 1.
+const (
+	imageProbeWidth        = 64
+	imageProbeInvalidWidth = 1
+	imageProbeQuality      = 75
+)
+
+// imageProbeExternalURL is the external image the optimizer is asked to
+// fetch, to tell whether it accepts URLs from arbitrary hosts.
+const imageProbeExternalURL = "https://nextjs.org/favicon.ico"
+
+// pageImageRegex matches the url parameter of an image served through the
+// optimizer in the page, e.g. src="/_next/image?url=%2Fhero.png&amp;w=640".
+var pageImageRegex = regexp.MustCompile(`/_next/image\?url=([^&"'\s]+)`)
+
+// localImagePath returns a same-origin image to request through the
+// optimizer: the first one the page itself optimizes, or /favicon.ico.
+func localImagePath(htmlContent string) string {
+	if m := pageImageRegex.FindStringSubmatch(htmlContent); m != nil {
+		imagePath, err := url.QueryUnescape(m[1])
+		if err == nil && strings.HasPrefix(imagePath, "/") && !strings.HasPrefix(imagePath, "//") {
+			return imagePath
+		}
+	}
+	return "/favicon.ico"
+}
+
+// imageOptimizerURL returns the optimizer URL serving imageURL at width,
+// under the app's basePath.
+func imageOptimizerURL(origin *url.URL, basePath, imageURL string, width int) string {
+	u := origin.ResolveReference(&url.URL{Path: basePath + "/_next/image"})
+	u.RawQuery = fmt.Sprintf("url=%s&w=%d&q=%d", url.QueryEscape(imageURL), width, imageProbeQuality)
+	return u.String()
+}
+
+// imageProbeURLs returns the URLs requested by probeImageOptimizer, besides
+// the validation request it only sends when the first answer is ambiguous.
+func imageProbeURLs(origin *url.URL, basePath, htmlContent string) []string {
+	return []string{
+		imageOptimizerURL(origin, basePath, localImagePath(htmlContent), imageProbeWidth),
+		imageOptimizerURL(origin, basePath, imageProbeExternalURL, imageProbeWidth),
+	}
+}
+
+// probeImageOptimizer requests a local image through the /_next/image
+// optimizer to record in result.ImageOptimizer whether the endpoint is
+// served, then an external image to record in result.ImageOptimizerExternal
+// whether it fetches URLs from any host. An optimizer open to arbitrary hosts
+// can be abused to make the server request URLs of the attacker's choosing.
+func (s *Scanner) probeImageOptimizer(result *ScanResult, htmlContent string, baseURL *url.URL) {
+	origin := &url.URL{Scheme: baseURL.Scheme, Host: baseURL.Host}
+	probeURLs := imageProbeURLs(origin, result.BasePath, htmlContent)
+
+	result.ImageOptimizer = s.imageOptimizerState(probeURLs[0])
+	if result.ImageOptimizer == ImageOptimizerEnabled {
+		reader, info, err := fetch.FetchWithInfo(s.fetcher, probeURLs[1])
+		if err == nil {
+			reader.Close()
+			result.ImageOptimizerExternal = true
+			log.Printf("Image optimizer: %s was served, external URLs are allowed", probeURLs[1])
+		} else {
+			log.Printf("Image optimizer: external image not served (status %d): %v", info.StatusCode, err)
+		}
+	}
+}
+
+// imageOptimizerState tells from the answer to probeURL whether the image
+// optimizer is served. A 400 is the optimizer rejecting the parameters. A
+// 404, or a 200 that is not an image (e.g. a catch-all page), is ambiguous,
+// since the local image may not exist: a width no configuration accepts is
+// then requested, which only the optimizer answers with a 400.
+func (s *Scanner) imageOptimizerState(probeURL string) string {
+	reader, info, err := fetch.FetchWithInfo(s.fetcher, probeURL)
+	if err == nil {
+		reader.Close()
+		if isImageResponse(info) {
+			log.Printf("Image optimizer: %s was served", probeURL)
+			return ImageOptimizerEnabled
+		}
+	}
+	switch {
+	case info.StatusCode == 400:
+		log.Printf("Image optimizer: %s rejected with a 400", probeURL)
+		return ImageOptimizerEnabled
+	case err != nil && info.StatusCode == 0:
+		log.Printf("Image optimizer: no answer from %s: %v", probeURL, err)
+		return ImageOptimizerUnknown
+	}
+
+	validationURL := strings.Replace(probeURL, fmt.Sprintf("&w=%d&", imageProbeWidth), fmt.Sprintf("&w=%d&", imageProbeInvalidWidth), 1)
+	reader, info, err = fetch.FetchWithInfo(s.fetcher, validationURL)
+	if err == nil {
+		reader.Close()
+	}
+	switch info.StatusCode {
+	case 400:
+		log.Printf("Image optimizer: %s rejected the invalid width", validationURL)
+		return ImageOptimizerEnabled
+	case 404:
+		log.Printf("Image optimizer: %s is not served", validationURL)
+		return ImageOptimizerDisabled
+	}
+	log.Printf("Image optimizer: unexpected answer from %s (status %d)", validationURL, info.StatusCode)
+	return ImageOptimizerUnknown
+}
+
+// isImageResponse reports whether a successful response is an image. When
+// the fetcher doesn't report headers, any successful response counts.
+func isImageResponse(info *fetch.ResponseInfo) bool {
+	if info.Headers == nil {
+		return true
+	}
+	if info.Headers.Get("X-Nextjs-Cache") != "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(info.Headers.Get("Content-Type"))
+	return err == nil && strings.HasPrefix(mediaType, "image/")
+}
+
+// formatImageOptimizer renders the image optimizer state for text output,
+// e.g. "enabled (external URLs allowed)".
+func formatImageOptimizer(result *ScanResult) string {
+	if result.ImageOptimizerExternal {
+		return result.ImageOptimizer + " (external URLs allowed)"
+	}
+	return result.ImageOptimizer
+}
@@ -0,0 +1,70 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanTarget_ProbeImage(t *testing.T) {
+	t.Parallel()
+
+	const target = "https://www.example.com/"
+	const page = `<html><body><img src="/_next/image?url=%2Fhero.png&amp;w=640&amp;q=75"><script id="__NEXT_DATA__" type="application/json">{"buildId":"b1","props":{}}</script></body></html>`
+	localURL := target + "_next/image?url=%2Fhero.png&w=64&q=75"
+	validationURL := target + "_next/image?url=%2Fhero.png&w=1&q=75"
+	externalURL := target + "_next/image?url=https%3A%2F%2Fnextjs.org%2Ffavicon.ico&w=64&q=75"
+
+	tests := []struct {
+		name         string
+		pages        map[string]string
+		statuses     map[string]int
+		wantState    string
+		wantExternal bool
+	}{
+		{
+			name:         "served with external URLs",
+			pages:        map[string]string{localURL: "png", externalURL: "png"},
+			wantState:    ImageOptimizerEnabled,
+			wantExternal: true,
+		},
+		{
+			name:      "served for local images only",
+			pages:     map[string]string{localURL: "png"},
+			statuses:  map[string]int{externalURL: 400},
+			wantState: ImageOptimizerEnabled,
+		},
+		{
+			name:      "missing image rejected by validation",
+			statuses:  map[string]int{validationURL: 400, externalURL: 400},
+			wantState: ImageOptimizerEnabled,
+		},
+		{
+			name:      "not served",
+			wantState: ImageOptimizerDisabled,
+		},
+		{
+			name:      "blocked",
+			statuses:  map[string]int{localURL: 403, validationURL: 403},
+			wantState: ImageOptimizerUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			pages := map[string]string{target: page}
+			for pageURL, body := range tt.pages {
+				pages[pageURL] = body
+			}
+			fetcher := &statusFetcher{stubFetcher: stubFetcher{pages: pages}, statuses: tt.statuses}
+			scr := NewScannerWithOptions(fetcher, stubDetector{}, Options{ProbeImage: true})
+
+			result, _ := scr.ScanTarget(target)
+
+			require.Equal(t, tt.wantState, result.ImageOptimizer)
+			require.Equal(t, tt.wantExternal, result.ImageOptimizerExternal)
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package scanner
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/rodrigopv/nextr4y/internal/fetch"
+)
+
+// assetInspector analyzes the body of a fetched asset. Inspectors may be
+// called from several goroutines and must not modify body.
+type assetInspector func(assetURL string, body []byte)
+
+// inspectingFetcher wraps a Fetcher and passes every body it fetches to its
+// inspectors, so analyses reuse the chunks downloaded for version detection
+// and hashing instead of fetching them again.
+type inspectingFetcher struct {
+	fetch.Fetcher
+	inspectors []assetInspector
+	failures   *fetchFailures // Records failed fetches when not nil
+	events     EventFunc      // Receives an EventAssetScanned per fetch when not nil
+}
+
+// fetchFailures collects the error of every failed asset fetch, keyed by
+// URL. It is safe for concurrent use.
+type fetchFailures struct {
+	mu     sync.Mutex
+	errors map[string]string
+}
+
+// newFetchFailures creates an empty fetchFailures.
+func newFetchFailures() *fetchFailures {
+	return &fetchFailures{errors: make(map[string]string)}
+}
+
+// record stores the error of a failed fetch of assetURL.
+func (f *fetchFailures) record(assetURL string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.errors[assetURL] = err.Error()
+}
+
+// Errors returns a copy of the recorded errors.
+func (f *fetchFailures) Errors() map[string]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	failed := make(map[string]string, len(f.errors))
+	for assetURL, message := range f.errors {
+		failed[assetURL] = message
+	}
+	return failed
+}
+
+// Fetch implements the Fetcher interface. The body is read in full to be
+// inspected, then handed to the caller as a new reader.
+func (f *inspectingFetcher) Fetch(targetURL string) (io.ReadCloser, string, error) {
+	reader, finalURL, err := f.Fetcher.Fetch(targetURL)
+	if err != nil {
+		f.fetchFailed(targetURL, err)
+		return nil, finalURL, err
+	}
+	defer reader.Close()
+
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		f.fetchFailed(targetURL, err)
+		return nil, finalURL, err
+	}
+	for _, inspect := range f.inspectors {
+		inspect(finalURL, body)
+	}
+	if f.events != nil {
+		f.events(ProgressEvent{Type: EventAssetScanned, URL: targetURL, Found: true})
+	}
+
+	return io.NopCloser(bytes.NewReader(body)), finalURL, nil
+}
+
+// sortedStringKeys returns the keys of m, sorted.
+func sortedStringKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// fetchFailed records the failed fetch of assetURL and reports it.
+func (f *inspectingFetcher) fetchFailed(assetURL string, err error) {
+	if f.failures != nil {
+		f.failures.record(assetURL, err)
+	}
+	if f.events != nil {
+		f.events(ProgressEvent{Type: EventAssetScanned, URL: assetURL})
+	}
+}
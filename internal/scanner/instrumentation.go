@@ -0,0 +1,93 @@
+package scanner
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// instrumentationSignature matches code of an observability SDK or of a
+// Next.js instrumentation hook in a JS chunk.
+type instrumentationSignature struct {
+	signal string
+	regex  *regexp.Regexp
+}
+
+// instrumentationSignatures lists the markers recognized by
+// DetectInstrumentation. Package names are usually minified away, so they
+// rely on the strings the SDKs keep at runtime.
+var instrumentationSignatures = []instrumentationSignature{
+	// The global API registry key of @opentelemetry/api, e.g. Symbol.for("opentelemetry.js.api.1")
+	{"opentelemetry-api", regexp.MustCompile(`opentelemetry\.js\.api\.\d+`)},
+	{"opentelemetry-sdk", regexp.MustCompile(`@opentelemetry/(?:sdk-trace-web|sdk-trace-base|exporter-trace-otlp-http|instrumentation-fetch|instrumentation-document-load)`)},
+	{"otel-env", regexp.MustCompile(`OTEL_(?:EXPORTER_OTLP_(?:TRACES_)?ENDPOINT|SERVICE_NAME|RESOURCE_ATTRIBUTES)`)},
+	{"vercel-otel", regexp.MustCompile(`\bregisterOTel\b|@vercel/otel`)},
+	{"next-instrumentation-client", regexp.MustCompile(`\bonRouterTransitionStart\b`)},
+}
+
+// telemetryEndpointRegex matches OTLP/HTTP collector URLs inside JS string
+// literals, e.g. "https://otel.example.com/v1/traces".
+var telemetryEndpointRegex = regexp.MustCompile("[\"'`](https?://[^\"'`\\s<>]+/v1/(?:traces|metrics|logs))[\"'`]")
+
+// instrumentationDetector accumulates the instrumentation signals and the
+// collector endpoints found in fetched assets. It is safe for concurrent use.
+type instrumentationDetector struct {
+	mu        sync.Mutex
+	signals   map[string]bool
+	endpoints map[string]bool
+}
+
+// newInstrumentationDetector creates an empty instrumentationDetector.
+func newInstrumentationDetector() *instrumentationDetector {
+	return &instrumentationDetector{signals: make(map[string]bool), endpoints: make(map[string]bool)}
+}
+
+// inspect implements assetInspector. The client instrumentation file of
+// Next.js is also recognized by its chunk name.
+func (d *instrumentationDetector) inspect(assetURL string, body []byte) {
+	var signals, endpoints []string
+	if strings.Contains(assetURL, "/instrumentation-client") {
+		signals = append(signals, "next-instrumentation-client")
+	}
+	for _, signature := range instrumentationSignatures {
+		if signature.regex.Match(body) {
+			signals = append(signals, signature.signal)
+		}
+	}
+	for _, m := range telemetryEndpointRegex.FindAllSubmatch(body, -1) {
+		if !strings.ContainsAny(string(m[1]), "${}") {
+			endpoints = append(endpoints, string(m[1]))
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, signal := range signals {
+		d.signals[signal] = true
+	}
+	for _, endpoint := range endpoints {
+		d.endpoints[endpoint] = true
+	}
+}
+
+// Results returns the unique signals and collector endpoints found so far,
+// sorted, or nil if there were none.
+func (d *instrumentationDetector) Results() (signals, endpoints []string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return sortedSet(d.signals), sortedSet(d.endpoints)
+}
+
+// sortedSet returns the members of set, sorted, or nil if it is empty.
+func sortedSet(set map[string]bool) []string {
+	if len(set) == 0 {
+		return nil
+	}
+	members := make([]string, 0, len(set))
+	for member := range set {
+		members = append(members, member)
+	}
+	sort.Strings(members)
+	return members
+}
@@ -0,0 +1,45 @@
+package scanner
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigopv/nextr4y/internal/versiondetect"
+)
+
+func TestScanTarget_DetectInstrumentation(t *testing.T) {
+	t.Parallel()
+
+	const target = "https://www.example.com/"
+	pages := map[string]string{
+		target: `<html><body><script src="/_next/static/chunks/main-1.js"></script><script src="/_next/static/chunks/app-2.js"></script>` +
+			`<script id="__NEXT_DATA__" type="application/json">{"buildId":"b1","props":{}}</script></body></html>`,
+		target + "_next/static/b1/_buildManifest.js": sampleManifestJS,
+		target + "_next/static/chunks/main-1.js":     `var k=Symbol.for("opentelemetry.js.api.1");`,
+		target + "_next/static/chunks/app-2.js":      `new OTLPTraceExporter({url:"https://otel.example.com/v1/traces"});fetch("https://api.example.com/v1/users")`,
+	}
+
+	scr := NewScannerWithOptions(&stubFetcher{pages: pages}, &versiondetect.HeuristicAssetScannerDetector{}, Options{DetectInstrumentation: true})
+	result, err := scr.ScanTarget(target)
+	require.NoError(t, err)
+	require.True(t, result.Instrumentation)
+	require.Equal(t, []string{"opentelemetry-api"}, result.InstrumentationSignals)
+	require.Equal(t, []string{"https://otel.example.com/v1/traces"}, result.TelemetryEndpoints)
+
+	var text strings.Builder
+	RenderText(result, &text, false)
+	require.Contains(t, text.String(), "Instrumentation: opentelemetry-api\n")
+	require.Contains(t, text.String(), "Telemetry Endpoints (1):\n  - https://otel.example.com/v1/traces\n")
+
+	report, err := RenderMarkdown(result)
+	require.NoError(t, err)
+	require.Contains(t, report, "## Instrumentation\n\nSignals found in the JS chunks: opentelemetry-api.\n\n- `https://otel.example.com/v1/traces`\n")
+
+	scr = NewScannerWithOptions(&stubFetcher{pages: pages}, &versiondetect.HeuristicAssetScannerDetector{}, Options{})
+	result, err = scr.ScanTarget(target)
+	require.NoError(t, err)
+	require.False(t, result.Instrumentation)
+	require.Nil(t, result.TelemetryEndpoints)
+}
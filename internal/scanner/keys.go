@@ -0,0 +1,83 @@
+package scanner
+
+import (
+	"regexp"
+	"sort"
+	"sync"
+)
+
+// keyPattern matches the public key or ID of a third-party SDK embedded in
+// a JS chunk. The first submatch is the value reported.
+type keyPattern struct {
+	service string
+	regex   *regexp.Regexp
+}
+
+// keyPatterns lists the SDK keys recognized by --extract-keys. They are
+// client-side identifiers meant to ship in bundles, but still reveal which
+// accounts and projects a site reports to.
+var keyPatterns = []keyPattern{
+	{"sentry", regexp.MustCompile(`(https://[0-9a-f]{32}(?::[0-9a-f]{32})?@[A-Za-z0-9.\-]*sentry\.io/\d+)`)},
+	{"google-analytics", regexp.MustCompile(`["'\x60](G-[A-Z0-9]{6,12}|UA-\d{4,10}-\d{1,4})["'\x60]`)},
+	{"google-tag-manager", regexp.MustCompile(`["'\x60](GTM-[A-Z0-9]{4,8})["'\x60]`)},
+	{"segment", regexp.MustCompile(`(?:writeKey["']?\s*[:=]\s*["'\x60]|cdn\.segment\.com/analytics\.js/v1/)([A-Za-z0-9]{20,40})`)},
+	{"posthog", regexp.MustCompile(`["'\x60](phc_[A-Za-z0-9]{30,50})["'\x60]`)},
+	{"stripe", regexp.MustCompile(`["'\x60](pk_(?:live|test)_[A-Za-z0-9]{20,120})["'\x60]`)},
+}
+
+// extractKeys returns the SDK keys found in a JS chunk, by service.
+func extractKeys(content []byte) map[string][]string {
+	found := make(map[string][]string)
+	for _, pattern := range keyPatterns {
+		for _, m := range pattern.regex.FindAllSubmatch(content, -1) {
+			found[pattern.service] = appendUnique(found[pattern.service], string(m[1]))
+		}
+	}
+	return found
+}
+
+// keyCollector accumulates the SDK keys found in fetched assets.
+// It is safe for concurrent use.
+type keyCollector struct {
+	mu   sync.Mutex
+	keys map[string]map[string]bool
+}
+
+// newKeyCollector creates an empty keyCollector.
+func newKeyCollector() *keyCollector {
+	return &keyCollector{keys: make(map[string]map[string]bool)}
+}
+
+// inspect implements assetInspector.
+func (c *keyCollector) inspect(_ string, body []byte) {
+	found := extractKeys(body)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for service, keys := range found {
+		if c.keys[service] == nil {
+			c.keys[service] = make(map[string]bool)
+		}
+		for _, key := range keys {
+			c.keys[service][key] = true
+		}
+	}
+}
+
+// Keys returns the unique keys collected so far by service, sorted, or nil
+// if none were found.
+func (c *keyCollector) Keys() map[string][]string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.keys) == 0 {
+		return nil
+	}
+
+	keys := make(map[string][]string, len(c.keys))
+	for service, set := range c.keys {
+		for key := range set {
+			keys[service] = append(keys[service], key)
+		}
+		sort.Strings(keys[service])
+	}
+	return keys
+}
@@ -0,0 +1,54 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigopv/nextr4y/internal/versiondetect"
+)
+
+func TestExtractKeys(t *testing.T) {
+	t.Parallel()
+
+	chunk := `Sentry.init({dsn:"https://0123456789abcdef0123456789abcdef@o123456.ingest.sentry.io/4504"});` +
+		`gtag("config","G-AB12CD34EF");gtag("config",'UA-1234567-1');var t="GTM-K9ZX3B";` +
+		`analytics.load({writeKey:"aBcDeFgHiJkLmNoPqRsTuVwXyZ012345"});` +
+		`posthog.init("phc_abcdefghijklmnopqrstuvwxyz0123456789ABCD");loadStripe("pk_live_51Abcdefghijklmnopqrstuvwxyz");` +
+		`var x="G-A",y="https://0123456789abcdef0123456789abcdef@o123456.ingest.sentry.io/4504";`
+
+	keys := extractKeys([]byte(chunk))
+
+	// Too short IDs are skipped, repeated keys are reported once
+	require.Equal(t, map[string][]string{
+		"sentry":             {"https://0123456789abcdef0123456789abcdef@o123456.ingest.sentry.io/4504"},
+		"google-analytics":   {"G-AB12CD34EF", "UA-1234567-1"},
+		"google-tag-manager": {"GTM-K9ZX3B"},
+		"segment":            {"aBcDeFgHiJkLmNoPqRsTuVwXyZ012345"},
+		"posthog":            {"phc_abcdefghijklmnopqrstuvwxyz0123456789ABCD"},
+		"stripe":             {"pk_live_51Abcdefghijklmnopqrstuvwxyz"},
+	}, keys)
+}
+
+func TestScanTarget_ExtractKeys(t *testing.T) {
+	t.Parallel()
+
+	const target = "https://www.example.com/"
+	pages := map[string]string{
+		target: `<html><body><script src="/_next/static/chunks/main-1.js"></script><script src="/_next/static/chunks/app-2.js"></script>` +
+			`<script id="__NEXT_DATA__" type="application/json">{"buildId":"b1","props":{}}</script></body></html>`,
+		target + "_next/static/b1/_buildManifest.js": sampleManifestJS,
+		target + "_next/static/chunks/main-1.js":     `gtag("config","G-ZZ99YY88")`,
+		target + "_next/static/chunks/app-2.js":      `gtag("config","G-AA11BB22");gtag("config","G-ZZ99YY88")`,
+	}
+
+	scr := NewScannerWithOptions(&stubFetcher{pages: pages}, &versiondetect.HeuristicAssetScannerDetector{}, Options{ExtractKeys: true})
+	result, err := scr.ScanTarget(target)
+	require.NoError(t, err)
+	require.Equal(t, map[string][]string{"google-analytics": {"G-AA11BB22", "G-ZZ99YY88"}}, result.EmbeddedKeys)
+
+	scr = NewScannerWithOptions(&stubFetcher{pages: pages}, &versiondetect.HeuristicAssetScannerDetector{}, Options{})
+	result, err = scr.ScanTarget(target)
+	require.NoError(t, err)
+	require.Nil(t, result.EmbeddedKeys)
+}
@@ -0,0 +1,138 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/rodrigopv/nextr4y/internal/fetch"
+)
+
+// ScanManifest reads the routes and assets of a build manifest fetched
+// directly from manifestURL, without fetching nor parsing any page: useful
+// when the page blocks scanning but the manifest is reachable. The build ID
+// and the asset base URL are taken from the manifest URL
+// (<asset base>/_next/static/<build ID>/_buildManifest.js), unless a custom
+// base URL is set. No other asset is fetched, so versions are reported as
+// VersionSkipped.
+func (s *Scanner) ScanManifest(manifestURL string) (*ScanResult, error) {
+	if s.customBaseURLErr != nil {
+		return nil, fmt.Errorf("scanner: %w", s.customBaseURLErr)
+	}
+
+	start := time.Now()
+	timing := fetch.NewTimingFetcher(s.fetcher)
+	scan := *s
+	scan.fetcher = timing
+
+	result, err := scan.scanManifest(manifestURL)
+	result.SchemaVersion = SchemaVersion
+	result.TotalScanDuration = time.Since(start)
+	result.FetchCount, result.FetchDuration = timing.Stats()
+	s.emit(ProgressEvent{Type: EventDone, Err: err})
+	return result, err
+}
+
+// scanManifest implements ScanManifest.
+func (s *Scanner) scanManifest(manifestURL string) (*ScanResult, error) {
+	if !strings.Contains(manifestURL, "://") {
+		manifestURL = "https://" + manifestURL
+	}
+	result := &ScanResult{
+		BaseURL:              manifestURL,
+		Routes:               make(map[string][]string),
+		AllAssets:            make(map[string]bool),
+		DetectedNextVersion:  VersionSkipped,
+		DetectedReactVersion: VersionSkipped,
+	}
+
+	log.Printf("Fetching build manifest from: %s", manifestURL)
+	reader, info, err := fetch.FetchWithInfo(s.fetcher, manifestURL)
+	if err != nil {
+		result.RedirectChain = redirectChain(info)
+		result.ExecutionError = fmt.Errorf("scanner: failed to fetch build manifest at %s: %w", manifestURL, err)
+		return result, result.ExecutionError
+	}
+	defer reader.Close()
+	result.RedirectChain = redirectChain(info)
+	if info != nil && info.FinalURL != "" {
+		result.BaseURL = info.FinalURL
+	}
+	result.ManifestFound = true
+	s.emit(ProgressEvent{Type: EventManifestFetched, URL: result.BaseURL})
+
+	finalURL, err := url.Parse(result.BaseURL)
+	if err != nil {
+		result.ExecutionError = fmt.Errorf("scanner: invalid final URL '%s' received from fetcher: %w", result.BaseURL, err)
+		return result, result.ExecutionError
+	}
+	var assetBaseURL url.URL
+	assetBaseURL, result.BuildID = manifestLocation(finalURL)
+	if result.BuildID != "" {
+		result.BuildIDType, result.BuildIDDate = classifyBuildID(result.BuildID)
+	} else {
+		log.Printf("Warning: %s is not at <asset base>/_next/static/<build ID>/_buildManifest.js, the build ID is unknown and assets are resolved against its origin.", result.BaseURL)
+	}
+	if s.customBaseURL != "" {
+		log.Printf("Using custom base URL: %s", s.customBaseURL)
+		assetBaseURL = resolveAssetBaseURL(finalURL, s.customBaseURL, "", "")
+	}
+	result.AssetBaseURL = assetBaseURL.String()
+
+	manifestBytes, err := io.ReadAll(reader)
+	if err != nil {
+		result.ExecutionError = fmt.Errorf("scanner: failed to read build manifest from %s: %w", result.BaseURL, err)
+		return result, result.ExecutionError
+	}
+
+	execStart := time.Now()
+	execData, err := executeManifestJS(string(manifestBytes), s.manifestExecTimeout)
+	result.ManifestExecDuration = time.Since(execStart)
+	log.Printf("Build manifest evaluated in %s (limit %s)", result.ManifestExecDuration.Round(time.Millisecond), s.manifestExecTimeout)
+	if err != nil {
+		result.ExecutionError = fmt.Errorf("scanner: manifest processing failed: goja execution failed: %w", err)
+		return result, result.ExecutionError
+	}
+
+	result.IsNextJS = true
+	result.ManifestExecOK = true
+	routes, manifestAssets := extractRoutesAndAssets(execData, result.AssetBaseURL)
+	result.Routes, result.SpecialPages = splitSpecialPages(routes)
+	result.RouteDetails = buildRouteInfos(result.Routes)
+	result.AllAssets = manifestAssets
+	result.AssetHosts = assetHosts(manifestAssets)
+	if s.includeRaw {
+		// Values the VM exported as JS functions can't be encoded, so only keep JSON-safe manifests
+		if _, jsonErr := json.Marshal(execData); jsonErr != nil {
+			log.Printf("Not including raw build manifest: %v", jsonErr)
+		} else {
+			result.BuildManifestRaw = execData
+		}
+	}
+	log.Printf("Successfully processed build manifest. Found %d routes, %d special pages and %d assets.", len(result.Routes), len(result.SpecialPages), len(manifestAssets))
+	return result, nil
+}
+
+// manifestLocation derives the asset base URL and the build ID from the URL
+// of a build manifest, e.g. https://cdn.example.com/app/ and "abc123" from
+// https://cdn.example.com/app/_next/static/abc123/_buildManifest.js. A URL
+// that doesn't follow this layout resolves to its origin and no build ID.
+func manifestLocation(manifestURL *url.URL) (assetBaseURL url.URL, buildID string) {
+	assetBaseURL = url.URL{Scheme: manifestURL.Scheme, User: manifestURL.User, Host: manifestURL.Host, Path: "/"}
+	dir, file := path.Split(manifestURL.Path)
+	i := strings.LastIndex(dir, "/_next/static/")
+	if file != "_buildManifest.js" || i == -1 {
+		return assetBaseURL, ""
+	}
+	buildID = strings.Trim(dir[i+len("/_next/static/"):], "/")
+	if buildID == "" || strings.Contains(buildID, "/") {
+		return assetBaseURL, ""
+	}
+	assetBaseURL.Path = dir[:i+1]
+	return assetBaseURL, buildID
+}
@@ -0,0 +1,53 @@
+package scanner
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanManifest(t *testing.T) {
+	t.Parallel()
+
+	const manifestURL = "https://cdn.example.com/app/_next/static/b1/_buildManifest.js"
+	scr := NewScannerWithOptions(&stubFetcher{pages: map[string]string{manifestURL: sampleManifestJS}}, &stubDetector{}, Options{})
+	result, err := scr.ScanManifest(manifestURL)
+	require.NoError(t, err)
+	require.True(t, result.IsNextJS)
+	require.True(t, result.ManifestExecOK)
+	require.Equal(t, "b1", result.BuildID)
+	require.Equal(t, "https://cdn.example.com/app/", result.AssetBaseURL)
+	require.Equal(t, []string{
+		"https://cdn.example.com/app/_next/static/chunks/1-xyz.js",
+		"https://cdn.example.com/app/_next/static/chunks/pages/index-abc.js",
+	}, result.Routes["/"])
+	require.Contains(t, result.SpecialPages, "/_error")
+	require.Equal(t, VersionSkipped, result.DetectedNextVersion)
+	require.Equal(t, SchemaVersion, result.SchemaVersion)
+
+	_, err = scr.ScanManifest("https://cdn.example.com/_next/static/missing/_buildManifest.js")
+	require.Error(t, err)
+}
+
+func TestManifestLocation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		manifestURL  string
+		assetBaseURL string
+		buildID      string
+	}{
+		{"https://example.com/_next/static/abc123/_buildManifest.js", "https://example.com/", "abc123"},
+		{"https://cdn.example.com/app/_next/static/abc123/_buildManifest.js", "https://cdn.example.com/app/", "abc123"},
+		{"https://example.com/manifest.js", "https://example.com/", ""},
+		{"https://example.com/_next/static/a/b/_buildManifest.js", "https://example.com/", ""},
+	}
+	for _, tt := range tests {
+		u, err := url.Parse(tt.manifestURL)
+		require.NoError(t, err)
+		assetBaseURL, buildID := manifestLocation(u)
+		require.Equal(t, tt.assetBaseURL, assetBaseURL.String(), tt.manifestURL)
+		require.Equal(t, tt.buildID, buildID, tt.manifestURL)
+	}
+}
@@ -0,0 +1,82 @@
+package scanner
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates/report.md.tmpl
+var markdownTemplateText string
+
+var markdownTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"cell":            markdownCell,
+	"join":            strings.Join,
+	"runtime":         formatRuntime,
+	"timing":          formatTiming,
+	"sortedKeys":      sortedKeys,
+	"buildID":         formatBuildID,
+	"jsBundle":        formatJSBundle,
+	"version":         formatVersion,
+	"csp":             formatCSP,
+	"appMetadata":     formatAppMetadata,
+	"assetHost":       formatAssetHost,
+	"discoveredRoute": formatDiscoveredRoute,
+	"runtimeConfig":   formatRuntimeConfig,
+	"imageOptimizer":  formatImageOptimizer,
+	"previewMode":     formatPreviewMode,
+	"securityHeaders": formatSecurityHeaders,
+}).Parse(markdownTemplateText))
+
+// markdownReport is the data passed to the Markdown template.
+type markdownReport struct {
+	Result    *ScanResult
+	Routes    []RouteInfo
+	PropsJSON string // Indented "props" of __NEXT_DATA__, empty if unavailable
+}
+
+// RenderMarkdown formats a scan result as a Markdown report, suitable for
+// pasting into tickets or pull requests.
+func RenderMarkdown(result *ScanResult) (string, error) {
+	report := markdownReport{
+		Result:    result,
+		Routes:    buildRouteInfos(result.Routes),
+		PropsJSON: indentedProps(result.NextDataJSONRaw),
+	}
+
+	var buf bytes.Buffer
+	if err := markdownTemplate.Execute(&buf, report); err != nil {
+		return "", fmt.Errorf("failed to render Markdown report: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// markdownCell escapes a value for use inside a Markdown table cell.
+func markdownCell(value string) string {
+	if value == "" {
+		return "-"
+	}
+	value = strings.ReplaceAll(value, "|", `\|`)
+	return strings.ReplaceAll(value, "\n", " ")
+}
+
+// indentedProps extracts and indents the "props" object of a raw __NEXT_DATA__ document.
+func indentedProps(rawNextData string) string {
+	if rawNextData == "" {
+		return ""
+	}
+	var nextData struct {
+		Props json.RawMessage `json:"props"`
+	}
+	if err := json.Unmarshal([]byte(rawNextData), &nextData); err != nil || len(nextData.Props) == 0 {
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, nextData.Props, "", "  "); err != nil {
+		return ""
+	}
+	return buf.String()
+}
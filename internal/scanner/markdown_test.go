@@ -0,0 +1,35 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	t.Parallel()
+
+	result := &ScanResult{
+		BaseURL:              "https://example.com/",
+		IsNextJS:             true,
+		BuildID:              "build|1",
+		DetectedNextVersion:  "14.1.0",
+		DetectedReactVersion: "18.2.0",
+		Routes: map[string][]string{
+			"/":            {"a.js", "b.js"},
+			"/blog/[slug]": {"c.js"},
+			"/api/users":   {},
+		},
+		AllAssets:       map[string]bool{"a.js": true, "b.js": true, "c.js": true},
+		NextDataJSONRaw: `{"buildId":"build|1","props":{"pageProps":{"apiKey":"secret"}}}`,
+	}
+
+	report, err := RenderMarkdown(result)
+	require.NoError(t, err)
+
+	require.Contains(t, report, "# nextr4y Scan Report: https://example.com/\n")
+	require.Contains(t, report, "| Build ID | build\\|1 |\n")
+	require.Contains(t, report, "| Next.js Version | 14.1.0 |\n")
+	require.Contains(t, report, "## Routes (3)\n\n- `/` (2 assets)\n- `/api/users` (0 assets, api)\n- `/blog/[slug]` (1 assets, dynamic)\n")
+	require.Contains(t, report, "```json\n{\n  \"pageProps\": {\n    \"apiKey\": \"secret\"\n  }\n}\n```")
+}
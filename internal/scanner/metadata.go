@@ -0,0 +1,113 @@
+package scanner
+
+import (
+	"encoding/json"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// webManifestPaths are the conventional locations of the PWA web app
+// manifest, tried when the page has no <link rel="manifest">: the classic
+// /manifest.json and the /manifest.webmanifest generated by the App Router.
+var webManifestPaths = []string{"/manifest.json", "/manifest.webmanifest"}
+
+// webManifest holds the fields of a web app manifest surfaced in ScanResult.
+type webManifest struct {
+	Name       string `json:"name"`
+	ShortName  string `json:"short_name"`
+	ThemeColor string `json:"theme_color"`
+}
+
+// collectMetadata records whether the target serves /favicon.ico and the app
+// name and theme color of its web app manifest, if any. Missing files are
+// only logged.
+func (s *Scanner) collectMetadata(result *ScanResult, htmlContent string, baseURL *url.URL) {
+	origin := &url.URL{Scheme: baseURL.Scheme, Host: baseURL.Host}
+
+	faviconURL := origin.ResolveReference(&url.URL{Path: "/favicon.ico"}).String()
+	if body, err := fetchText(s.fetcher, faviconURL); err != nil {
+		log.Printf("Metadata: could not fetch %s: %v", faviconURL, err)
+	} else if body == "" || looksLikeHTML(body) {
+		log.Printf("Metadata: %s is not an icon (empty or an HTML fallback page)", faviconURL)
+	} else {
+		result.FaviconFound = true
+	}
+
+	for _, manifestURL := range webManifestCandidates(htmlContent, baseURL) {
+		body, err := fetchText(s.fetcher, manifestURL)
+		if err != nil {
+			log.Printf("Metadata: could not fetch %s: %v", manifestURL, err)
+			continue
+		}
+		var manifest webManifest
+		if err := json.Unmarshal([]byte(body), &manifest); err != nil {
+			log.Printf("Metadata: %s is not a web app manifest: %v", manifestURL, err)
+			continue
+		}
+		result.WebManifestURL = manifestURL
+		result.AppName = manifest.Name
+		if result.AppName == "" {
+			result.AppName = manifest.ShortName
+		}
+		result.ThemeColor = manifest.ThemeColor
+		log.Printf("Metadata: web app manifest found at %s (name: %q, theme color: %q)", manifestURL, result.AppName, result.ThemeColor)
+		return
+	}
+}
+
+// webManifestCandidates returns the URLs at which the web app manifest may
+// be found, in the order they should be tried: the page's <link
+// rel="manifest">, then the conventional paths.
+func webManifestCandidates(htmlContent string, baseURL *url.URL) []string {
+	origin := &url.URL{Scheme: baseURL.Scheme, Host: baseURL.Host}
+	var candidates []string
+	if href := manifestLink(htmlContent); href != "" {
+		if manifestURL, err := baseURL.Parse(href); err == nil {
+			candidates = append(candidates, manifestURL.String())
+		}
+	}
+	for _, manifestPath := range webManifestPaths {
+		candidates = append(candidates, origin.ResolveReference(&url.URL{Path: manifestPath}).String())
+	}
+	return candidates
+}
+
+// manifestLink returns the href of the page's <link rel="manifest">, or "".
+func manifestLink(htmlContent string) string {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent))
+	if err != nil {
+		return ""
+	}
+	href, _ := doc.Find(`link[rel="manifest"]`).First().Attr("href")
+	return strings.TrimSpace(href)
+}
+
+// looksLikeHTML reports whether body is an HTML document, as served by
+// catch-all routes for files that don't exist.
+func looksLikeHTML(body string) bool {
+	start := strings.ToLower(strings.TrimSpace(body))
+	return strings.HasPrefix(start, "<!doctype html") || strings.HasPrefix(start, "<html")
+}
+
+// formatAppMetadata summarizes the collected metadata for text output, e.g.
+// `"Acme Store", theme color #0f172a, favicon.ico found`. It returns "" when
+// nothing was found.
+func formatAppMetadata(result *ScanResult) string {
+	var parts []string
+	if result.AppName != "" {
+		parts = append(parts, strconv.Quote(result.AppName))
+	} else if result.WebManifestURL != "" {
+		parts = append(parts, "unnamed web app manifest")
+	}
+	if result.ThemeColor != "" {
+		parts = append(parts, "theme color "+result.ThemeColor)
+	}
+	if result.FaviconFound {
+		parts = append(parts, "favicon.ico found")
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,75 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanTarget_Metadata(t *testing.T) {
+	t.Parallel()
+
+	const target = "https://www.example.com/"
+	page := func(head string) string {
+		return `<html><head>` + head + `</head><body><script id="__NEXT_DATA__" type="application/json">{"buildId":"b1","props":{}}</script></body></html>`
+	}
+
+	testCases := []struct {
+		name    string
+		pages   map[string]string
+		want    ScanResult
+		summary string
+	}{
+		{
+			name: "Linked manifest and favicon",
+			pages: map[string]string{
+				target:                                       page(`<link rel="manifest" href="/static/site.webmanifest">`),
+				target + "favicon.ico":                       "\x00\x00\x01\x00",
+				target + "static/site.webmanifest":           `{"name":"Acme Store","short_name":"Acme","theme_color":"#0f172a"}`,
+				target + "manifest.json":                     `{"name":"Wrong"}`,
+				target + "_next/static/b1/_buildManifest.js": sampleManifestJS,
+			},
+			want:    ScanResult{FaviconFound: true, WebManifestURL: target + "static/site.webmanifest", AppName: "Acme Store", ThemeColor: "#0f172a"},
+			summary: `"Acme Store", theme color #0f172a, favicon.ico found`,
+		},
+		{
+			name: "Fallback manifest with short name only",
+			pages: map[string]string{
+				target:                          page(""),
+				target + "manifest.webmanifest": `{"short_name":"Acme"}`,
+				target + "_next/static/b1/_buildManifest.js": sampleManifestJS,
+			},
+			want:    ScanResult{WebManifestURL: target + "manifest.webmanifest", AppName: "Acme"},
+			summary: `"Acme"`,
+		},
+		{
+			name: "HTML fallback pages",
+			pages: map[string]string{
+				target:                   page(""),
+				target + "favicon.ico":   "<!DOCTYPE html><html><body>Not found</body></html>",
+				target + "manifest.json": "<!DOCTYPE html><html><body>Not found</body></html>",
+				target + "_next/static/b1/_buildManifest.js": sampleManifestJS,
+			},
+			want:    ScanResult{},
+			summary: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			scr := NewScannerWithOptions(&stubFetcher{pages: tc.pages}, stubDetector{}, Options{Metadata: true})
+
+			result, err := scr.ScanTarget(target)
+
+			require.NoError(t, err)
+			require.Equal(t, tc.want.FaviconFound, result.FaviconFound)
+			require.Equal(t, tc.want.WebManifestURL, result.WebManifestURL)
+			require.Equal(t, tc.want.AppName, result.AppName)
+			require.Equal(t, tc.want.ThemeColor, result.ThemeColor)
+			require.Equal(t, tc.summary, formatAppMetadata(result))
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package scanner
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rodrigopv/nextr4y/internal/fetch"
+)
+
+// previewPaths are the conventional routes turning on Preview Mode (Pages
+// Router, res.setPreviewData) and Draft Mode (App Router, draftMode()), as
+// set up by the CMS examples of Next.js, and the routes turning it off.
+var previewPaths = []string{"/api/preview", "/api/draft", "/api/enable-draft", "/api/exit-preview", "/api/disable-draft"}
+
+// previewCookieNames are the cookies Next.js sets while Preview or Draft
+// Mode is on.
+var previewCookieNames = []string{"__prerender_bypass", "__next_preview_data"}
+
+// previewProbeURLs returns the preview routes under the app's basePath.
+func previewProbeURLs(origin *url.URL, basePath string) []string {
+	probeURLs := make([]string, len(previewPaths))
+	for i, previewPath := range previewPaths {
+		probeURLs[i] = origin.ResolveReference(&url.URL{Path: basePath + previewPath}).String()
+	}
+	return probeURLs
+}
+
+// probePreviewMode requests the conventional preview routes and records in
+// result.PreviewEndpoints those the target answers with anything but a 404:
+// without the CMS secret, a live route typically rejects the request (401,
+// or a 405 for a method it doesn't serve) or redirects. As in
+// bruteforceRoutes, a random API path is probed first so that a catch-all
+// answer is not mistaken for a route. Preview cookies set by any of the
+// responses are recorded in result.PreviewCookies.
+func (s *Scanner) probePreviewMode(result *ScanResult, baseURL *url.URL) {
+	origin := &url.URL{Scheme: baseURL.Scheme, Host: baseURL.Host}
+	cookies := make(map[string]bool)
+	result.PreviewEndpoints = []DiscoveredRoute{} // Probed, even if nothing is found
+
+	baselineURL := origin.ResolveReference(&url.URL{Path: fmt.Sprintf("%s/api/nextr4y-%d", result.BasePath, time.Now().UnixNano())}).String()
+	baseline, baselineAnswered := s.probePreviewURL(baselineURL, cookies)
+
+	for i, probeURL := range previewProbeURLs(origin, result.BasePath) {
+		route, answered := s.probePreviewURL(probeURL, cookies)
+		route.Path = previewPaths[i]
+		if !answered {
+			continue
+		}
+		if baselineAnswered && route.StatusCode == baseline.StatusCode && route.RedirectTo == baseline.RedirectTo {
+			log.Printf("Preview mode: %s answers like an unknown path (%s), ignoring it", probeURL, formatDiscoveredRoute(route))
+			continue
+		}
+		log.Printf("Preview mode: found %s (%s)", probeURL, formatDiscoveredRoute(route))
+		result.PreviewEndpoints = append(result.PreviewEndpoints, route)
+	}
+	result.PreviewModeEnabled = len(result.PreviewEndpoints) > 0
+	result.PreviewCookies = appendUnique(result.PreviewCookies, sortedSet(cookies)...)
+}
+
+// probePreviewURL requests probeURL and reports how it was answered. answered
+// is false for a 404 or when no response was received.
+func (s *Scanner) probePreviewURL(probeURL string, cookies map[string]bool) (route DiscoveredRoute, answered bool) {
+	reader, info, err := fetch.FetchWithInfo(s.fetcher, probeURL)
+	if err == nil {
+		reader.Close()
+	}
+	for _, name := range previewCookies(info.Headers) {
+		cookies[name] = true
+	}
+
+	route.StatusCode = info.StatusCode
+	if len(info.RedirectChain) > 1 {
+		route.RedirectTo = info.RedirectChain[1]
+	} else if info.StatusCode >= 300 && info.StatusCode < 400 && info.Headers != nil {
+		route.RedirectTo = info.Headers.Get("Location")
+	}
+	if err == nil && route.StatusCode == 0 {
+		route.StatusCode = http.StatusOK // Not every fetcher reports the status
+	}
+	switch route.StatusCode {
+	case 0:
+		log.Printf("Preview mode: no answer from %s: %v", probeURL, err)
+		return route, false
+	case http.StatusNotFound:
+		return route, false
+	}
+	return route, true
+}
+
+// previewCookies returns the names of the preview cookies set by a
+// response, in the order of previewCookieNames.
+func previewCookies(headers http.Header) []string {
+	var names []string
+	for _, name := range previewCookieNames {
+		for _, setCookie := range headers.Values("Set-Cookie") {
+			if strings.HasPrefix(strings.TrimSpace(setCookie), name+"=") {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	return names
+}
+
+// formatPreviewMode renders the preview routes found for text output, e.g.
+// "/api/preview (401), /api/draft (redirect to https://example.com/)".
+func formatPreviewMode(result *ScanResult) string {
+	if !result.PreviewModeEnabled {
+		return "no preview route found"
+	}
+	endpoints := make([]string, len(result.PreviewEndpoints))
+	for i, route := range result.PreviewEndpoints {
+		endpoints[i] = fmt.Sprintf("%s (%s)", route.Path, formatDiscoveredRoute(route))
+	}
+	return strings.Join(endpoints, ", ")
+}
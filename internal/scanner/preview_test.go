@@ -0,0 +1,91 @@
+package scanner
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigopv/nextr4y/internal/fetch"
+)
+
+func TestScanTarget_ProbePreview(t *testing.T) {
+	t.Parallel()
+
+	const target = "https://www.example.com/"
+	const page = `<html><body><script id="__NEXT_DATA__" type="application/json">{"buildId":"b1","props":{}}</script></body></html>`
+
+	tests := []struct {
+		name          string
+		statuses      map[string]int
+		catchAll      bool
+		wantEndpoints []DiscoveredRoute
+		wantCookies   []string
+	}{
+		{
+			name:          "preview and draft routes",
+			statuses:      map[string]int{target + "api/preview": 401, target + "api/draft": 405},
+			wantEndpoints: []DiscoveredRoute{{Path: "/api/preview", StatusCode: 401}, {Path: "/api/draft", StatusCode: 405}},
+			wantCookies:   []string{"__prerender_bypass"},
+		},
+		{
+			name:          "not served",
+			wantEndpoints: []DiscoveredRoute{},
+			wantCookies:   []string{"__prerender_bypass"},
+		},
+		{
+			name:          "every API path rejected",
+			statuses:      map[string]int{target + "api/preview": 403},
+			catchAll:      true,
+			wantEndpoints: []DiscoveredRoute{},
+			wantCookies:   []string{"__prerender_bypass"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fetcher := &catchAllStatusFetcher{
+				statusFetcher: statusFetcher{
+					stubFetcher: stubFetcher{pages: map[string]string{target: page}},
+					statuses:    tt.statuses,
+					headers: map[string]http.Header{
+						target: {"Set-Cookie": {"__prerender_bypass=abc; Path=/; HttpOnly", "session=1"}},
+					},
+				},
+				catchAll: tt.catchAll,
+			}
+			scr := NewScannerWithOptions(fetcher, stubDetector{}, Options{ProbePreview: true})
+
+			result, _ := scr.ScanTarget(target)
+
+			require.Equal(t, tt.wantEndpoints, result.PreviewEndpoints)
+			require.Equal(t, len(tt.wantEndpoints) > 0, result.PreviewModeEnabled)
+			require.Equal(t, tt.wantCookies, result.PreviewCookies)
+		})
+	}
+}
+
+// catchAllStatusFetcher answers every /api/ path without an explicit status
+// with a 403 when catchAll is set, like a WAF guarding the API.
+type catchAllStatusFetcher struct {
+	statusFetcher
+	catchAll bool
+}
+
+func (f *catchAllStatusFetcher) Fetch(targetURL string) (io.ReadCloser, string, error) {
+	body, info, err := f.FetchWithInfo(targetURL)
+	return body, info.FinalURL, err
+}
+
+func (f *catchAllStatusFetcher) FetchWithInfo(targetURL string) (io.ReadCloser, *fetch.ResponseInfo, error) {
+	if _, ok := f.statuses[targetURL]; !ok && f.catchAll && strings.Contains(targetURL, "/api/") {
+		info := &fetch.ResponseInfo{FinalURL: targetURL, RedirectChain: []string{targetURL}, StatusCode: 403}
+		return nil, info, fmt.Errorf("stub: bad status code fetching %s: 403", targetURL)
+	}
+	return f.statusFetcher.FetchWithInfo(targetURL)
+}
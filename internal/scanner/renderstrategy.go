@@ -0,0 +1,65 @@
+package scanner
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Render strategies reported in ScanResult.RenderStrategy.
+const (
+	RenderStrategySSR     = "ssr"
+	RenderStrategySSG     = "ssg"
+	RenderStrategyISR     = "isr"
+	RenderStrategyUnknown = "unknown"
+)
+
+// staticMaxAge is the s-maxage Next.js sends for pages generated at build
+// time that are never revalidated (one year). A shorter s-maxage on a
+// getStaticProps page is its revalidate period.
+const staticMaxAge = 31536000
+
+// detectRenderStrategy infers how the scanned page was rendered from the
+// data-fetching flags of its __NEXT_DATA__:
+//   - "gssp" (getServerSideProps), or "gip"/"appGip" (getInitialProps on the
+//     page or in _app) are rendered on every request;
+//   - "gsp" (getStaticProps) pages are ISR when the Cache-Control header
+//     carries a revalidate period, SSG otherwise;
+//   - "autoExport" and "nextExport" pages are static HTML.
+//
+// It returns RenderStrategyUnknown without __NEXT_DATA__ (e.g. App Router
+// pages) or when no flag is set. The ISR check is a heuristic: hosts that
+// rewrite Cache-Control make ISR pages look like SSG ones.
+func detectRenderStrategy(nextData *NextData, headers http.Header) string {
+	switch {
+	case nextData == nil:
+		return RenderStrategyUnknown
+	case nextData.GSSP, nextData.GIP, nextData.AppGIP:
+		return RenderStrategySSR
+	case nextData.GSP:
+		if maxAge, ok := sharedMaxAge(headers.Get("Cache-Control")); ok && maxAge < staticMaxAge {
+			return RenderStrategyISR
+		}
+		return RenderStrategySSG
+	case nextData.AutoExport, nextData.NextExport:
+		return RenderStrategySSG
+	default:
+		return RenderStrategyUnknown
+	}
+}
+
+// sharedMaxAge returns the s-maxage directive of a Cache-Control header.
+func sharedMaxAge(cacheControl string) (int, bool) {
+	for _, directive := range strings.Split(cacheControl, ",") {
+		name, value, found := strings.Cut(strings.TrimSpace(directive), "=")
+		if !found || !strings.EqualFold(name, "s-maxage") {
+			continue
+		}
+		seconds, err := strconv.Atoi(strings.Trim(value, `"`))
+		if err != nil {
+			return 0, false
+		}
+		return seconds, true
+	}
+	return 0, false
+}
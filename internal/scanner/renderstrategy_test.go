@@ -0,0 +1,40 @@
+package scanner
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectRenderStrategy(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name         string
+		nextData     *NextData
+		cacheControl string
+		want         string
+	}{
+		{name: "no next data", want: RenderStrategyUnknown},
+		{name: "getServerSideProps", nextData: &NextData{GSSP: true}, cacheControl: "private, no-cache", want: RenderStrategySSR},
+		{name: "getInitialProps in _app", nextData: &NextData{AppGIP: true}, want: RenderStrategySSR},
+		{name: "getStaticProps", nextData: &NextData{GSP: true}, cacheControl: "s-maxage=31536000, stale-while-revalidate", want: RenderStrategySSG},
+		{name: "getStaticProps with revalidate", nextData: &NextData{GSP: true}, cacheControl: "s-maxage=60, stale-while-revalidate", want: RenderStrategyISR},
+		{name: "getStaticProps behind a CDN", nextData: &NextData{GSP: true}, cacheControl: "public, max-age=0, must-revalidate", want: RenderStrategySSG},
+		{name: "automatic static optimization", nextData: &NextData{AutoExport: true}, want: RenderStrategySSG},
+		{name: "no flags", nextData: &NextData{BuildID: "b1"}, want: RenderStrategyUnknown},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			headers := http.Header{}
+			if tc.cacheControl != "" {
+				headers.Set("Cache-Control", tc.cacheControl)
+			}
+			require.Equal(t, tc.want, detectRenderStrategy(tc.nextData, headers))
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package scanner
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Route types reported in RouteInfo.Type.
+const (
+	RouteTypePage   = "page"
+	RouteTypeAPI    = "api"
+	RouteTypeLayout = "layout"
+)
+
+// RouteInfo describes a single route discovered in the build manifest.
+type RouteInfo struct {
+	Path    string   // Route path as listed in the manifest (e.g. "/blog/[slug]")
+	Type    string   // One of RouteTypePage, RouteTypeAPI or RouteTypeLayout
+	Dynamic bool     // True if the path contains a [param] segment
+	Assets  []string // Asset URLs loaded by the route
+}
+
+var dynamicSegmentRegex = regexp.MustCompile(`\[[^/\]]+\]`)
+
+// classifyRoute infers the route type from its path.
+// Paths under /api are API routes, paths whose last segment is "layout" are
+// App Router layouts, and everything else is a page.
+func classifyRoute(routePath string) string {
+	if routePath == "/api" || strings.HasPrefix(routePath, "/api/") {
+		return RouteTypeAPI
+	}
+	if routePath == "/layout" || strings.HasSuffix(routePath, "/layout") {
+		return RouteTypeLayout
+	}
+	return RouteTypePage
+}
+
+// specialPages lists the build manifest entries that are framework pages
+// rather than user-facing routes:
+//   - "/_app" and "/_document" wrap every page;
+//   - "/_error", "/404", "/500" and the App Router's "/_not-found" are only
+//     rendered in place of another page when it fails or does not exist.
+//
+// Other underscore-prefixed paths (e.g. "/_sites/[site]") are regular routes.
+var specialPages = map[string]bool{
+	"/_app":       true,
+	"/_document":  true,
+	"/_error":     true,
+	"/_not-found": true,
+	"/404":        true,
+	"/500":        true,
+}
+
+// isSpecialPage reports whether a build manifest entry is listed in specialPages.
+func isSpecialPage(routePath string) bool {
+	return specialPages[routePath]
+}
+
+// splitSpecialPages moves the special pages (see isSpecialPage) out of routes.
+// The returned special map is nil if there are none.
+func splitSpecialPages(routes map[string][]string) (pages map[string][]string, special map[string][]string) {
+	pages = make(map[string][]string, len(routes))
+	for routePath, assets := range routes {
+		if !isSpecialPage(routePath) {
+			pages[routePath] = assets
+			continue
+		}
+		if special == nil {
+			special = make(map[string][]string)
+		}
+		special[routePath] = assets
+	}
+	return pages, special
+}
+
+// buildRouteInfos converts the route→assets map into a list of RouteInfo sorted by path.
+func buildRouteInfos(routes map[string][]string) []RouteInfo {
+	infos := make([]RouteInfo, 0, len(routes))
+	for routePath, assets := range routes {
+		infos = append(infos, RouteInfo{
+			Path:    routePath,
+			Type:    classifyRoute(routePath),
+			Dynamic: dynamicSegmentRegex.MatchString(routePath),
+			Assets:  assets,
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Path < infos[j].Path })
+	return infos
+}
+
+// maxResolvedExamples bounds the example URLs recorded per dynamic route.
+const maxResolvedExamples = 5
+
+// routeMatcher matches concrete paths against a dynamic route template.
+type routeMatcher struct {
+	template   string
+	regex      *regexp.Regexp
+	catchAlls  int
+	dynamics   int
+	staticSegs int
+}
+
+// newRouteMatcher compiles a route template such as "/blog/[slug]" or
+// "/docs/[...path]" into a matcher. "[param]" matches one segment,
+// "[...param]" one or more and "[[...param]]" zero or more.
+func newRouteMatcher(template string) *routeMatcher {
+	m := &routeMatcher{template: template}
+	var pattern strings.Builder
+	pattern.WriteString("^")
+	for _, segment := range strings.Split(strings.Trim(template, "/"), "/") {
+		switch {
+		case segment == "":
+			// Root route
+		case strings.HasPrefix(segment, "[[...") && strings.HasSuffix(segment, "]]"):
+			pattern.WriteString("(?:/.+)?")
+			m.catchAlls++
+		case strings.HasPrefix(segment, "[...") && strings.HasSuffix(segment, "]"):
+			pattern.WriteString("/.+")
+			m.catchAlls++
+		case strings.HasPrefix(segment, "[") && strings.HasSuffix(segment, "]"):
+			pattern.WriteString("/[^/]+")
+			m.dynamics++
+		default:
+			pattern.WriteString("/" + regexp.QuoteMeta(segment))
+			m.staticSegs++
+		}
+	}
+	pattern.WriteString("$")
+	m.regex = regexp.MustCompile(pattern.String())
+	return m
+}
+
+// moreSpecific reports whether m should win over other when both match,
+// following Next.js precedence: static segments over dynamic ones, and
+// dynamic segments over catch-alls.
+func (m *routeMatcher) moreSpecific(other *routeMatcher) bool {
+	if m.catchAlls != other.catchAlls {
+		return m.catchAlls < other.catchAlls
+	}
+	if m.dynamics != other.dynamics {
+		return m.dynamics < other.dynamics
+	}
+	if m.staticSegs != other.staticSegs {
+		return m.staticSegs > other.staticSegs
+	}
+	return m.template < other.template
+}
+
+// resolveDynamicRoutes maps each dynamic route template to up to
+// maxResolvedExamples concrete URLs taken from the sitemap. Each URL is
+// assigned to the most specific matching template, and URLs served by a
+// static route or outside of the base path are skipped. The base path and
+// locale prefixes are stripped before matching.
+func resolveDynamicRoutes(routes map[string][]string, sitemapURLs []string, basePath string, localePrefixes []string) map[string][]string {
+	var matchers []*routeMatcher
+	for template := range routes {
+		if dynamicSegmentRegex.MatchString(template) {
+			matchers = append(matchers, newRouteMatcher(template))
+		}
+	}
+	if len(matchers) == 0 || len(sitemapURLs) == 0 {
+		return nil
+	}
+	sort.Slice(matchers, func(i, j int) bool { return matchers[i].moreSpecific(matchers[j]) })
+
+	resolved := make(map[string][]string)
+	for _, sitemapURL := range sitemapURLs {
+		parsed, err := url.Parse(sitemapURL)
+		if err != nil {
+			continue
+		}
+		routePath, ok := appRoutePath(parsed.Path, basePath, localePrefixes)
+		if !ok {
+			continue
+		}
+		if routePath != "/" {
+			routePath = strings.TrimSuffix(routePath, "/")
+		}
+		if _, isStatic := routes[routePath]; isStatic {
+			continue
+		}
+		for _, m := range matchers {
+			if m.regex.MatchString(routePath) {
+				if len(resolved[m.template]) < maxResolvedExamples {
+					resolved[m.template] = append(resolved[m.template], sitemapURL)
+				}
+				break
+			}
+		}
+	}
+	if len(resolved) == 0 {
+		return nil
+	}
+	return resolved
+}
+
+// appRoutePath returns the route path of a URL path of the app, without the
+// base path ("/docs") nor the locale prefix ("/fr") it is served under. ok
+// is false for a path outside of the base path, not served by the app.
+func appRoutePath(urlPath, basePath string, localePrefixes []string) (routePath string, ok bool) {
+	if basePath != "" {
+		if urlPath != basePath && !strings.HasPrefix(urlPath, basePath+"/") {
+			return "", false
+		}
+		urlPath = strings.TrimPrefix(urlPath, basePath)
+	}
+	return stripLocalePrefix(urlPath, localePrefixes), true
+}
+
+// stripLocalePrefix removes a leading locale prefix ("/fr") from a path.
+func stripLocalePrefix(routePath string, localePrefixes []string) string {
+	for _, prefix := range localePrefixes {
+		if routePath == prefix {
+			return "/"
+		}
+		if strings.HasPrefix(routePath, prefix+"/") {
+			return strings.TrimPrefix(routePath, prefix)
+		}
+	}
+	if routePath == "" {
+		return "/"
+	}
+	return routePath
+}
+
+// sortedKeys returns the keys of a route map in lexical order.
+func sortedKeys(routes map[string][]string) []string {
+	keys := make([]string, 0, len(routes))
+	for k := range routes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formatRouteList renders the route paths one per line, sorted, for the
+// "routes" output format (e.g. to build a fuzzing wordlist).
+func formatRouteList(result *ScanResult) string {
+	var sb strings.Builder
+	for _, routePath := range sortedKeys(result.Routes) {
+		sb.WriteString(routePath)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,132 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildRouteInfos(t *testing.T) {
+	t.Parallel()
+
+	routes := map[string][]string{
+		"/":                 {"a.js"},
+		"/api/users/[id]":   {},
+		"/blog/[...slug]":   {"b.js"},
+		"/dashboard/layout": {"c.js"},
+		"/apiary":           {"d.js"},
+	}
+
+	infos := buildRouteInfos(routes)
+
+	require.Equal(t, []RouteInfo{
+		{Path: "/", Type: RouteTypePage, Dynamic: false, Assets: []string{"a.js"}},
+		{Path: "/api/users/[id]", Type: RouteTypeAPI, Dynamic: true, Assets: []string{}},
+		{Path: "/apiary", Type: RouteTypePage, Dynamic: false, Assets: []string{"d.js"}},
+		{Path: "/blog/[...slug]", Type: RouteTypePage, Dynamic: true, Assets: []string{"b.js"}},
+		{Path: "/dashboard/layout", Type: RouteTypeLayout, Dynamic: false, Assets: []string{"c.js"}},
+	}, infos)
+}
+
+func TestResolveDynamicRoutes(t *testing.T) {
+	t.Parallel()
+
+	routes := map[string][]string{
+		"/":                     {},
+		"/about":                {},
+		"/blog/[slug]":          {},
+		"/blog/featured":        {},
+		"/docs/[...path]":       {},
+		"/shop/[[...filters]]":  {},
+		"/[category]/[product]": {},
+	}
+	sitemap := []string{
+		"https://example.com/",
+		"https://example.com/about/",
+		"https://example.com/blog/featured",
+		"https://example.com/blog/hello-world",
+		"https://example.com/fr/blog/bonjour",
+		"https://example.com/docs/getting-started/install",
+		"https://example.com/shop",
+		"https://example.com/shop/red/large",
+		"https://example.com/shoes/sneaker-1",
+		"https://example.com/a/b/c",
+	}
+
+	resolved := resolveDynamicRoutes(routes, sitemap, "", []string{"/fr"})
+
+	require.Equal(t, map[string][]string{
+		"/blog/[slug]":          {"https://example.com/blog/hello-world", "https://example.com/fr/blog/bonjour"},
+		"/docs/[...path]":       {"https://example.com/docs/getting-started/install"},
+		"/shop/[[...filters]]":  {"https://example.com/shop", "https://example.com/shop/red/large"},
+		"/[category]/[product]": {"https://example.com/shoes/sneaker-1"},
+	}, resolved)
+}
+
+func TestResolveDynamicRoutes_BasePath(t *testing.T) {
+	t.Parallel()
+
+	routes := map[string][]string{
+		"/":             {},
+		"/blog/[slug]":  {},
+		"/blog/feature": {},
+	}
+	sitemap := []string{
+		"https://example.com/docs",
+		"https://example.com/docs/blog/feature",
+		"https://example.com/docs/blog/hello-world",
+		"https://example.com/docs/fr/blog/bonjour",
+		"https://example.com/blog/outside-the-app",
+	}
+
+	resolved := resolveDynamicRoutes(routes, sitemap, "/docs", []string{"/fr"})
+
+	require.Equal(t, map[string][]string{
+		"/blog/[slug]": {"https://example.com/docs/blog/hello-world", "https://example.com/docs/fr/blog/bonjour"},
+	}, resolved)
+}
+
+func TestSplitSpecialPages(t *testing.T) {
+	t.Parallel()
+
+	routes := map[string][]string{
+		"/":              {"index.js"},
+		"/_app":          {"app.js"},
+		"/_error":        {"error.js"},
+		"/404":           {"404.js"},
+		"/_not-found":    {"not-found.js"},
+		"/_sites/[site]": {"site.js"},
+		"/blog/404":      {"blog-404.js"},
+	}
+
+	pages, special := splitSpecialPages(routes)
+
+	require.Equal(t, []string{"/", "/_sites/[site]", "/blog/404"}, sortedKeys(pages))
+	require.Equal(t, []string{"/404", "/_app", "/_error", "/_not-found"}, sortedKeys(special))
+	require.Equal(t, []string{"app.js"}, special["/_app"])
+
+	_, special = splitSpecialPages(map[string][]string{"/": {"index.js"}})
+	require.Nil(t, special)
+}
+
+func TestScanTarget_SeparatesSpecialPages(t *testing.T) {
+	t.Parallel()
+
+	const target = "https://www.example.com/"
+	fetcher := &stubFetcher{pages: map[string]string{
+		target: `<html><body><script id="__NEXT_DATA__" type="application/json">{"buildId":"b1","props":{}}</script></body></html>`,
+		target + "_next/static/b1/_buildManifest.js": sampleManifestJS,
+	}}
+	scr := NewScannerWithOptions(fetcher, stubDetector{}, Options{})
+
+	result, err := scr.ScanTarget(target)
+
+	require.NoError(t, err)
+	require.Equal(t, []string{"/"}, sortedKeys(result.Routes))
+	require.Equal(t, []string{"/_error"}, sortedKeys(result.SpecialPages))
+	require.True(t, result.AllAssets["https://www.example.com/_next/static/chunks/pages/_error-def.js"])
+
+	report, err := RenderMarkdown(result)
+	require.NoError(t, err)
+	require.Contains(t, report, "Special pages: `/_error`")
+}
@@ -0,0 +1,92 @@
+package scanner
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Runtimes reported in ScanResult.Runtime.
+const (
+	RuntimeEdge    = "edge"
+	RuntimeNode    = "node"
+	RuntimeUnknown = "unknown"
+)
+
+// middlewareManifestSignal is the runtime signal recorded when the app's
+// _middlewareManifest.js was found.
+const middlewareManifestSignal = "_middlewareManifest.js found (middleware)"
+
+// foundMiddlewareManifest reports whether signals, as returned by
+// detectRuntime, include middlewareManifestSignal.
+func foundMiddlewareManifest(signals []string) bool {
+	for _, signal := range signals {
+		if signal == middlewareManifestSignal {
+			return true
+		}
+	}
+	return false
+}
+
+// detectRuntime infers whether the target's server code runs on the Edge or
+// the Node.js runtime. Signals are checked from most to least specific:
+//   - an x-edge-runtime header marks an Edge response;
+//   - "X-Powered-By: Next.js" is only sent by the Node.js server;
+//   - middleware (x-middleware-* headers or a middleware manifest) runs on Edge.
+//
+// It returns RuntimeUnknown when no signal is present, along with a
+// human-readable description of every signal that was found.
+func detectRuntime(headers http.Header, middlewareManifestFound bool) (string, []string) {
+	var signals []string
+	var edgeHeader, poweredByNext, middleware bool
+
+	if headers.Get("X-Edge-Runtime") != "" {
+		edgeHeader = true
+		signals = append(signals, "x-edge-runtime header")
+	}
+	if strings.Contains(strings.ToLower(headers.Get("X-Powered-By")), "next.js") {
+		poweredByNext = true
+		signals = append(signals, "x-powered-by: Next.js header (Node.js server)")
+	}
+	var middlewareHeaders []string
+	for name := range headers {
+		if strings.HasPrefix(strings.ToLower(name), "x-middleware-") {
+			middlewareHeaders = append(middlewareHeaders, strings.ToLower(name))
+		}
+	}
+	sort.Strings(middlewareHeaders)
+	for _, name := range middlewareHeaders {
+		middleware = true
+		signals = append(signals, fmt.Sprintf("%s header (middleware)", name))
+	}
+	if matchedPath := headers.Get("X-Matched-Path"); matchedPath != "" {
+		signals = append(signals, fmt.Sprintf("x-matched-path: %s header", matchedPath))
+	}
+	if middlewareManifestFound {
+		middleware = true
+		signals = append(signals, middlewareManifestSignal)
+	}
+
+	switch {
+	case edgeHeader:
+		return RuntimeEdge, signals
+	case poweredByNext:
+		return RuntimeNode, signals
+	case middleware:
+		return RuntimeEdge, signals
+	default:
+		return RuntimeUnknown, signals
+	}
+}
+
+// formatRuntime renders the runtime with its signals for text output.
+func formatRuntime(result *ScanResult) string {
+	if result.Runtime == "" {
+		return RuntimeUnknown
+	}
+	if len(result.RuntimeSignals) == 0 {
+		return result.Runtime
+	}
+	return fmt.Sprintf("%s (%s)", result.Runtime, strings.Join(result.RuntimeSignals, ", "))
+}
@@ -0,0 +1,34 @@
+package scanner
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectRuntime(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name               string
+		headers            http.Header
+		middlewareManifest bool
+		wantRuntime        string
+		wantSignals        int
+	}{
+		{name: "No signals", headers: http.Header{}, wantRuntime: RuntimeUnknown},
+		{name: "Nil headers", headers: nil, wantRuntime: RuntimeUnknown},
+		{name: "Edge runtime header", headers: http.Header{"X-Edge-Runtime": {"1"}}, wantRuntime: RuntimeEdge, wantSignals: 1},
+		{name: "Node server", headers: http.Header{"X-Powered-By": {"Next.js"}}, wantRuntime: RuntimeNode, wantSignals: 1},
+		{name: "Middleware headers", headers: http.Header{"X-Middleware-Rewrite": {"/en"}, "X-Matched-Path": {"/[locale]"}}, wantRuntime: RuntimeEdge, wantSignals: 2},
+		{name: "Middleware manifest", headers: http.Header{}, middlewareManifest: true, wantRuntime: RuntimeEdge, wantSignals: 1},
+		{name: "Node server with middleware", headers: http.Header{"X-Powered-By": {"Next.js"}}, middlewareManifest: true, wantRuntime: RuntimeNode, wantSignals: 2},
+	}
+
+	for _, tc := range testCases {
+		runtime, signals := detectRuntime(tc.headers, tc.middlewareManifest)
+		require.Equal(t, tc.wantRuntime, runtime, tc.name)
+		require.Len(t, signals, tc.wantSignals, tc.name)
+	}
+}
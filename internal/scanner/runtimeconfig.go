@@ -0,0 +1,37 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// formatRuntimeConfig renders the public runtime config of a page for text
+// output, one "key: value" line per top-level key, sorted. Nested values are
+// rendered as JSON.
+func formatRuntimeConfig(config map[string]interface{}) []string {
+	keys := make([]string, 0, len(config))
+	for key := range config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	lines := make([]string, 0, len(keys))
+	for _, key := range keys {
+		lines = append(lines, fmt.Sprintf("%s: %s", key, formatConfigValue(config[key])))
+	}
+	return lines
+}
+
+// formatConfigValue renders a runtime config value: strings as-is, anything
+// else as JSON.
+func formatConfigValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(encoded)
+}
@@ -0,0 +1,42 @@
+package scanner
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanTarget_RuntimeConfig(t *testing.T) {
+	t.Parallel()
+
+	html, err := os.ReadFile("testdata/runtime_config.html")
+	require.NoError(t, err)
+
+	const target = "https://shop.example.com/"
+	fetcher := &stubFetcher{pages: map[string]string{target: string(html)}}
+	scr := NewScannerWithOptions(fetcher, stubDetector{}, Options{SkipRoutes: true})
+
+	result, err := scr.ScanTarget(target)
+
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"apiBaseUrl":    "https://api.staging.acme.example/v2",
+		"environment":   "staging",
+		"features":      map[string]interface{}{"newCheckout": true, "reviews": false},
+		"sentryRelease": "acme-web@4.12.0",
+	}, result.RuntimeConfig)
+	require.Equal(t, []string{
+		"apiBaseUrl: https://api.staging.acme.example/v2",
+		"environment: staging",
+		`features: {"newCheckout":true,"reviews":false}`,
+		"sentryRelease: acme-web@4.12.0",
+	}, formatRuntimeConfig(result.RuntimeConfig))
+
+	staticExportHTML, err := os.ReadFile("testdata/static_export.html")
+	require.NoError(t, err)
+	fetcher = &stubFetcher{pages: map[string]string{target: string(staticExportHTML)}}
+	result, err = NewScannerWithOptions(fetcher, stubDetector{}, Options{SkipRoutes: true}).ScanTarget(target)
+	require.NoError(t, err)
+	require.Nil(t, result.RuntimeConfig)
+}
@@ -4,18 +4,22 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"html"
 	"io"
 	"log"
+	"log/slog"
+	"mime"
+	"net/http"
 	"net/url"
 	"os"
 	"path"
 	"regexp"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/dop251/goja"
-	"github.com/fatih/color"
 
 	"github.com/rodrigopv/nextr4y/internal/fetch"
 	"github.com/rodrigopv/nextr4y/internal/versiondetect"
@@ -23,41 +27,411 @@ import (
 
 // Structure to hold extracted Next.js config data
 type NextData struct {
-	BuildID     string                 `json:"buildId"`
-	AssetPrefix string                 `json:"assetPrefix"` 
-	Props       map[string]interface{} `json:"props"`      
+	BuildID       string                 `json:"buildId"`
+	AssetPrefix   string                 `json:"assetPrefix"`
+	Props         map[string]interface{} `json:"props"`
+	Locale        string                 `json:"locale"`
+	Locales       []string               `json:"locales"`
+	DefaultLocale string                 `json:"defaultLocale"`
+	NextExport    bool                   `json:"nextExport"`    // Set on pages built with `next export` / output: 'export'
+	GSSP          bool                   `json:"gssp"`          // The page uses getServerSideProps
+	GSP           bool                   `json:"gsp"`           // The page uses getStaticProps
+	GIP           bool                   `json:"gip"`           // The page uses getInitialProps
+	AppGIP        bool                   `json:"appGip"`        // The custom _app uses getInitialProps
+	AutoExport    bool                   `json:"autoExport"`    // The page was statically optimized (no data fetching)
+	IsFallback    bool                   `json:"isFallback"`    // The page is the fallback of a dynamic getStaticProps route
+	RuntimeConfig map[string]interface{} `json:"runtimeConfig"` // publicRuntimeConfig of next.config.js
+	BasePath      string                 `json:"basePath"`      // Subpath the app is served under ("/docs"), rarely serialized
 }
 
+// SchemaVersion is the version of the JSON structure of ScanResult, recorded
+// in every result. The minor version is bumped when fields are added, which
+// existing consumers can ignore; the major version when fields are removed,
+// renamed or change type.
+const SchemaVersion = "1.2"
+
 // Structure to hold the final results
 type ScanResult struct {
-	BaseURL         string
-	AssetBaseURL    string 
-	IsNextJS        bool
-	BuildID         string
-	AssetPrefix     string
-	Routes          map[string][]string 
-	AllAssets       map[string]bool     
-	ManifestFound   bool
-	ManifestExecOK  bool
-	ExecutionError  error
-	NextDataJSONRaw string 
-	DetectedNextVersion string
-	DetectedReactVersion string
+	SchemaVersion          string // Version of this structure, see the SchemaVersion constant
+	BaseURL                string
+	AssetBaseURL           string
+	AssetHost              string   // Host the assets are served from, only set when it is not the page's host
+	AssetHostBlocked       bool     // Every request to AssetHost failed, e.g. because it blocks the scanner's TLS profiles
+	AssetHosts             []string // Distinct hosts serving AllAssets and the page scripts, sorted
+	IsNextJS               bool
+	DeploymentType         string // DeploymentTypeStaticExport for static exports, empty otherwise
+	RenderStrategy         string // How the scanned page was rendered: "ssr", "ssg", "isr" or "unknown", see detectRenderStrategy
+	BuildID                string
+	BuildIDType            string // Heuristic guess of how BuildID was generated: "sha", "nanoid", "timestamp" or "custom"
+	BuildIDDate            string // Approximate build date (RFC 3339) read from a timestamp BuildID, a heuristic
+	AssetPrefix            string
+	BasePath               string // Subpath the app is served under (next.config basePath, e.g. "/docs"), empty at the root
+	Routes                 map[string][]string
+	SpecialPages           map[string][]string // Framework pages kept out of Routes ("/_app", "/_error", "/404", ...), see specialPages
+	RouteDetails           []RouteInfo
+	AllAssets              map[string]bool
+	ManifestFound          bool
+	ManifestExecOK         bool
+	ExecutionError         error
+	NextDataJSONRaw        string
+	DetectedNextVersion    string
+	NextVersionFallback    bool // DetectedNextVersion is a last-resort guess from the asset scan, not tied to Next.js
+	DetectedReactVersion   string
+	ReactReconcilerVersion string
+	VersionCandidates      []versiondetect.Candidate // Every version considered by the detector, to audit the choice, only set with Debug enabled
+	Locale                 string
+	Locales                []string
+	DefaultLocale          string
+	LocalePrefixes         []string               // Prefixes ("/fr") under which localized copies of Routes exist
+	RuntimeConfig          map[string]interface{} // publicRuntimeConfig exposed in __NEXT_DATA__ (API URLs, feature flags, environment), nil if empty
+	RedirectChain          []string               // URLs visited by the initial fetch, only set if it was redirected
+	AssetHashes            map[string]string      // SHA-256 (hex) of each asset in AllAssets, only set with hashing enabled
+	AssetHashErrors        map[string]string      // Assets that could not be hashed, with the reason
+	AssetErrors            map[string]string      // Error of every failed asset fetch (version detection, hashing), only set with IncludeErrors enabled
+	RobotsDisallow         []string               // Disallow paths from /robots.txt, only set with discovery enabled
+	SitemapURLs            []string               // Page URLs listed in the site's sitemaps, only set with discovery enabled
+	ResolvedRoutes         map[string][]string    // Example sitemap URLs for each dynamic route (e.g. "/blog/[slug]")
+	DataRoutes             map[string]bool        // Probed routes, true if their _next/data JSON is served, only set with ProbeData enabled
+	ImageOptimizer         string                 // Whether the /_next/image optimizer is served: "enabled", "disabled" or "unknown", only set with ProbeImage enabled
+	ImageOptimizerExternal bool                   // The image optimizer fetched an image from an external host (SSRF risk), only set with ProbeImage enabled
+	PreviewModeEnabled     bool                   // A Preview/Draft Mode route answered, only set with ProbePreview enabled
+	PreviewEndpoints       []DiscoveredRoute      // Preview routes ("/api/preview", "/api/draft", ...) answered with anything but a 404, only set with ProbePreview enabled
+	PreviewCookies         []string               // Preview Mode cookies ("__prerender_bypass", "__next_preview_data") set by the page or the preview routes
+	FaviconFound           bool                   // /favicon.ico is served, only set with Metadata enabled
+	WebManifestURL         string                 // URL of the PWA web app manifest, only set with Metadata enabled
+	AppName                string                 // "name" (or "short_name") of the web app manifest
+	ThemeColor             string                 // "theme_color" of the web app manifest
+	DiscoveredRoutes       []DiscoveredRoute      // Wordlist paths answered with a 200 or a redirect, only set with Bruteforce enabled
+	PlannedRequests        []string               // URLs the scan would request, starting with the page, only set with DryRun enabled
+	Runtime                string                 // Server runtime: "edge", "node" or "unknown"
+	RuntimeSignals         []string               // Evidence the Runtime was inferred from
+	BuildManifestRaw       map[string]interface{} // Full executed build manifest, only set with IncludeRaw enabled
+	FlightPayloadRaw       string                 // Concatenated App Router flight payload (self.__next_f), only set with IncludeRaw enabled
+	DiscoveredEndpoints    []string               // URLs and API/GraphQL paths found in fetched JS chunks, only set with ExtractEndpoints enabled
+	EmbeddedKeys           map[string][]string    // Third-party SDK keys (Sentry DSNs, analytics IDs) found in fetched JS chunks by service, only set with ExtractKeys enabled
+	Instrumentation        bool                   // OpenTelemetry or a Next.js instrumentation hook was found in fetched JS chunks, only set with DetectInstrumentation enabled
+	InstrumentationSignals []string               // Markers Instrumentation was inferred from ("opentelemetry-api", "vercel-otel", ...)
+	TelemetryEndpoints     []string               // OTLP/HTTP collector URLs (".../v1/traces") found in fetched JS chunks, only set with DetectInstrumentation enabled
+	StylingLibraries       []string               // CSS-in-JS and utility CSS libraries detected in the page and fetched assets
+	CSP                    *CSPInfo               // Content Security Policy of the target page, nil if none was sent
+	ResponseHeaders        map[string][]string    // Every header of the target page response, only set with IncludeHeaders enabled. Every Set-Cookie value is kept, but the HTTP fetcher only reports the last value of other repeated headers
+	SecurityHeaders        map[string]string      // Recommended security headers sent with the target page (Strict-Transport-Security, X-Frame-Options...) by name, nil if the fetcher reported no headers
+	MissingSecurityHeaders []string               // Recommended security headers the target page was sent without
+	WebpackVersion         string                 // Webpack version ("5.90.3", or "5.x" from the runtime format), empty without a webpack runtime chunk (e.g. Turbopack)
+	ChunkCount             int                    // Number of distinct JS chunks fetched during the scan (version detection, hashing)
+	TotalJSBytes           int64                  // Total size of those chunks, a lower bound of the bundle size when not every chunk was fetched
+	HTMLFetchDuration      time.Duration          // Time taken by the initial page fetch, including redirects
+	TotalScanDuration      time.Duration          // Wall-clock duration of the whole scan
+	FetchCount             int                    // Number of requests made during the scan
+	FetchDuration          time.Duration          // Cumulative duration of those requests (parallel requests overlap)
+	ManifestExecDuration   time.Duration          // Time spent evaluating the build manifest JS (also set when it failed or timed out), zero if it was not executed
+	FromCache              bool                   // The build ID was unchanged and the manifest, routes, assets and versions were reused from the cache
+}
+
+// DecodeResult decodes a scan result from its JSON encoding. ExecutionError
+// is an error interface and cannot be decoded back, so it is discarded.
+func DecodeResult(data []byte) (*ScanResult, error) {
+	type plainResult ScanResult
+	var result ScanResult
+	aux := struct {
+		*plainResult
+		ExecutionError json.RawMessage
+	}{plainResult: (*plainResult)(&result)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Options holds the optional settings of a Scanner.
+type Options struct {
+	CustomBaseURL string // Custom base URL provided by CLI parameter
+
+	// RenderFetcher, when set, is used to re-fetch the initial page if the
+	// static HTML carries no Next.js signal (e.g. purely client-rendered apps).
+	// It should be a fetcher that executes JavaScript.
+	RenderFetcher fetch.Fetcher
+
+	// ManifestExecTimeout bounds the evaluation of the build manifest JS.
+	// Zero uses DefaultManifestExecTimeout.
+	ManifestExecTimeout time.Duration
+
+	// Scheme is prefixed to targets given without one ("example.com").
+	// SchemeHTTPS and SchemeHTTP force that scheme; the empty string (SchemeAuto)
+	// tries HTTPS first and falls back to HTTP if no response is received.
+	Scheme string
+
+	// HashAssets enables downloading every asset in AllAssets to record its
+	// SHA-256 in ScanResult.AssetHashes.
+	HashAssets bool
+
+	// HashConcurrency bounds the parallel downloads made by HashAssets.
+	// Zero uses DefaultHashConcurrency.
+	HashConcurrency int
+
+	// Discover fetches /robots.txt and the site's sitemaps after the scan to
+	// record server-declared paths in RobotsDisallow and SitemapURLs.
+	Discover bool
+
+	// IncludeRaw keeps the full executed build manifest in
+	// ScanResult.BuildManifestRaw, including the keys the route extractor skips,
+	// and the App Router flight payload in ScanResult.FlightPayloadRaw.
+	IncludeRaw bool
+
+	// ExtractEndpoints scans the JS chunks fetched for version detection (and
+	// hashing) for external URLs and API/GraphQL paths, recorded in
+	// ScanResult.DiscoveredEndpoints. No extra requests are made.
+	ExtractEndpoints bool
+
+	// ExtractKeys scans the same chunks for the keys and IDs of well-known
+	// SDKs (Sentry DSNs, Google Analytics and Tag Manager IDs, Segment write
+	// keys, PostHog and Stripe publishable keys), recorded unredacted in
+	// ScanResult.EmbeddedKeys by service.
+	ExtractKeys bool
+
+	// DetectInstrumentation scans the same chunks for OpenTelemetry SDKs and
+	// Next.js instrumentation hooks, recorded in ScanResult.Instrumentation
+	// and InstrumentationSignals, and for the OTLP collector endpoints they
+	// report to, recorded in ScanResult.TelemetryEndpoints.
+	DetectInstrumentation bool
+
+	// Metadata fetches /favicon.ico and the PWA web app manifest to record
+	// FaviconFound, AppName and ThemeColor.
+	Metadata bool
+
+	// ProbeData fetches the _next/data JSON of up to MaxDataProbes Pages
+	// Router routes to record in ScanResult.DataRoutes which ones are live.
+	ProbeData bool
+
+	// ProbeImage requests images through the /_next/image optimizer to record
+	// in ScanResult.ImageOptimizer whether it is served, and in
+	// ImageOptimizerExternal whether it fetches images from any host.
+	ProbeImage bool
+
+	// ProbePreview requests the conventional Preview and Draft Mode routes
+	// (/api/preview, /api/draft, ...) to record in ScanResult.PreviewEndpoints
+	// those that are served, and the preview cookies they set.
+	ProbePreview bool
+
+	// MaxDataProbes bounds the routes probed by ProbeData.
+	// Zero uses DefaultMaxDataProbes.
+	MaxDataProbes int
+
+	// Bruteforce probes the paths of Wordlist on the target origin and records
+	// those answered with a 200 or a redirect in ScanResult.DiscoveredRoutes.
+	Bruteforce bool
+
+	// Wordlist is the list of paths probed by Bruteforce.
+	// Empty uses DefaultWordlist.
+	Wordlist []string
+
+	// SkipVersions skips version detection, the slowest phase of a scan, for
+	// scans only after routes and the build ID. DetectedNextVersion and
+	// DetectedReactVersion are set to VersionSkipped. Since no chunks are
+	// downloaded for detection, ExtractEndpoints only sees hashed assets.
+	SkipVersions bool
+
+	// SkipRoutes skips the build manifest, so routes are not enumerated and
+	// versions are detected from the page's own scripts only. It is the fast,
+	// versions-only path for callers that don't need the route list.
+	SkipRoutes bool
+
+	// DryRun only fetches the page, then records in ScanResult.PlannedRequests
+	// the URLs the rest of the scan would request (manifest candidates, page
+	// scripts, and the files of the enabled optional phases) instead of
+	// fetching them. Versions are left empty.
+	DryRun bool
+
+	// IncludeErrors records in ScanResult.AssetErrors why each asset fetched
+	// for version detection or hashing failed (e.g. a 403 from a CDN), which
+	// explains "Unknown" versions.
+	IncludeErrors bool
+
+	// IncludeHeaders records every header of the target page response in
+	// ScanResult.ResponseHeaders.
+	IncludeHeaders bool
+
+	// Debug keeps details meant for troubleshooting detection in the
+	// result: every version the detector considered, in
+	// ScanResult.VersionCandidates.
+	Debug bool
+
+	// AssetHostOverride, when set, fetches the build manifest and the assets
+	// served from another host than the page from this host instead (e.g.
+	// "www.example.com" when "static.example.com" blocks the scanner). It is
+	// validated with NormalizeAssetHost.
+	AssetHostOverride string
+
+	// AssetPattern, when set, also treats the page scripts whose resolved URL
+	// matches it as Next.js chunks, besides those under "_next/static".
+	AssetPattern *regexp.Regexp
+
+	// Cache, when set, stores the result of every successful scan of a
+	// Next.js build, and a scan finding an already cached build ID returns
+	// the cached result right after fetching the page, without fetching the
+	// build manifest nor any asset. The optional phases are skipped as well,
+	// their results coming from the cached scan.
+	Cache ResultCache
+
+	// RefreshCache skips the Cache lookup, scanning the target fully, but
+	// still stores the result.
+	RefreshCache bool
+
+	// Progress, when set, receives updates during the build manifest fetch
+	// and asset hashing.
+	Progress ProgressFunc
+
+	// Events, when set, receives a ProgressEvent at each step of the scan:
+	// page and manifest fetched, every asset fetched for analysis, and the
+	// end of the scan.
+	Events EventFunc
 }
 
+// ProgressFunc receives progress updates for a long-running scan phase,
+// e.g. ("Hashing asset", 5, 40). It may be called from several goroutines.
+type ProgressFunc func(stage string, done, total int)
+
+// VersionSkipped is reported as the detected versions when Options.SkipVersions
+// is set, as opposed to "Unknown" for versions that were looked for but not found.
+const VersionSkipped = "Skipped"
+
+// Schemes accepted by Options.Scheme.
+const (
+	SchemeAuto  = ""
+	SchemeHTTPS = "https"
+	SchemeHTTP  = "http"
+)
+
+// NormalizeBaseURL validates a custom base URL and returns its canonical
+// form: an absolute http(s) URL with a lowercase scheme and host, and a
+// cleaned path ending in "/" so that relative asset paths resolve under it.
+func NormalizeBaseURL(rawURL string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", fmt.Errorf("invalid base URL '%s': %w", rawURL, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("invalid base URL '%s': must be an absolute http:// or https:// URL with a host", rawURL)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return "", fmt.Errorf("invalid base URL '%s': must not contain a query or fragment", rawURL)
+	}
+
+	u.Host = strings.ToLower(u.Host)
+	u.Path = path.Clean("/" + u.Path)
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+	u.RawPath = ""
+	return u.String(), nil
+}
+
+// ErrNextDataNotFound is reported when the page has no __NEXT_DATA__ script,
+// which is expected for App Router sites and for targets that don't use Next.js.
+var ErrNextDataNotFound = errors.New("__NEXT_DATA__ script tag not found")
+
+// ErrNotHTML is reported when the target page is served with a Content-Type
+// outside htmlContentTypes, e.g. a mistyped URL answered with JSON or an image.
+var ErrNotHTML = errors.New("target did not return HTML")
+
+// htmlContentTypes are the media types the target page is parsed as HTML for.
+var htmlContentTypes = []string{"text/html", "application/xhtml+xml"}
+
+// DefaultManifestExecTimeout is the default time allowed for evaluating a build manifest.
+const DefaultManifestExecTimeout = 5 * time.Second
+
 // Scanner encapsulates the dependencies and logic for scanning a Next.js site.
 type Scanner struct {
-	fetcher         fetch.Fetcher
-	versionDetector versiondetect.VersionDetector
-	customBaseURL   string // Custom base URL provided by CLI parameter
+	fetcher             fetch.Fetcher
+	versionDetector     versiondetect.VersionDetector
+	customBaseURL       string // Custom base URL provided by CLI parameter, normalized
+	customBaseURLErr    error  // Set if the custom base URL is invalid
+	assetHostOverride   string
+	assetHostErr        error // Set if the asset host override is invalid
+	renderFetcher       fetch.Fetcher
+	manifestExecTimeout time.Duration
+	scheme              string
+	hashAssets          bool
+	hashConcurrency     int
+	discover            bool
+	metadata            bool
+	includeRaw          bool
+	extractEndpoints    bool
+	extractKeys         bool
+	detectInstr         bool
+	assetPattern        *regexp.Regexp
+	probeData           bool
+	maxDataProbes       int
+	probeImage          bool
+	probePreview        bool
+	bruteforce          bool
+	wordlist            []string
+	skipVersions        bool
+	skipRoutes          bool
+	dryRun              bool
+	includeErrors       bool
+	includeHeaders      bool
+	debug               bool
+	cache               ResultCache
+	refreshCache        bool
+	progress            ProgressFunc
+	events              EventFunc
 }
 
 // NewScanner creates a new Scanner with the required dependencies.
 func NewScanner(fetcher fetch.Fetcher, detector versiondetect.VersionDetector, customBaseURL string) *Scanner {
+	return NewScannerWithOptions(fetcher, detector, Options{CustomBaseURL: customBaseURL})
+}
+
+// NewScannerWithOptions creates a new Scanner with the required dependencies and optional settings.
+func NewScannerWithOptions(fetcher fetch.Fetcher, detector versiondetect.VersionDetector, opts Options) *Scanner {
+	manifestExecTimeout := opts.ManifestExecTimeout
+	if manifestExecTimeout <= 0 {
+		manifestExecTimeout = DefaultManifestExecTimeout
+	}
+	customBaseURL, customBaseURLErr := opts.CustomBaseURL, error(nil)
+	if customBaseURL != "" {
+		customBaseURL, customBaseURLErr = NormalizeBaseURL(customBaseURL)
+	}
+	assetHostOverride, assetHostErr := opts.AssetHostOverride, error(nil)
+	if assetHostOverride != "" {
+		assetHostOverride, assetHostErr = NormalizeAssetHost(assetHostOverride)
+	}
 	return &Scanner{
-		fetcher:         fetcher,
-		versionDetector: detector,
-		customBaseURL:   customBaseURL,
+		fetcher:             fetcher,
+		versionDetector:     detector,
+		customBaseURL:       customBaseURL,
+		customBaseURLErr:    customBaseURLErr,
+		assetHostOverride:   assetHostOverride,
+		assetHostErr:        assetHostErr,
+		renderFetcher:       opts.RenderFetcher,
+		manifestExecTimeout: manifestExecTimeout,
+		scheme:              opts.Scheme,
+		hashAssets:          opts.HashAssets,
+		hashConcurrency:     opts.HashConcurrency,
+		discover:            opts.Discover,
+		metadata:            opts.Metadata,
+		includeRaw:          opts.IncludeRaw,
+		extractEndpoints:    opts.ExtractEndpoints,
+		extractKeys:         opts.ExtractKeys,
+		detectInstr:         opts.DetectInstrumentation,
+		assetPattern:        opts.AssetPattern,
+		probeData:           opts.ProbeData,
+		probeImage:          opts.ProbeImage,
+		probePreview:        opts.ProbePreview,
+		maxDataProbes:       opts.MaxDataProbes,
+		bruteforce:          opts.Bruteforce,
+		wordlist:            opts.Wordlist,
+		skipVersions:        opts.SkipVersions,
+		skipRoutes:          opts.SkipRoutes,
+		dryRun:              opts.DryRun,
+		includeErrors:       opts.IncludeErrors,
+		includeHeaders:      opts.IncludeHeaders,
+		debug:               opts.Debug,
+		cache:               opts.Cache,
+		refreshCache:        opts.RefreshCache,
+		progress:            opts.Progress,
+		events:              opts.Events,
 	}
 }
 
@@ -67,8 +441,9 @@ var manifestJSRegex = regexp.MustCompile(`self\.__BUILD_MANIFEST\s*=\s*(function
 var simpleVersionRegex = regexp.MustCompile(`["'](\d+\.\d+\.\d+[^"']*)["']`)
 
 // findInitialScriptURLs parses HTML content to find <script> tags pointing to Next.js JS chunks.
-// It resolves the URLs relative to the provided assetBaseURL.
-func findInitialScriptURLs(htmlContent string, assetBaseURL *url.URL) map[string]bool {
+// It resolves the URLs relative to the provided assetBaseURL. Scripts matching
+// assetPattern, when set, are kept as well (for chunks served from non-standard paths).
+func findInitialScriptURLs(htmlContent string, assetBaseURL *url.URL, assetPattern *regexp.Regexp) map[string]bool {
 	jsURLs := make(map[string]bool)
 	if assetBaseURL == nil {
 		log.Println("Warning: Cannot resolve initial script URLs without an asset base URL.")
@@ -87,17 +462,15 @@ func findInitialScriptURLs(htmlContent string, assetBaseURL *url.URL) map[string
 			return
 		}
 
-		if strings.Contains(src, "/_next/static/") {
-			srcURL, err := url.Parse(src)
-			if err != nil {
-				log.Printf("Warning: Could not parse script src '%s': %v", src, err)
-				return
-			}
-
-			if strings.HasSuffix(srcURL.Path, ".js") {
-				fullURL := assetBaseURL.ResolveReference(srcURL).String()
-				jsURLs[fullURL] = true
-			}
+		srcURL, err := url.Parse(src)
+		if err != nil {
+			log.Printf("Warning: Could not parse script src '%s': %v", src, err)
+			return
+		}
+		resolvedURL := assetBaseURL.ResolveReference(srcURL)
+		fullURL := normalizeAssetURL(resolvedURL)
+		if isNextJSChunk(resolvedURL) || (assetPattern != nil && assetPattern.MatchString(fullURL)) {
+			jsURLs[fullURL] = true
 		}
 	})
 
@@ -105,6 +478,193 @@ func findInitialScriptURLs(htmlContent string, assetBaseURL *url.URL) map[string
 	return jsURLs
 }
 
+// isNextJSChunk reports whether a resolved script URL points at a Next.js JS
+// chunk: "_next/static" appears anywhere in it (asset prefixes may move it to
+// another host or under a path) and its path ends in ".js", leaving out any
+// query string such as a "?v=" cache buster.
+func isNextJSChunk(u *url.URL) bool {
+	return strings.Contains(u.String(), "_next/static") && strings.HasSuffix(strings.ToLower(u.Path), ".js")
+}
+
+// resolveAssetBaseURL returns the URL the page's assets are served under,
+// from the final page URL, the custom base URL (validated and normalized by
+// NewScannerWithOptions, may be "") and the assetPrefix of __NEXT_DATA__:
+//
+//   - A prefix with a host ("https://cdn.example.com/app", or protocol
+//     relative "//cdn.example.com/app") is used as-is, taking the page's
+//     scheme when it has none.
+//   - A relative prefix ("/app") is a path from the root of the page's origin,
+//     regardless of the path of the page itself.
+//   - With a custom base URL, only the path of the prefix is appended to it,
+//     unless the custom base path already ends with it (e.g. a base of
+//     "https://mirror.example.com/app/" with a prefix of "/app").
+//   - Without a prefix nor a custom base URL, the basePath of an app served
+//     under a subpath ("/docs") is a path from the root of the page's origin.
+//
+// Without a prefix, a custom base URL nor a basePath, the page URL itself is
+// returned. Otherwise the returned path always ends in "/", so asset paths
+// resolve below it.
+func resolveAssetBaseURL(pageURL *url.URL, customBaseURL, assetPrefix, basePath string) url.URL {
+	var prefixURL *url.URL
+	if assetPrefix != "" {
+		if parsed, err := url.Parse(assetPrefix); err == nil && parsed.Host != "" {
+			prefixURL = parsed
+		}
+	}
+
+	var base url.URL
+	prefixPath := assetPrefix
+	switch {
+	case customBaseURL != "":
+		customURL, _ := url.Parse(customBaseURL)
+		base = *customURL
+		if prefixURL != nil {
+			prefixPath = prefixURL.Path
+		}
+		if trimmed := strings.Trim(prefixPath, "/"); trimmed != "" && strings.HasSuffix(strings.TrimSuffix(base.Path, "/"), "/"+trimmed) {
+			prefixPath = ""
+		}
+	case prefixURL != nil:
+		base = *prefixURL
+		if base.Scheme == "" {
+			base.Scheme = pageURL.Scheme
+		}
+		prefixPath = ""
+	case assetPrefix != "":
+		base = url.URL{Scheme: pageURL.Scheme, User: pageURL.User, Host: pageURL.Host, Path: "/"}
+	case basePath != "":
+		base = url.URL{Scheme: pageURL.Scheme, User: pageURL.User, Host: pageURL.Host, Path: "/"}
+		prefixPath = basePath
+	default:
+		return *pageURL
+	}
+
+	base.Path = path.Join("/", base.Path, prefixPath)
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path += "/"
+	}
+	base.RawPath = ""
+	base.RawQuery = ""
+	base.Fragment = ""
+	return base
+}
+
+// candidateManifestURLs returns the URLs at which the build manifest may be
+// found, in the order they should be tried. The first candidate is derived
+// from the resolved asset base URL; when a custom base URL is combined with
+// an asset prefix pointing at another host (either a full URL or a bare
+// "cdn.example.com/path" prefix), that host is added as a fallback.
+func candidateManifestURLs(assetBaseURL *url.URL, assetPrefix, buildID, customBaseURL string) []string {
+	// The asset base may already end in _next (from the asset prefix)
+	relativePath := path.Join("_next/static", buildID, "_buildManifest.js")
+	if strings.Contains(assetBaseURL.Path, "/_next/") || strings.HasSuffix(assetBaseURL.Path, "/_next") {
+		relativePath = path.Join("static", buildID, "_buildManifest.js")
+	}
+	candidates := []string{assetBaseURL.ResolveReference(&url.URL{Path: relativePath}).String()}
+
+	if customBaseURL == "" || assetPrefix == "" {
+		return candidates
+	}
+
+	scheme := "https"
+	if customRoot, err := url.Parse(customBaseURL); err == nil && customRoot.Scheme != "" {
+		scheme = customRoot.Scheme
+	}
+	fallbackPath := path.Join("_next/static", buildID, "_buildManifest.js")
+
+	var fallbackURL string
+	if prefixURL, err := url.Parse(assetPrefix); err == nil && prefixURL.Host != "" {
+		// Asset prefix is a full URL
+		fallbackURL = fmt.Sprintf("%s://%s%s", scheme, prefixURL.Host, path.Join("/", prefixURL.Path, fallbackPath))
+	} else if prefixParts := strings.Split(strings.Trim(assetPrefix, "/"), "/"); strings.Contains(prefixParts[0], ".") {
+		// Asset prefix starts with a domain, e.g. "cdn.example.com/app"
+		remainingPath := "/" + strings.Join(prefixParts[1:], "/")
+		fallbackURL = fmt.Sprintf("%s://%s%s", scheme, prefixParts[0], path.Join(remainingPath, fallbackPath))
+	}
+
+	if fallbackURL != "" && fallbackURL != candidates[0] {
+		candidates = append(candidates, fallbackURL)
+	}
+	return candidates
+}
+
+// fetchInitial fetches the target page, prefixing the configured scheme when
+// the target has none. In SchemeAuto mode, a bare host that cannot be reached
+// over HTTPS is retried over HTTP. It returns the URL actually requested.
+func (s *Scanner) fetchInitial(initialTargetURL string) (io.ReadCloser, *fetch.ResponseInfo, string, error) {
+	if strings.HasPrefix(initialTargetURL, "http://") || strings.HasPrefix(initialTargetURL, "https://") {
+		slog.Info("Scanning target", "target", initialTargetURL)
+		body, info, err := fetch.FetchWithInfo(s.fetcher, initialTargetURL)
+		return body, info, initialTargetURL, err
+	}
+
+	scheme := s.scheme
+	if scheme == SchemeAuto {
+		scheme = SchemeHTTPS
+	}
+	targetURL := scheme + "://" + initialTargetURL
+	slog.Info("Scanning target, no scheme given", "target", targetURL, "scheme", scheme)
+	body, info, err := fetch.FetchWithInfo(s.fetcher, targetURL)
+	if err == nil || s.scheme != SchemeAuto || info.StatusCode != 0 {
+		return body, info, targetURL, err
+	}
+
+	// No HTTP response at all over HTTPS: the host may only serve plain HTTP.
+	httpURL := SchemeHTTP + "://" + initialTargetURL
+	slog.Warn("HTTPS fetch failed, falling back to HTTP", "target", targetURL, "error", err, "fallback", httpURL)
+	body, httpInfo, httpErr := fetch.FetchWithInfo(s.fetcher, httpURL)
+	if httpErr != nil {
+		// Report the original HTTPS failure; it is usually the more relevant one.
+		return nil, info, targetURL, err
+	}
+	slog.Info("Scanning target over plain HTTP", "target", httpURL)
+	return body, httpInfo, httpURL, nil
+}
+
+// redirectChain returns the redirect hops of a fetch, or nil if there were none.
+func redirectChain(info *fetch.ResponseInfo) []string {
+	if info == nil || len(info.RedirectChain) < 2 {
+		return nil
+	}
+	return info.RedirectChain
+}
+
+// isHTMLContentType reports whether a Content-Type header value names one of
+// htmlContentTypes, ignoring its parameters (e.g. "text/html; charset=utf-8").
+func isHTMLContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType, _, _ = strings.Cut(strings.ToLower(contentType), ";")
+		mediaType = strings.TrimSpace(mediaType)
+	}
+	for _, htmlType := range htmlContentTypes {
+		if mediaType == htmlType {
+			return true
+		}
+	}
+	return false
+}
+
+// hasNextJSSignal reports whether raw HTML contains any of the markers the
+// scanner relies on to identify a Next.js page.
+func hasNextJSSignal(htmlContent string) bool {
+	return strings.Contains(htmlContent, "__NEXT_DATA__") || strings.Contains(htmlContent, "/_next/static/") || hasRSCPayload(htmlContent)
+}
+
+// fetchRendered retrieves the page through the render fetcher and returns its HTML.
+func (s *Scanner) fetchRendered(targetURL string) (string, string, error) {
+	reader, finalURL, err := s.renderFetcher.Fetch(targetURL)
+	if err != nil {
+		return "", finalURL, err
+	}
+	defer reader.Close()
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return "", finalURL, fmt.Errorf("failed to read rendered body from %s: %w", finalURL, err)
+	}
+	return string(body), finalURL, nil
+}
+
 // findAndParseNextData finds the __NEXT_DATA__ script and parses its JSON content.
 func findAndParseNextData(htmlBody io.Reader) (*NextData, string, error) {
 	doc, err := goquery.NewDocumentFromReader(htmlBody)
@@ -118,12 +678,27 @@ func findAndParseNextData(htmlBody io.Reader) (*NextData, string, error) {
 	})
 
 	if jsonData == "" {
-		return nil, "", errors.New("__NEXT_DATA__ script tag not found")
+		return nil, "", ErrNextDataNotFound
 	}
 
 	var nextData NextData
 	err = json.Unmarshal([]byte(jsonData), &nextData)
+	if err != nil && strings.Contains(jsonData, "&") {
+		// Some proxies HTML-escape the script content (&quot;), which the HTML
+		// parser leaves as is inside <script> elements
+		unescaped := html.UnescapeString(jsonData)
+		var unescapedData NextData
+		if json.Unmarshal([]byte(unescaped), &unescapedData) == nil {
+			log.Printf("__NEXT_DATA__ is HTML-escaped, parsed it after unescaping its entities")
+			nextData, jsonData, err = unescapedData, unescaped, nil
+		}
+	}
 	if err != nil {
+		// The blob may have been cut off by a CDN or a truncated stream: salvage
+		// what is needed to fetch the build manifest.
+		if partial := partialNextData(jsonData); partial != nil {
+			return partial, jsonData, fmt.Errorf("__NEXT_DATA__ JSON is invalid or truncated, recovered buildId '%s' only: %w", partial.BuildID, err)
+		}
 		return nil, jsonData, fmt.Errorf("failed to unmarshal __NEXT_DATA__ JSON: %w", err)
 	}
 
@@ -134,8 +709,112 @@ func findAndParseNextData(htmlBody io.Reader) (*NextData, string, error) {
 	return &nextData, jsonData, nil
 }
 
+var nextDataBuildIDRegex = regexp.MustCompile(`"buildId"\s*:\s*("(?:[^"\\]|\\.)*")`)
+var nextDataAssetPrefixRegex = regexp.MustCompile(`"assetPrefix"\s*:\s*("(?:[^"\\]|\\.)*")`)
+
+// partialNextData extracts the buildId and assetPrefix from a __NEXT_DATA__
+// blob that is not valid JSON. It returns nil if no buildId can be found.
+func partialNextData(jsonData string) *NextData {
+	var nextData NextData
+	if m := nextDataBuildIDRegex.FindStringSubmatch(jsonData); m != nil {
+		_ = json.Unmarshal([]byte(m[1]), &nextData.BuildID)
+	}
+	if nextData.BuildID == "" {
+		return nil
+	}
+	if m := nextDataAssetPrefixRegex.FindStringSubmatch(jsonData); m != nil {
+		_ = json.Unmarshal([]byte(m[1]), &nextData.AssetPrefix)
+	}
+	return &nextData
+}
+
+// manifestGlobals lists the built-ins kept in the manifest sandbox.
+// Every other global is removed before the manifest is evaluated.
+var manifestGlobals = map[string]bool{
+	"Object": true, "Array": true, "String": true, "Number": true, "Boolean": true,
+	"Symbol": true, "JSON": true, "Math": true, "Error": true, "TypeError": true,
+	"undefined": true, "NaN": true, "Infinity": true,
+}
+
+// manifestMaxCallStackSize caps recursion depth inside the manifest sandbox.
+const manifestMaxCallStackSize = 1024
+
+// newManifestVM creates a goja runtime with a minimal, frozen global object
+// exposing only manifestGlobals and an empty `self`.
+func newManifestVM() (*goja.Runtime, error) {
+	vm := goja.New()
+	vm.SetMaxCallStackSize(manifestMaxCallStackSize)
+
+	global := vm.GlobalObject()
+	for _, name := range global.GetOwnPropertyNames() {
+		if manifestGlobals[name] {
+			continue
+		}
+		if err := global.Delete(name); err != nil {
+			return nil, fmt.Errorf("goja: failed to remove global '%s': %w", name, err)
+		}
+	}
+
+	if _, err := vm.RunString("var self = {}; Object.freeze(this);"); err != nil {
+		return nil, fmt.Errorf("goja: failed to prepare sandbox: %w", err)
+	}
+	return vm, nil
+}
+
+// isManifestExpression reports whether expr looks like something a build
+// manifest assigns: an object literal or the function expression Next.js
+// wraps around it. Anything else is rejected before evaluation.
+func isManifestExpression(expr string) bool {
+	trimmed := strings.TrimLeft(expr, "( \t\r\n")
+	return strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "function")
+}
+
+// applyNextData copies the fields parsed from __NEXT_DATA__ into the result.
+func applyNextData(result *ScanResult, nextData *NextData) {
+	result.BuildID = nextData.BuildID
+	if result.BuildID != "" {
+		result.BuildIDType, result.BuildIDDate = classifyBuildID(result.BuildID)
+	}
+	result.AssetPrefix = nextData.AssetPrefix
+	result.BasePath = normalizeBasePath(nextData.BasePath)
+	result.Locale = nextData.Locale
+	result.Locales = nextData.Locales
+	result.DefaultLocale = nextData.DefaultLocale
+	result.LocalePrefixes = localePrefixes(nextData.Locales, nextData.DefaultLocale)
+	if len(nextData.RuntimeConfig) > 0 {
+		result.RuntimeConfig = nextData.RuntimeConfig
+		log.Printf("__NEXT_DATA__ exposes a public runtime config with %d keys", len(nextData.RuntimeConfig))
+	}
+	if len(result.LocalePrefixes) > 0 {
+		log.Printf("i18n enabled: routes may also be served under locale prefixes %v", result.LocalePrefixes)
+	}
+}
+
+// localePrefixes returns the path prefixes under which localized routes are
+// served. The default locale is served without a prefix and is skipped.
+func localePrefixes(locales []string, defaultLocale string) []string {
+	var prefixes []string
+	for _, locale := range locales {
+		if locale == "" || locale == defaultLocale {
+			continue
+		}
+		prefixes = append(prefixes, "/"+locale)
+	}
+	return prefixes
+}
+
 // executeManifestJS runs the manifest JS using goja.
-func executeManifestJS(manifestJS string) (map[string]interface{}, error) {
+// The VM is interrupted if evaluation takes longer than timeout, so a hostile
+// or pathological manifest cannot hang the scan. Evaluation happens in a
+// sandbox (see newManifestVM) and panics from the VM are returned as errors.
+func executeManifestJS(manifestJS string, timeout time.Duration) (manifestMap map[string]interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			manifestMap = nil
+			err = fmt.Errorf("goja: manifest execution panicked: %v", r)
+		}
+	}()
+
 	matches := manifestJSRegex.FindStringSubmatch(manifestJS)
 	if len(matches) < 2 {
 		log.Printf("Warning: Could not extract exact manifest expression via regex, attempting to run full script content.")
@@ -157,14 +836,26 @@ func executeManifestJS(manifestJS string) (map[string]interface{}, error) {
 		manifestJS = "(" + matches[1] + ")"
 	}
 
-	vm := goja.New()
-	_, err := vm.RunString("var self = {};")
+	if !isManifestExpression(manifestJS) {
+		return nil, errors.New("manifest JS is not an object literal or manifest function, refusing to execute")
+	}
+
+	vm, err := newManifestVM()
 	if err != nil {
-		return nil, fmt.Errorf("goja: failed to define 'self': %w", err)
+		return nil, err
 	}
 
+	timer := time.AfterFunc(timeout, func() {
+		vm.Interrupt("manifest execution timeout")
+	})
+	defer timer.Stop()
+
 	result, err := vm.RunString(manifestJS)
 	if err != nil {
+		var interrupted *goja.InterruptedError
+		if errors.As(err, &interrupted) {
+			return nil, fmt.Errorf("goja: manifest JS did not finish within %s (timed out)", timeout)
+		}
 		return nil, fmt.Errorf("goja: failed to execute manifest JS: %w", err)
 	}
 
@@ -178,7 +869,26 @@ func executeManifestJS(manifestJS string) (map[string]interface{}, error) {
 	return manifestMap, nil
 }
 
+// normalizeAssetURL returns a canonical string form of an asset URL so that
+// entries differing only by host case, duplicate slashes, dot segments or
+// percent-encoding map to the same key.
+func normalizeAssetURL(u *url.URL) string {
+	normalized := *u
+	normalized.Scheme = strings.ToLower(normalized.Scheme)
+	normalized.Host = strings.ToLower(normalized.Host)
+	if unescaped, err := url.PathUnescape(normalized.Path); err == nil {
+		normalized.Path = unescaped
+	}
+	normalized.RawPath = ""
+	if normalized.Path != "" {
+		normalized.Path = path.Clean("/" + normalized.Path)
+	}
+	return normalized.String()
+}
+
 // extractRoutesAndAssets processes the parsed manifest map.
+// Asset URLs are normalized with normalizeAssetURL and deduplicated, both per
+// route and across the whole manifest.
 func extractRoutesAndAssets(manifestData map[string]interface{}, assetBaseURL string) (map[string][]string, map[string]bool) {
 	routes := make(map[string][]string)
 	allAssets := make(map[string]bool)
@@ -205,6 +915,7 @@ func extractRoutesAndAssets(manifestData map[string]interface{}, assetBaseURL st
 		}
 
 		routeAssets := []string{}
+		seen := make(map[string]bool)
 		for _, assetPathInterface := range assetList {
 			assetPath, ok := assetPathInterface.(string)
 			if !ok {
@@ -225,8 +936,12 @@ func extractRoutesAndAssets(manifestData map[string]interface{}, assetBaseURL st
 				Host:   baseURLParsed.Host,
 				Path:   fullPath,
 			}
-			fullAssetURL := resolvedURL.String()
+			fullAssetURL := normalizeAssetURL(resolvedURL)
 
+			if seen[fullAssetURL] {
+				continue
+			}
+			seen[fullAssetURL] = true
 			routeAssets = append(routeAssets, fullAssetURL)
 			allAssets[fullAssetURL] = true
 		}
@@ -238,36 +953,81 @@ func extractRoutesAndAssets(manifestData map[string]interface{}, assetBaseURL st
 }
 
 // ScanTarget performs the Next.js analysis on the given target URL.
+// Every request of the scan is timed, and the totals are recorded in the result.
 func (s *Scanner) ScanTarget(initialTargetURL string) (*ScanResult, error) {
-	targetURL := initialTargetURL
-	if !strings.HasPrefix(targetURL, "http://") && !strings.HasPrefix(targetURL, "https://") {
-		targetURL = "https://" + targetURL
+	if s.customBaseURLErr != nil {
+		return nil, fmt.Errorf("scanner: %w", s.customBaseURLErr)
+	}
+	if s.assetHostErr != nil {
+		return nil, fmt.Errorf("scanner: %w", s.assetHostErr)
 	}
-	log.Printf("Scanning target: %s", targetURL)
 
-	htmlBodyReader, finalURL, fetchErr := s.fetcher.Fetch(targetURL)
+	start := time.Now()
+	timing := fetch.NewTimingFetcher(s.fetcher)
+	scan := *s
+	scan.fetcher = timing
+
+	result, err := scan.scanTarget(initialTargetURL)
+	if result != nil {
+		result.SchemaVersion = SchemaVersion
+		result.TotalScanDuration = time.Since(start)
+		result.FetchCount, result.FetchDuration = timing.Stats()
+	}
+
+	attrs := []any{"target", initialTargetURL, "duration", time.Since(start)}
+	if result != nil {
+		attrs = append(attrs, "nextjs", result.IsNextJS, "requests", result.FetchCount)
+	}
+	if err != nil {
+		slog.Warn("Scan failed", append(attrs, "error", err)...)
+	} else {
+		slog.Info("Scan finished", attrs...)
+	}
+	s.emit(ProgressEvent{Type: EventDone, Err: err})
+	return result, err
+}
+
+// scanTarget implements ScanTarget.
+func (s *Scanner) scanTarget(initialTargetURL string) (*ScanResult, error) {
+	htmlFetchStart := time.Now()
+	htmlBodyReader, responseInfo, targetURL, fetchErr := s.fetchInitial(initialTargetURL)
+	htmlFetchDuration := time.Since(htmlFetchStart)
+	finalURL := responseInfo.FinalURL
 	if fetchErr != nil {
 		parsedBaseUrl, _ := url.Parse(targetURL)
 		result := ScanResult{
-			BaseURL:   targetURL,
-			Routes:    make(map[string][]string),
-			AllAssets: make(map[string]bool),
+			BaseURL:           targetURL,
+			Routes:            make(map[string][]string),
+			AllAssets:         make(map[string]bool),
+			HTMLFetchDuration: htmlFetchDuration,
 		}
 		if parsedBaseUrl != nil {
 			result.AssetBaseURL = parsedBaseUrl.String()
 		}
+		result.RedirectChain = redirectChain(responseInfo)
 		result.ExecutionError = fmt.Errorf("scanner: initial fetch failed for %s: %w", targetURL, fetchErr)
 		return &result, result.ExecutionError
 	}
 	defer htmlBodyReader.Close()
 	log.Printf("Initial fetch successful, final URL: %s", finalURL)
+	s.emit(ProgressEvent{Type: EventHTMLFetched, URL: finalURL})
+	initialHeaders := responseInfo.Headers
+	// The TLS client doesn't report the negotiated protocol, but servers and
+	// CDNs often expose it, and whether HTTP/3 is offered
+	if via := initialHeaders.Get("Via"); via != "" {
+		log.Printf("Response protocol hops (Via): %s", via)
+	}
+	if altSvc := initialHeaders.Get("Alt-Svc"); altSvc != "" {
+		log.Printf("Server advertises alternative protocols (Alt-Svc): %s", altSvc)
+	}
 
 	baseURL, parseErr := url.Parse(finalURL)
 	if parseErr != nil {
 		result := ScanResult{
-			BaseURL:   initialTargetURL,
-			Routes:    make(map[string][]string),
-			AllAssets: make(map[string]bool),
+			BaseURL:           initialTargetURL,
+			Routes:            make(map[string][]string),
+			AllAssets:         make(map[string]bool),
+			HTMLFetchDuration: htmlFetchDuration,
 		}
 		err := fmt.Errorf("scanner: invalid final URL '%s' received from fetcher: %w", finalURL, parseErr)
 		result.ExecutionError = err
@@ -275,9 +1035,24 @@ func (s *Scanner) ScanTarget(initialTargetURL string) (*ScanResult, error) {
 	}
 
 	result := ScanResult{
-		BaseURL:   baseURL.String(),
-		Routes:    make(map[string][]string),
-		AllAssets: make(map[string]bool),
+		BaseURL:           baseURL.String(),
+		Routes:            make(map[string][]string),
+		AllAssets:         make(map[string]bool),
+		RedirectChain:     redirectChain(responseInfo),
+		HTMLFetchDuration: htmlFetchDuration,
+	}
+	if len(result.RedirectChain) > 0 {
+		log.Printf("Followed redirects: %s", strings.Join(result.RedirectChain, " -> "))
+	}
+	if s.includeHeaders && initialHeaders != nil {
+		result.ResponseHeaders = initialHeaders.Clone()
+	}
+
+	// A page without Content-Type (or from a fetcher not reporting headers) is
+	// still parsed
+	if contentType := initialHeaders.Get("Content-Type"); contentType != "" && !isHTMLContentType(contentType) {
+		result.ExecutionError = fmt.Errorf("scanner: %w (got %s)", ErrNotHTML, contentType)
+		return &result, result.ExecutionError
 	}
 
 	bodyBytes, readErr := io.ReadAll(htmlBodyReader)
@@ -287,6 +1062,19 @@ func (s *Scanner) ScanTarget(initialTargetURL string) (*ScanResult, error) {
 	}
 	htmlContent := string(bodyBytes)
 
+	if s.renderFetcher != nil && !hasNextJSSignal(htmlContent) {
+		log.Printf("No Next.js signal in static HTML, rendering %s with a JavaScript-capable fetcher...", finalURL)
+		renderedHTML, renderedURL, renderErr := s.fetchRendered(finalURL)
+		if renderErr != nil {
+			log.Printf("Warning: Rendered fetch failed, continuing with static HTML: %v", renderErr)
+		} else if renderedBaseURL, err := url.Parse(renderedURL); err == nil {
+			htmlContent = renderedHTML
+			baseURL = renderedBaseURL
+			result.BaseURL = baseURL.String()
+			log.Printf("Using rendered HTML from %s", renderedURL)
+		}
+	}
+
 	var nextData *NextData
 	var nextDataErr error
 	nextData, result.NextDataJSONRaw, nextDataErr = findAndParseNextData(strings.NewReader(htmlContent))
@@ -295,193 +1083,137 @@ func (s *Scanner) ScanTarget(initialTargetURL string) (*ScanResult, error) {
 		log.Printf("Note: Error processing __NEXT_DATA__: %v", nextDataErr)
 		if nextData != nil && nextData.BuildID != "" {
 			result.IsNextJS = true
-			result.BuildID = nextData.BuildID
-			result.AssetPrefix = nextData.AssetPrefix
-		} else if !errors.Is(nextDataErr, errors.New("__NEXT_DATA__ script tag not found")) {
+			applyNextData(&result, nextData)
+		} else if !errors.Is(nextDataErr, ErrNextDataNotFound) {
 			result.IsNextJS = false
 		}
 	} else {
 		result.IsNextJS = true
-		result.BuildID = nextData.BuildID
-		result.AssetPrefix = nextData.AssetPrefix
+		applyNextData(&result, nextData)
 	}
 
-	// Handle asset base URL based on whether a custom base URL was provided
-	var assetBaseParsedURL url.URL
-	
 	if s.customBaseURL != "" {
-		// Use the custom base URL when provided
-		customURL, err := url.Parse(s.customBaseURL)
-		if err != nil {
-			log.Printf("Warning: Could not parse custom base URL '%s': %v. Using default behavior.", s.customBaseURL, err)
-			assetBaseParsedURL = *baseURL
-		} else {
-			log.Printf("Using custom base URL: %s", s.customBaseURL)
-			assetBaseParsedURL = *customURL
-			
-			// If asset prefix is detected, append it to the custom base URL
-			if result.AssetPrefix != "" {
-				prefixURL, err := url.Parse(result.AssetPrefix)
-				if err == nil && prefixURL.IsAbs() {
-					// If asset prefix is absolute, use just its path with the custom base URL
-					prefixPath := prefixURL.Path
-					if !strings.HasSuffix(assetBaseParsedURL.Path, "/") && !strings.HasPrefix(prefixPath, "/") {
-						assetBaseParsedURL.Path += "/"
-					}
-					assetBaseParsedURL.Path += strings.TrimPrefix(prefixPath, "/")
-					log.Printf("Appending absolute AssetPrefix path to custom base URL: %s", assetBaseParsedURL.String())
-				} else {
-					// For relative asset prefix, simply append it to the custom base path
-					if !strings.HasSuffix(assetBaseParsedURL.Path, "/") && !strings.HasPrefix(result.AssetPrefix, "/") {
-						assetBaseParsedURL.Path += "/"
-					}
-					assetBaseParsedURL.Path += strings.TrimPrefix(result.AssetPrefix, "/")
-					log.Printf("Appending relative AssetPrefix to custom base URL: %s", assetBaseParsedURL.String())
-				}
-			}
-		}
+		log.Printf("Using custom base URL: %s", s.customBaseURL)
+	}
+	if result.BasePath == "" && result.AssetPrefix == "" {
+		result.BasePath = inferBasePath(htmlContent, baseURL)
+	}
+	if result.BasePath != "" {
+		log.Printf("App is served under the base path %s", result.BasePath)
+	}
+	assetBaseParsedURL := resolveAssetBaseURL(baseURL, s.customBaseURL, result.AssetPrefix, result.BasePath)
+	if result.AssetPrefix != "" {
+		log.Printf("Resolved AssetPrefix %q to asset base: %s", result.AssetPrefix, assetBaseParsedURL.String())
 	} else {
-		// Use the original logic when no custom base URL is provided
-		assetBaseParsedURL = *baseURL
-		if result.AssetPrefix != "" {
-			prefixURL, err := url.Parse(result.AssetPrefix)
-			if err == nil && prefixURL.IsAbs() {
-				assetBaseParsedURL = *prefixURL
-				log.Printf("Using absolute AssetPrefix: %s", assetBaseParsedURL.String())
-			} else {
-				assetPrefixURL := &url.URL{Path: result.AssetPrefix}
-				resolvedAssetBaseURL := baseURL.ResolveReference(assetPrefixURL)
-				assetBaseParsedURL = *resolvedAssetBaseURL
-				if assetBaseParsedURL.Path != "" && !strings.HasSuffix(assetBaseParsedURL.Path, "/") {
-					assetBaseParsedURL.Path += "/"
-				}
-				log.Printf("Using relative AssetPrefix, resolved asset base: %s", assetBaseParsedURL.String())
-			}
-		} else {
-			log.Printf("No AssetPrefix found, asset paths will be resolved relative to page base: %s", assetBaseParsedURL.String())
-		}
+		log.Printf("No AssetPrefix found, asset paths will be resolved relative to: %s", assetBaseParsedURL.String())
 	}
-	
+
 	result.AssetBaseURL = assetBaseParsedURL.String()
 
-	initialScriptURLs := findInitialScriptURLs(htmlContent, &assetBaseParsedURL)
+	// The manifest and the assets are fetched through assetSource, which
+	// watches (and overrides) the asset host when it isn't the page's
+	var assetSource fetch.Fetcher = s.fetcher
+	var assetHost *assetHostFetcher
+	if assetBaseParsedURL.Host != "" && !strings.EqualFold(assetBaseParsedURL.Host, baseURL.Host) {
+		result.AssetHost = assetBaseParsedURL.Host
+		log.Printf("Assets are served from %s, not from the page host %s.", result.AssetHost, baseURL.Host)
+		assetHost = &assetHostFetcher{Fetcher: s.fetcher, assetHost: result.AssetHost, override: s.assetHostOverride}
+		assetSource = assetHost
+		if s.assetHostOverride != "" {
+			log.Printf("Fetching assets from %s instead of %s.", s.assetHostOverride, result.AssetHost)
+		}
+	} else if s.assetHostOverride != "" {
+		log.Printf("Assets are served from the page host, ignoring the asset host override %s.", s.assetHostOverride)
+	}
+
+	initialScriptURLs := findInitialScriptURLs(htmlContent, &assetBaseParsedURL, s.assetPattern)
 
-	if errors.Is(nextDataErr, errors.New("__NEXT_DATA__ script tag not found")) && len(initialScriptURLs) > 0 {
+	if errors.Is(nextDataErr, ErrNextDataNotFound) && len(initialScriptURLs) > 0 {
 		log.Println("__NEXT_DATA__ not found, but initial Next.js scripts detected. Setting IsNextJS=true.")
 		result.IsNextJS = true
 	}
+	appRouterPayload := hasRSCPayload(htmlContent)
+	if appRouterPayload && s.includeRaw {
+		result.FlightPayloadRaw = extractFlightPayload(htmlContent)
+		log.Printf("Extracted %d bytes of App Router flight payload.", len(result.FlightPayloadRaw))
+	}
+	if errors.Is(nextDataErr, ErrNextDataNotFound) && !result.IsNextJS && appRouterPayload {
+		log.Println("__NEXT_DATA__ not found, but an App Router RSC payload was detected. Setting IsNextJS=true.")
+		result.IsNextJS = true
+	}
+	if result.IsNextJS && isStaticExport(nextData, len(initialScriptURLs) > 0 || appRouterPayload, initialHeaders) {
+		result.DeploymentType = DeploymentTypeStaticExport
+		log.Println("No Next.js server detected, the site appears to be a static export (output: 'export').")
+	}
+	if result.IsNextJS {
+		result.RenderStrategy = detectRenderStrategy(nextData, initialHeaders)
+		log.Printf("Render strategy of the scanned page: %s", result.RenderStrategy)
+	}
+
+	if s.dryRun {
+		result.PlannedRequests = s.planRequests(&result, htmlContent, baseURL, &assetBaseParsedURL, initialScriptURLs, assetHost)
+		log.Printf("Dry run: %d requests planned, not fetching the build manifest nor any asset.", len(result.PlannedRequests))
+		if !result.IsNextJS {
+			result.ExecutionError = nextDataErr
+		}
+		return &result, result.ExecutionError
+	}
+
+	if s.cache != nil && !s.refreshCache && result.BuildID != "" {
+		if cached, ok := s.cache.Get(baseURL.Host, result.BuildID, s.cacheOptions()); ok {
+			log.Printf("Build ID %s of %s is cached, reusing the cached routes, assets and versions.", result.BuildID, baseURL.Host)
+			reuseCachedResult(&result, cached)
+			analyzePageHeaders(&result, initialHeaders, foundMiddlewareManifest(cached.RuntimeSignals))
+			if s.probePreview {
+				// The cookies set by the preview routes come from the cached probes
+				result.PreviewCookies = appendUnique(result.PreviewCookies, cached.PreviewCookies...)
+			}
+			result.FromCache = true
+			return &result, nil
+		}
+	}
 
 	manifestAssets := make(map[string]bool)
 	routes := make(map[string][]string)
 	var manifestProcessingError error
+	middlewareManifestFound := false
 
-	if result.BuildID != "" {
-		// Construct the manifest URL correctly
-		// When using custom base URL + asset prefix, we need to be careful with path construction
-		// We need to determine whether _next should be a part of the asset prefix or appended separately
-		
-		var manifestURL string
-		
-		// Check if the asset base already contains _next in the path (from the asset prefix)
-		if strings.Contains(assetBaseParsedURL.Path, "/_next/") || strings.HasSuffix(assetBaseParsedURL.Path, "/_next") {
-			// Asset base already contains _next path, just append the rest
-			relativePath := path.Join("static", result.BuildID, "_buildManifest.js")
-			manifestPathURL := &url.URL{Path: relativePath}
-			manifestURL = (&assetBaseParsedURL).ResolveReference(manifestPathURL).String()
-		} else {
-			// Asset base doesn't contain _next, so append the full path
-			relativePath := path.Join("_next/static", result.BuildID, "_buildManifest.js")
-			manifestPathURL := &url.URL{Path: relativePath}
-			manifestURL = (&assetBaseParsedURL).ResolveReference(manifestPathURL).String()
-		}
-		
-		log.Printf("Attempting to fetch build manifest from: %s", manifestURL)
+	if result.BuildID != "" && !s.skipRoutes {
+		candidates := candidateManifestURLs(&assetBaseParsedURL, result.AssetPrefix, result.BuildID, s.customBaseURL)
+		manifestURL := candidates[0]
 
 		var manifestReader io.ReadCloser
 		var manifestFinalURL string
-		
-		manifestReader, manifestFinalURL, fetchErr := s.fetcher.Fetch(manifestURL)
-		if fetchErr != nil {
-			log.Printf("Failed to fetch build manifest: %v", fetchErr)
-			
-			// Try a fallback approach for sites that might place the manifest at the root
-			// This is especially relevant for complex CDN setups with custom base URLs
-			var fallbackManifestURL string
-			
-			// Construct a fallback URL that assumes the manifest might be at the root of the asset prefix path
-			customRoot, err := url.Parse(s.customBaseURL)
-			if s.customBaseURL != "" && err == nil && result.AssetPrefix != "" {
-				// Try a direct join of the host with _next path
-				fallbackPath := path.Join("_next/static", result.BuildID, "_buildManifest.js")
-				
-				// Parse the asset prefix to extract host and path components
-				prefixURL, prefixErr := url.Parse(result.AssetPrefix)
-				
-				if prefixErr == nil && prefixURL.Host != "" {
-					// Handle case where AssetPrefix is a full URL
-					host := prefixURL.Host
-					pathPrefix := prefixURL.Path
-					
-					scheme := customRoot.Scheme
-					if scheme == "" {
-						scheme = "https" // Default to https
-					}
-					
-					// Include the path component from the asset prefix
-					completePath := path.Join(pathPrefix, fallbackPath)
-					fallbackManifestURL = fmt.Sprintf("%s://%s%s", scheme, host, completePath)
-				} else if strings.Contains(result.AssetPrefix, ".") {
-					// Handle cases where the asset prefix might contain a domain
-					// Extract domain and path components from the asset prefix
-					assetPrefix := strings.Trim(result.AssetPrefix, "/")
-					assetPrefixParts := strings.Split(assetPrefix, "/")
-					
-					// Check if the first part looks like a domain
-					if len(assetPrefixParts) > 0 && strings.Contains(assetPrefixParts[0], ".") {
-						host := assetPrefixParts[0]
-						remainingPath := ""
-						
-						// Reconstruct the path after the domain
-						if len(assetPrefixParts) > 1 {
-							remainingPath = "/" + strings.Join(assetPrefixParts[1:], "/")
-						}
-						
-						scheme := customRoot.Scheme
-						if scheme == "" {
-							scheme = "https" // Default to https
-						}
-						
-						// Construct URL with domain and preserved path components
-						completePath := path.Join(remainingPath, fallbackPath)
-						fallbackManifestURL = fmt.Sprintf("%s://%s%s", scheme, host, completePath)
-						log.Printf("Detected domain in AssetPrefix, using: %s://%s%s", scheme, host, remainingPath)
-					} else {
-						// If no domain detected, use the original error
-						manifestProcessingError = fmt.Errorf("failed to fetch build manifest at %s: %w", manifestURL, fetchErr)
-					}
-				} else {
-					manifestProcessingError = fmt.Errorf("failed to fetch build manifest at %s: %w", manifestURL, fetchErr)
-				}
-				
-				// If we constructed a fallback URL, try to fetch it
-				if fallbackManifestURL != "" {
-					log.Printf("Trying fallback manifest location: %s", fallbackManifestURL)
-					
-					fallbackReader, fallbackFinalURL, fallbackErr := s.fetcher.Fetch(fallbackManifestURL)
-					if fallbackErr == nil {
-						// Successfully fetched the fallback URL
-						manifestReader = fallbackReader
-						manifestFinalURL = fallbackFinalURL
-						fetchErr = nil // Clear the error since fallback worked
-						log.Printf("Successfully fetched manifest from fallback location: %s", fallbackFinalURL)
-					} else {
-						log.Printf("Fallback manifest fetch also failed: %v", fallbackErr)
-						// Keep the original error and continue with it
-						manifestProcessingError = fmt.Errorf("failed to fetch build manifest at %s (and fallback): %w", manifestURL, fetchErr)
-					}
+		var firstFetchErr error
+
+		for i, candidateURL := range candidates {
+			if s.progress != nil {
+				s.progress("Fetching build manifest", i+1, len(candidates))
+			}
+			if i == 0 {
+				log.Printf("Attempting to fetch build manifest from: %s", candidateURL)
+			} else {
+				log.Printf("Trying fallback manifest location: %s", candidateURL)
+			}
+			reader, finalURL, fetchErr := assetSource.Fetch(candidateURL)
+			if fetchErr != nil {
+				log.Printf("Failed to fetch build manifest: %v", fetchErr)
+				if firstFetchErr == nil {
+					firstFetchErr = fetchErr
 				}
+				continue
+			}
+			if i > 0 {
+				log.Printf("Successfully fetched manifest from fallback location: %s", finalURL)
+			}
+			manifestReader, manifestFinalURL = reader, finalURL
+			break
+		}
+		if manifestReader == nil {
+			if len(candidates) > 1 {
+				manifestProcessingError = fmt.Errorf("failed to fetch build manifest at %s (and fallback): %w", manifestURL, firstFetchErr)
 			} else {
-				manifestProcessingError = fmt.Errorf("failed to fetch build manifest at %s: %w", manifestURL, fetchErr)
+				manifestProcessingError = fmt.Errorf("failed to fetch build manifest at %s: %w", manifestURL, firstFetchErr)
 			}
 		}
 		
@@ -492,6 +1224,7 @@ func (s *Scanner) ScanTarget(initialTargetURL string) (*ScanResult, error) {
 				log.Printf("Build manifest request resulted in final URL: %s", manifestFinalURL)
 			}
 			result.ManifestFound = true
+			s.emit(ProgressEvent{Type: EventManifestFetched, URL: manifestFinalURL})
 
 			manifestBytes, readErr := io.ReadAll(manifestReader)
 			if readErr != nil {
@@ -499,7 +1232,10 @@ func (s *Scanner) ScanTarget(initialTargetURL string) (*ScanResult, error) {
 				manifestProcessingError = fmt.Errorf("failed to read build manifest from %s: %w", manifestFinalURL, readErr)
 			} else {
 				manifestJS := string(manifestBytes)
-				execData, execErr := executeManifestJS(manifestJS)
+				execStart := time.Now()
+				execData, execErr := executeManifestJS(manifestJS, s.manifestExecTimeout)
+				result.ManifestExecDuration = time.Since(execStart)
+				log.Printf("Build manifest evaluated in %s (limit %s)", result.ManifestExecDuration.Round(time.Millisecond), s.manifestExecTimeout)
 				if execErr != nil {
 					log.Printf("Failed to execute build manifest JS: %v", execErr)
 					trimmedJS := strings.ReplaceAll(manifestJS, "\n", " ")
@@ -509,19 +1245,41 @@ func (s *Scanner) ScanTarget(initialTargetURL string) (*ScanResult, error) {
 				} else {
 					result.ManifestExecOK = true
 					routes, manifestAssets = extractRoutesAndAssets(execData, result.AssetBaseURL)
+					routes, result.SpecialPages = splitSpecialPages(routes)
 					result.Routes = routes
+					result.RouteDetails = buildRouteInfos(routes)
 					result.AllAssets = manifestAssets
-					log.Printf("Successfully processed build manifest. Found %d routes and %d assets.", len(routes), len(manifestAssets))
+					if s.includeRaw {
+						// Values the VM exported as JS functions can't be encoded, so only keep JSON-safe manifests
+						if _, jsonErr := json.Marshal(execData); jsonErr != nil {
+							log.Printf("Not including raw build manifest: %v", jsonErr)
+						} else {
+							result.BuildManifestRaw = execData
+						}
+					}
+					log.Printf("Successfully processed build manifest. Found %d routes, %d special pages and %d assets.", len(routes), len(result.SpecialPages), len(manifestAssets))
 				}
 			}
 		}
+
+		// The middleware manifest sits next to the build manifest when the app has middleware
+		middlewareManifestURL := strings.TrimSuffix(manifestURL, "_buildManifest.js") + "_middlewareManifest.js"
+		if reader, _, err := assetSource.Fetch(middlewareManifestURL); err == nil {
+			reader.Close()
+			middlewareManifestFound = true
+			log.Printf("Middleware manifest found at %s", middlewareManifestURL)
+		}
 	} else {
-		log.Println("No BuildID found, skipping build manifest fetch.")
+		if result.BuildID == "" {
+			log.Println("No BuildID found, skipping build manifest fetch.")
+		} else {
+			log.Println("Route enumeration disabled, skipping build manifest fetch.")
+		}
 		if result.AllAssets == nil { result.AllAssets = make(map[string]bool) }
 		for url := range initialScriptURLs {
 			result.AllAssets[url] = true
 		}
-		log.Printf("No BuildID found. Using %d initial scripts for AllAssets.", len(initialScriptURLs))
+		log.Printf("Using %d initial scripts for AllAssets.", len(initialScriptURLs))
 	}
 
 	combinedJSAssets := make(map[string]bool)
@@ -536,33 +1294,172 @@ func (s *Scanner) ScanTarget(initialTargetURL string) (*ScanResult, error) {
 		}
 	}
 	log.Printf("Using %d unique JS assets for version detection.", len(combinedJSAssets))
+	result.AssetHosts = assetHosts(result.AllAssets, initialScriptURLs)
 
-	nextV, reactV := s.versionDetector.Detect(result.BuildID, combinedJSAssets, &assetBaseParsedURL, s.fetcher)
-	result.DetectedNextVersion = nextV
-	result.DetectedReactVersion = reactV
+	// Assets are fetched through the inspectors, so the chunks downloaded for
+	// version detection and hashing are analyzed without fetching them again
+	styling := newStylingDetector()
+	if doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent)); err == nil {
+		styling.inspectHTML(doc)
+	}
+	webpack := &webpackDetector{}
+	jsSizes := newJSSizeCollector()
+	inspectors := []assetInspector{styling.inspect, webpack.inspect, jsSizes.inspect}
+	var endpoints *endpointCollector
+	if s.extractEndpoints {
+		endpoints = newEndpointCollector()
+		inspectors = append(inspectors, endpoints.inspect)
+	}
+	var keys *keyCollector
+	if s.extractKeys {
+		keys = newKeyCollector()
+		inspectors = append(inspectors, keys.inspect)
+	}
+	var instrumentation *instrumentationDetector
+	if s.detectInstr {
+		instrumentation = newInstrumentationDetector()
+		inspectors = append(inspectors, instrumentation.inspect)
+	}
+	assetFetcher := &inspectingFetcher{Fetcher: assetSource, inspectors: inspectors, events: s.events}
+	if s.includeErrors {
+		assetFetcher.failures = newFetchFailures()
+	}
+
+	if s.skipVersions {
+		log.Println("Skipping version detection.")
+		result.DetectedNextVersion = VersionSkipped
+		result.DetectedReactVersion = VersionSkipped
+	} else {
+		versions := s.versionDetector.Detect(result.BuildID, combinedJSAssets, &assetBaseParsedURL, assetFetcher)
+		result.DetectedNextVersion = versions.NextVersion
+		result.DetectedReactVersion = versions.ReactVersion
+		result.ReactReconcilerVersion = versions.ReactReconcilerVersion
+		result.NextVersionFallback = versions.NextVersionFallback
+		if s.debug {
+			result.VersionCandidates = versions.Candidates
+		}
+	}
+
+	// The runtime chunk is usually among the assets fetched for version
+	// detection; fetch it on its own otherwise
+	if runtimeURL := findWebpackRuntime(initialScriptURLs); runtimeURL == "" {
+		log.Println("No webpack runtime chunk among the initial scripts (Turbopack or non-webpack build).")
+	} else {
+		if _, seen := webpack.Version(); !seen {
+			if reader, _, err := assetFetcher.Fetch(runtimeURL); err == nil {
+				reader.Close()
+			} else {
+				log.Printf("Warning: Failed to fetch webpack runtime chunk %s: %v", runtimeURL, err)
+			}
+		}
+		result.WebpackVersion, _ = webpack.Version()
+		if result.WebpackVersion != "" {
+			log.Printf("Detected webpack version: %s", result.WebpackVersion)
+		}
+	}
+
+	analyzePageHeaders(&result, initialHeaders, middlewareManifestFound)
+
+	if s.hashAssets && len(result.AllAssets) > 0 {
+		log.Printf("Hashing %d assets...", len(result.AllAssets))
+		result.AssetHashes, result.AssetHashErrors = hashAssets(assetFetcher, result.AllAssets, s.hashConcurrency, s.progress)
+		log.Printf("Hashed %d assets (%d failed).", len(result.AssetHashes), len(result.AssetHashErrors))
+	}
+
+	if assetFetcher.failures != nil {
+		result.AssetErrors = assetFetcher.failures.Errors()
+		log.Printf("%d asset fetches failed.", len(result.AssetErrors))
+	}
+
+	if assetHost != nil {
+		var requests int
+		result.AssetHostBlocked, requests = assetHost.blocked()
+		if result.AssetHostBlocked {
+			log.Printf("Warning: all %d requests to the asset host %s failed, it may be blocking the scanner. Versions left Unknown are likely due to it.", requests, result.AssetHost)
+		}
+	}
+
+	result.ChunkCount, result.TotalJSBytes = jsSizes.Totals()
+	log.Printf("Fetched %d JS chunks (%s).", result.ChunkCount, formatByteSize(result.TotalJSBytes))
+
+	result.StylingLibraries = styling.Libraries()
+	if len(result.StylingLibraries) > 0 {
+		log.Printf("Detected styling libraries: %s", strings.Join(result.StylingLibraries, ", "))
+	}
+	if endpoints != nil {
+		result.DiscoveredEndpoints = endpoints.Endpoints()
+		log.Printf("Found %d endpoints referenced in JS chunks.", len(result.DiscoveredEndpoints))
+	}
+	if keys != nil {
+		result.EmbeddedKeys = keys.Keys()
+		log.Printf("Found embedded keys of %d services in JS chunks.", len(result.EmbeddedKeys))
+	}
+	if instrumentation != nil {
+		result.InstrumentationSignals, result.TelemetryEndpoints = instrumentation.Results()
+		result.Instrumentation = len(result.InstrumentationSignals) > 0
+		log.Printf("Found %d instrumentation signals and %d telemetry endpoints in JS chunks.", len(result.InstrumentationSignals), len(result.TelemetryEndpoints))
+	}
+
+	if s.discover {
+		s.discoverServerPaths(&result, baseURL)
+		result.ResolvedRoutes = resolveDynamicRoutes(result.Routes, result.SitemapURLs, result.BasePath, result.LocalePrefixes)
+	}
+	if s.metadata {
+		s.collectMetadata(&result, htmlContent, baseURL)
+	}
+	if s.probeData {
+		if result.BuildID == "" || !result.ManifestExecOK {
+			log.Println("Skipping data route probes: no build ID or build manifest routes.")
+		} else {
+			s.probeDataRoutes(&result, baseURL)
+			log.Printf("Probed %d data routes, %d live.", len(result.DataRoutes), countLive(result.DataRoutes))
+		}
+	}
+	if s.probeImage {
+		s.probeImageOptimizer(&result, htmlContent, baseURL)
+		log.Printf("Image optimizer: %s", formatImageOptimizer(&result))
+	}
+	if s.probePreview {
+		s.probePreviewMode(&result, baseURL)
+		log.Printf("Preview mode: %s", formatPreviewMode(&result))
+	}
+	if s.bruteforce {
+		wordlist := s.wordlist
+		if len(wordlist) == 0 {
+			wordlist = DefaultWordlist()
+		}
+		log.Printf("Bruteforcing %d paths...", len(wordlist))
+		result.DiscoveredRoutes = bruteforceRoutes(s.fetcher, baseURL, wordlist, DefaultBruteforceConcurrency, s.progress)
+		log.Printf("Found %d of %d paths.", len(result.DiscoveredRoutes), len(wordlist))
+	}
 
 	var finalError error
 	if manifestProcessingError != nil {
 		finalError = fmt.Errorf("scanner: manifest processing failed: %w", manifestProcessingError)
-		log.Printf("Scan completed with manifest processing errors.")
-	} else if nextDataErr != nil && !errors.Is(nextDataErr, errors.New("__NEXT_DATA__ script tag not found")) {
+		slog.Warn("Scan completed with manifest processing errors", "target", result.BaseURL, "error", manifestProcessingError)
+	} else if nextDataErr != nil && !errors.Is(nextDataErr, ErrNextDataNotFound) {
 		finalError = fmt.Errorf("scanner: __NEXT_DATA__ processing error: %w", nextDataErr)
-		log.Printf("Scan completed with __NEXT_DATA__ processing errors.")
-	} else if errors.Is(nextDataErr, errors.New("__NEXT_DATA__ script tag not found")) && len(initialScriptURLs) == 0 {
+		slog.Warn("Scan completed with __NEXT_DATA__ processing errors", "target", result.BaseURL, "error", nextDataErr)
+	} else if errors.Is(nextDataErr, ErrNextDataNotFound) && len(initialScriptURLs) == 0 && !appRouterPayload {
 		finalError = nextDataErr
-		log.Printf("Scan complete: __NEXT_DATA__ not found and no initial scripts detected.")
-	} else if errors.Is(nextDataErr, errors.New("__NEXT_DATA__ script tag not found")) && result.IsNextJS {
-		log.Printf("Scan complete: __NEXT_DATA__ not found but initial scripts were present.")
+		slog.Info("Scan complete, __NEXT_DATA__ not found and no initial scripts detected", "target", result.BaseURL)
+	} else if errors.Is(nextDataErr, ErrNextDataNotFound) && result.IsNextJS {
+		slog.Info("Scan complete, __NEXT_DATA__ not found but initial scripts were present", "target", result.BaseURL)
 	} else {
-		log.Printf("Scan complete. Routes: %d, Assets (final combined): %d", len(result.Routes), len(combinedJSAssets))
+		slog.Info("Scan complete", "target", result.BaseURL, "routes", len(result.Routes), "assets", len(combinedJSAssets))
 	}
 
+	// A version string alone is weak evidence (any bundle may contain "15.0.0"),
+	// so it only promotes the target when the page also loads _next/static chunks
 	if !result.IsNextJS {
 		versionFound := result.DetectedNextVersion
-		if versionFound != "" && !strings.HasPrefix(versionFound, "Unknown") && !strings.Contains(versionFound, "Likely") {
+		versionMatched := versionFound != "" && versionFound != VersionSkipped && !result.NextVersionFallback && !strings.HasPrefix(versionFound, "Unknown") && !strings.Contains(versionFound, "Likely")
+		if versionMatched && !hasNextStaticScripts(initialScriptURLs) {
+			log.Printf("Ignoring detected version '%s': the page loads no _next/static scripts.", versionFound)
+		} else if versionMatched {
 			log.Printf("Setting IsNextJS=true based on detected version '%s' despite missing __NEXT_DATA__.", versionFound)
 			result.IsNextJS = true
-			if finalError != nil && errors.Is(finalError, errors.New("__NEXT_DATA__ script tag not found")) {
+			if finalError != nil && errors.Is(finalError, ErrNextDataNotFound) {
 				finalError = nil
 			} else if finalError != nil && strings.Contains(finalError.Error(), "Unsupported deployment") {
 				finalError = nil
@@ -572,118 +1469,156 @@ func (s *Scanner) ScanTarget(initialTargetURL string) (*ScanResult, error) {
 
 	result.ExecutionError = finalError
 
+	if s.cache != nil && finalError == nil && result.BuildID != "" {
+		if err := s.cache.Put(baseURL.Host, s.cacheOptions(), &result); err != nil {
+			log.Printf("Warning: Failed to cache the result: %v", err)
+		}
+	}
+
 	return &result, finalError
 }
 
+// analyzePageHeaders sets the fields of result derived from the headers of
+// the target page: the runtime, the CSP, the security headers and the
+// preview cookies. They are never taken from the cache, since headers change
+// without a new deployment.
+func analyzePageHeaders(result *ScanResult, headers http.Header, middlewareManifestFound bool) {
+	result.Runtime, result.RuntimeSignals = detectRuntime(headers, middlewareManifestFound)
+
+	result.CSP = parseCSP(headers)
+	if result.CSP != nil {
+		log.Printf("Content Security Policy: %s", formatCSP(result.CSP))
+	}
+	result.SecurityHeaders, result.MissingSecurityHeaders = parseSecurityHeaders(headers, result.CSP)
+	if len(result.MissingSecurityHeaders) > 0 {
+		log.Printf("Missing security headers: %s", strings.Join(result.MissingSecurityHeaders, ", "))
+	}
+	log.Printf("Detected runtime: %s", result.Runtime)
+	result.PreviewCookies = previewCookies(headers)
+}
+
+// hasNextStaticScripts reports whether any of the page scripts is served
+// from a _next/static directory, as opposed to only matching AssetPattern.
+func hasNextStaticScripts(scriptURLs map[string]bool) bool {
+	for scriptURL := range scriptURLs {
+		if strings.Contains(scriptURL, "/_next/static/") {
+			return true
+		}
+	}
+	return false
+}
+
+// assetHosts returns the distinct hostnames of the given asset URLs, sorted,
+// or nil if there are none.
+func assetHosts(assetSets ...map[string]bool) []string {
+	hosts := make(map[string]bool)
+	for _, assets := range assetSets {
+		for assetURL := range assets {
+			if u, err := url.Parse(assetURL); err == nil && u.Hostname() != "" {
+				hosts[strings.ToLower(u.Hostname())] = true
+			}
+		}
+	}
+	return sortedSet(hosts)
+}
+
+// marshalJSON encodes the scan result, indented for readability unless
+// compact is set.
+func marshalJSON(result *ScanResult, compact bool) ([]byte, error) {
+	if compact {
+		return json.Marshal(result)
+	}
+	return json.MarshalIndent(result, "", "  ")
+}
+
 // PrintResults formats and prints the scan results.
-func PrintResults(result *ScanResult, outputFormat string) error {
+// compact prints JSON on a single line instead of indenting it.
+// The "routes" format prints only the sorted route paths, one per line.
+func PrintResults(result *ScanResult, outputFormat string, compact bool) error {
 	switch outputFormat {
 	case "json":
-		outJSON, err := json.MarshalIndent(result, "", "  ")
+		outJSON, err := marshalJSON(result, compact)
 		if err != nil {
 			return fmt.Errorf("failed to marshal result to JSON: %w", err)
 		}
 		fmt.Println(string(outJSON))
-	case "text":
-		// Define colors (will automatically handle non-TTY environments)
-		title := color.New(color.FgWhite, color.Bold).SprintfFunc()
-		label := color.New(color.FgYellow).SprintFunc()
-		value := color.New(color.FgCyan).SprintFunc()
-		valBoolTrue := color.New(color.FgGreen).SprintFunc()
-		valBoolFalse := color.New(color.FgRed).SprintFunc()
-		errorText := color.New(color.FgRed).SprintFunc()
-		routePath := color.New(color.FgMagenta).SprintFunc()
-		assetCount := color.New(color.FgBlue).SprintfFunc()
-
-		fmt.Printf("%s: %s\n", title("Scan Results for"), value(result.BaseURL))
-		fmt.Printf("%s %s\n", label("Is Next.js:"), formatBool(result.IsNextJS, valBoolTrue, valBoolFalse))
-
-		if result.IsNextJS {
-			fmt.Printf("%s %s\n", label("Build ID:"), value(result.BuildID))
-			fmt.Printf("%s %s\n", label("Detected Next.js Version:"), value(result.DetectedNextVersion))
-			fmt.Printf("%s %s\n", label("Detected React Version:"), value(result.DetectedReactVersion))
-			fmt.Printf("%s %s\n", label("Asset Prefix:"), value(result.AssetPrefix))
-			fmt.Printf("%s %s\n", label("Calculated Asset Base URL:"), value(result.AssetBaseURL))
-			fmt.Printf("%s %s\n", label("Build Manifest Found:"), formatBool(result.ManifestFound, valBoolTrue, valBoolFalse))
-			fmt.Printf("%s %s\n", label("Build Manifest Executed OK:"), formatBool(result.ManifestExecOK, valBoolTrue, valBoolFalse))
-
-			if result.ExecutionError != nil {
-				fmt.Printf("%s %s\n", label("Execution Error:"), errorText("\n"+result.ExecutionError.Error()))
-			} else {
-				fmt.Printf("%s (%s routes found):\n", label("Routes"), value(len(result.Routes)))
-				routeKeys := make([]string, 0, len(result.Routes))
-				for route := range result.Routes {
-					routeKeys = append(routeKeys, route)
-				}
-				sort.Strings(routeKeys)
-
-				for _, route := range routeKeys {
-					assetNumStr := assetCount("(%d assets)", len(result.Routes[route]))
-					fmt.Printf("  - %s %s\n", routePath(route), assetNumStr)
-				}
-				fmt.Printf("%s %s unique assets from manifest.\n", label("Found"), value(len(result.AllAssets)))
-			}
-		}
-		if result.NextDataJSONRaw != "" && !result.IsNextJS {
-			fmt.Printf("\n%s\n%s\n", label("Raw __NEXT_DATA__ (found but potentially invalid):"), result.NextDataJSONRaw)
+	case "markdown":
+		report, err := RenderMarkdown(result)
+		if err != nil {
+			return err
 		}
+		fmt.Print(report)
+	case "routes":
+		fmt.Print(formatRouteList(result))
+	case "table":
+		return RenderTable([]*ScanResult{result}, os.Stdout)
+	case "text":
+		RenderText(result, os.Stdout, true)
 	default:
 		return fmt.Errorf("unknown output format: %s", outputFormat)
 	}
 	return nil
 }
 
-// formatBool helper for colorizing boolean output
-func formatBool(b bool, trueColorFunc, falseColorFunc func(a ...interface{}) string) string {
-	if b {
-		return trueColorFunc("true")
+// formatVersion renders a detected version, spelling out why it is missing
+// when version detection was skipped.
+func formatVersion(version string) string {
+	if version == VersionSkipped {
+		return "Skipped (version detection disabled)"
 	}
-	return falseColorFunc("false")
+	return version
+}
+
+// formatCandidate describes how a version candidate was found, e.g.
+// "(context, confidence 2, 3 occurrences in 2 chunks)".
+func formatCandidate(candidate versiondetect.Candidate) string {
+	return fmt.Sprintf("(%s, confidence %d, %d occurrences in %d chunks)", candidate.Method, candidate.Confidence, candidate.Count, len(candidate.Assets))
+}
+
+// formatTiming summarizes the request timings of a scan, rounded to the millisecond.
+func formatTiming(result *ScanResult) string {
+	timing := fmt.Sprintf("HTML fetched in %s, %d requests (%s cumulative), scan took %s",
+		result.HTMLFetchDuration.Round(time.Millisecond), result.FetchCount,
+		result.FetchDuration.Round(time.Millisecond), result.TotalScanDuration.Round(time.Millisecond))
+	if result.ManifestExecDuration > 0 {
+		timing += fmt.Sprintf(", manifest evaluated in %s", result.ManifestExecDuration.Round(time.Millisecond))
+	}
+	if result.FromCache {
+		timing += ", rest of the result from cache"
+	}
+	return timing
 }
 
 // WriteOutput formats and writes the scan results to a file.
 // It defaults to JSON but can write text if specified.
-func WriteOutput(result *ScanResult, outputFile string, outputFormat string) error {
+// compact writes JSON on a single line instead of indenting it.
+func WriteOutput(result *ScanResult, outputFile string, outputFormat string, compact bool) error {
 	var outputBytes []byte
 	var err error
 
 	if outputFormat == "json" {
-		outputBytes, err = json.MarshalIndent(result, "", "  ")
+		outputBytes, err = marshalJSON(result, compact)
 		if err != nil {
 			return fmt.Errorf("failed to marshal result to JSON for file output: %w", err)
 		}
-	} else if outputFormat == "text" {
-		var sb strings.Builder
-		sb.WriteString(fmt.Sprintf("Scan Results for: %s\n", result.BaseURL))
-		sb.WriteString(fmt.Sprintf("Is Next.js: %t\n", result.IsNextJS))
-		if result.IsNextJS {
-			sb.WriteString(fmt.Sprintf("Build ID: %s\n", result.BuildID))
-			sb.WriteString(fmt.Sprintf("Detected Next.js Version: %s\n", result.DetectedNextVersion))
-			sb.WriteString(fmt.Sprintf("Detected React Version: %s\n", result.DetectedReactVersion))  
-			sb.WriteString(fmt.Sprintf("Asset Prefix: %s\n", result.AssetPrefix))
-			sb.WriteString(fmt.Sprintf("Calculated Asset Base URL: %s\n", result.AssetBaseURL))
-			sb.WriteString(fmt.Sprintf("Build Manifest Found: %t\n", result.ManifestFound))
-			sb.WriteString(fmt.Sprintf("Build Manifest Executed OK: %t\n", result.ManifestExecOK))
-			if result.ExecutionError != nil {
-				sb.WriteString(fmt.Sprintf("Execution Error: %v\n", result.ExecutionError))
-			} else {
-				sb.WriteString(fmt.Sprintf("Found %d Routes:\n", len(result.Routes)))
-				routeKeys := make([]string, 0, len(result.Routes))
-				for route := range result.Routes {
-					routeKeys = append(routeKeys, route)
-				}
-				sort.Strings(routeKeys)
-
-				for _, route := range routeKeys {
-					sb.WriteString(fmt.Sprintf("  - %s (%d assets)\n", route, len(result.Routes[route])))
-				}
-				sb.WriteString(fmt.Sprintf("Found %d Unique Assets from manifest.\n", len(result.AllAssets)))
-			}
+	} else if outputFormat == "markdown" {
+		report, renderErr := RenderMarkdown(result)
+		if renderErr != nil {
+			return renderErr
 		}
-		if result.NextDataJSONRaw != "" && !result.IsNextJS {
-			sb.WriteString(fmt.Sprintf("\nRaw __NEXT_DATA__ (found but potentially invalid):\n%s\n", result.NextDataJSONRaw))
+		outputBytes = []byte(report)
+	} else if outputFormat == "routes" {
+		outputBytes = []byte(formatRouteList(result))
+	} else if outputFormat == "table" {
+		var sb strings.Builder
+		if err := RenderTable([]*ScanResult{result}, &sb); err != nil {
+			return err
 		}
 		outputBytes = []byte(sb.String())
+	} else if outputFormat == "text" {
+		var sb strings.Builder
+		RenderText(result, &sb, false)
+		outputBytes = []byte(sb.String())
 	} else {
 		return fmt.Errorf("unknown output format for file writing: %s", outputFormat)
 	}
@@ -0,0 +1,831 @@
+package scanner
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigopv/nextr4y/internal/fetch"
+	"github.com/rodrigopv/nextr4y/internal/versiondetect"
+)
+
+func TestExtractRoutesAndAssets_DeduplicatesNearDuplicatePaths(t *testing.T) {
+	t.Parallel()
+
+	manifest := map[string]interface{}{
+		"/": []interface{}{
+			"static/chunks/main-abc123.js",
+			"/static/chunks/main-abc123.js",
+			"static//chunks/./main-abc123.js",
+			"static/chunks/pages/../main-abc123.js",
+			"static/css/app-def456.css",
+		},
+		"/blog/[slug]": []interface{}{
+			"static/chunks/pages/blog/[slug]-789.js",
+			"static/chunks/pages/blog/%5Bslug%5D-789.js",
+			"static/chunks/main-abc123.js",
+		},
+		"__rewrites":  map[string]interface{}{},
+		"sortedPages": []interface{}{"/", "/blog/[slug]"},
+	}
+
+	routes, allAssets := extractRoutesAndAssets(manifest, "https://Example.COM//")
+
+	require.Len(t, allAssets, 3)
+	require.Len(t, routes, 2)
+	require.Len(t, routes["/"], 2)
+	require.Len(t, routes["/blog/[slug]"], 2)
+	require.Contains(t, allAssets, "https://example.com/_next/static/chunks/main-abc123.js")
+	require.Contains(t, routes["/"], "https://example.com/_next/static/css/app-def456.css")
+}
+
+func TestScanTarget_FallsBackToRenderFetcher(t *testing.T) {
+	t.Parallel()
+
+	const target = "https://spa.example.com/"
+	static := &stubFetcher{pages: map[string]string{
+		target: `<html><body><div id="root"></div><script src="/bundle.js"></script></body></html>`,
+	}}
+	rendered := &stubFetcher{pages: map[string]string{
+		target: `<html><body><script id="__NEXT_DATA__" type="application/json">{"buildId":"abc123","props":{}}</script></body></html>`,
+	}}
+
+	scr := NewScannerWithOptions(static, stubDetector{}, Options{RenderFetcher: rendered})
+	result, _ := scr.ScanTarget(target)
+
+	require.NotNil(t, result)
+	require.True(t, result.IsNextJS)
+	require.Equal(t, "abc123", result.BuildID)
+}
+
+func TestScanTarget_ManifestExecutionTimesOut(t *testing.T) {
+	t.Parallel()
+
+	const target = "https://loop.example.com/"
+	fetcher := &stubFetcher{pages: map[string]string{
+		target: `<html><body><script id="__NEXT_DATA__" type="application/json">{"buildId":"loop","props":{}}</script></body></html>`,
+		"https://loop.example.com/_next/static/loop/_buildManifest.js": `self.__BUILD_MANIFEST=function(a){while(true){}return {"/":[a]}}("static/chunks/a.js");`,
+	}}
+
+	scr := NewScannerWithOptions(fetcher, stubDetector{}, Options{ManifestExecTimeout: 50 * time.Millisecond})
+
+	done := make(chan struct{})
+	var result *ScanResult
+	var err error
+	go func() {
+		result, err = scr.ScanTarget(target)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("scan did not return; manifest execution was not interrupted")
+	}
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "timed out")
+	require.True(t, result.ManifestFound)
+	require.False(t, result.ManifestExecOK)
+	require.GreaterOrEqual(t, result.ManifestExecDuration, 50*time.Millisecond)
+}
+
+func TestExecuteManifestJS_ValidManifest(t *testing.T) {
+	t.Parallel()
+
+	manifest, err := executeManifestJS(sampleManifestJS, time.Second)
+
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{"static/chunks/1-xyz.js", "static/chunks/pages/index-abc.js"}, manifest["/"])
+}
+
+func TestExecuteManifestJS_MalformedManifests(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		manifest string
+	}{
+		{name: "Empty body", manifest: ""},
+		{name: "Garbage", manifest: "}{)(][;;"},
+		{name: "Dangling assignment", manifest: "self.__BUILD_MANIFEST = "},
+		{name: "Not an object", manifest: "self.__BUILD_MANIFEST = alert(1)"},
+		{name: "Throws", manifest: `self.__BUILD_MANIFEST = function(){throw new Error("boom")}()`},
+		{name: "Uses removed global", manifest: `self.__BUILD_MANIFEST = {"/": [Date.now()]}`},
+		{name: "Calls eval", manifest: `self.__BUILD_MANIFEST = function(){return {"/": eval("1")}}()`},
+		{name: "Unbounded recursion", manifest: `self.__BUILD_MANIFEST = function(){function f(){return f()} return {"/": f()}}()`},
+		{name: "Infinite loop", manifest: `self.__BUILD_MANIFEST = function(){for(;;){} return {}}()`},
+		{name: "Returns string", manifest: `self.__BUILD_MANIFEST = function(){return "x"}()`},
+		{name: "HTML error page", manifest: "<html><body>403 Forbidden</body></html>"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			manifest, err := executeManifestJS(tc.manifest, 200*time.Millisecond)
+
+			require.Error(t, err)
+			require.Nil(t, manifest)
+		})
+	}
+}
+
+func FuzzExecuteManifestJS(f *testing.F) {
+	f.Add(sampleManifestJS)
+	f.Add(`self.__BUILD_MANIFEST = {"/": ["static/a.js"]}`)
+	f.Add(`self.__BUILD_MANIFEST = function(){while(true){}}()`)
+	f.Add("self.__BUILD_MANIFEST = ({")
+
+	f.Fuzz(func(t *testing.T, manifest string) {
+		// Must never panic or hang; errors are expected for most inputs.
+		_, _ = executeManifestJS(manifest, 100*time.Millisecond)
+	})
+}
+
+func TestFindAndParseNextData_Locales(t *testing.T) {
+	t.Parallel()
+
+	html := `<html><body><script id="__NEXT_DATA__" type="application/json">` +
+		`{"buildId":"b1","props":{},"locale":"fr","locales":["en","fr","de"],"defaultLocale":"en"}` +
+		`</script></body></html>`
+
+	nextData, _, err := findAndParseNextData(strings.NewReader(html))
+	require.NoError(t, err)
+
+	var result ScanResult
+	applyNextData(&result, nextData)
+
+	require.Equal(t, "fr", result.Locale)
+	require.Equal(t, []string{"en", "fr", "de"}, result.Locales)
+	require.Equal(t, "en", result.DefaultLocale)
+	require.Equal(t, []string{"/fr", "/de"}, result.LocalePrefixes)
+}
+
+func TestFindAndParseNextData_Truncated(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name            string
+		json            string
+		wantBuildID     string
+		wantAssetPrefix string
+	}{
+		{name: "Cut after buildId", json: `{"props":{"pageProps":{}},"buildId":"b1","assetPrefix":"/cdn","locales":["en","f`, wantBuildID: "b1", wantAssetPrefix: "/cdn"},
+		{name: "Cut inside assetPrefix", json: `{"props":{},"buildId":"b\u002d1","assetPrefix":"/cd`, wantBuildID: "b-1"},
+		{name: "Cut before buildId", json: `{"props":{"pageProps":{"items":[1,2`},
+	}
+
+	for _, tc := range testCases {
+		html := `<html><body><script id="__NEXT_DATA__" type="application/json">` + tc.json
+
+		nextData, raw, err := findAndParseNextData(strings.NewReader(html))
+
+		require.Error(t, err, tc.name)
+		require.Equal(t, tc.json, raw, tc.name)
+		if tc.wantBuildID == "" {
+			require.Nil(t, nextData, tc.name)
+			continue
+		}
+		require.NotNil(t, nextData, tc.name)
+		require.Equal(t, tc.wantBuildID, nextData.BuildID, tc.name)
+		require.Equal(t, tc.wantAssetPrefix, nextData.AssetPrefix, tc.name)
+	}
+}
+
+func TestScanTarget_TruncatedNextData(t *testing.T) {
+	t.Parallel()
+
+	html, err := os.ReadFile("testdata/truncated_next_data.html")
+	require.NoError(t, err)
+
+	const target = "https://shop.example.com/"
+	fetcher := &stubFetcher{pages: map[string]string{
+		target: string(html),
+		target + "static-assets/_next/static/tRuNc4t3d/_buildManifest.js": sampleManifestJS,
+	}}
+	scr := NewScannerWithOptions(fetcher, stubDetector{}, Options{})
+
+	result, err := scr.ScanTarget(target)
+
+	// The truncation is still reported, alongside the recovered results
+	require.ErrorContains(t, err, "truncated")
+	require.True(t, result.IsNextJS)
+	require.Equal(t, "tRuNc4t3d", result.BuildID)
+	require.Equal(t, "/static-assets", result.AssetPrefix)
+	require.True(t, result.ManifestExecOK)
+	require.Positive(t, result.ManifestExecDuration)
+	require.Contains(t, result.NextDataJSONRaw, `"locales":["en","fr","d`)
+}
+
+func TestScanTarget_SchemeForBareHosts(t *testing.T) {
+	t.Parallel()
+
+	const page = `<html><body><script id="__NEXT_DATA__" type="application/json">{"buildId":"b1","props":{}}</script></body></html>`
+
+	testCases := []struct {
+		name        string
+		scheme      string
+		pages       map[string]string
+		wantBaseURL string
+		wantErr     bool
+	}{
+		{name: "Auto prefers HTTPS", scheme: SchemeAuto, pages: map[string]string{"https://both.local": page, "http://both.local": page}, wantBaseURL: "https://both.local"},
+		{name: "Auto falls back to HTTP", scheme: SchemeAuto, pages: map[string]string{"http://both.local": page}, wantBaseURL: "http://both.local"},
+		{name: "Forced HTTPS does not fall back", scheme: SchemeHTTPS, pages: map[string]string{"http://both.local": page}, wantBaseURL: "https://both.local", wantErr: true},
+		{name: "Forced HTTP", scheme: SchemeHTTP, pages: map[string]string{"https://both.local": page, "http://both.local": page}, wantBaseURL: "http://both.local"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			scr := NewScannerWithOptions(&stubFetcher{pages: tc.pages}, stubDetector{}, Options{Scheme: tc.scheme})
+			result, err := scr.ScanTarget("both.local")
+
+			require.NotNil(t, result)
+			require.Equal(t, tc.wantBaseURL, result.BaseURL)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.Equal(t, "b1", result.BuildID)
+		})
+	}
+}
+
+func TestNormalizeBaseURL(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "https://cdn.example.com", want: "https://cdn.example.com/"},
+		{input: "https://cdn.example.com/", want: "https://cdn.example.com/"},
+		{input: "https://CDN.example.com/assets", want: "https://cdn.example.com/assets/"},
+		{input: "HTTPS://cdn.example.com/assets/", want: "https://cdn.example.com/assets/"},
+		{input: " https://cdn.example.com//assets/./v2 ", want: "https://cdn.example.com/assets/v2/"},
+		{input: "cdn.example.com", wantErr: true},
+		{input: "/assets", wantErr: true},
+		{input: "ftp://cdn.example.com", wantErr: true},
+		{input: "https://", wantErr: true},
+		{input: "https://cdn.example.com/?v=1", wantErr: true},
+		{input: "https://cdn.example.com/%zz", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		got, err := NormalizeBaseURL(tc.input)
+		if tc.wantErr {
+			require.Error(t, err, tc.input)
+			continue
+		}
+		require.NoError(t, err, tc.input)
+		require.Equal(t, tc.want, got, tc.input)
+	}
+}
+
+func TestScanTarget_CustomBaseURL(t *testing.T) {
+	t.Parallel()
+
+	const target = "https://www.example.com/"
+	nextData := func(assetPrefix string) string {
+		return `<html><body><script id="__NEXT_DATA__" type="application/json">{"buildId":"b1","assetPrefix":"` + assetPrefix + `","props":{}}</script></body></html>`
+	}
+
+	testCases := []struct {
+		name          string
+		baseURL       string
+		assetPrefix   string
+		wantAssetBase string
+	}{
+		{name: "Host only", baseURL: "https://cdn.example.com", wantAssetBase: "https://cdn.example.com/"},
+		{name: "Host with trailing slash", baseURL: "https://cdn.example.com/", wantAssetBase: "https://cdn.example.com/"},
+		{name: "Path without trailing slash", baseURL: "https://cdn.example.com/assets", wantAssetBase: "https://cdn.example.com/assets/"},
+		{name: "Path with trailing slash", baseURL: "https://cdn.example.com/assets/", wantAssetBase: "https://cdn.example.com/assets/"},
+		{name: "Path and asset prefix", baseURL: "https://cdn.example.com/assets", assetPrefix: "/app", wantAssetBase: "https://cdn.example.com/assets/app/"},
+		{name: "Path and absolute asset prefix", baseURL: "https://cdn.example.com/assets/", assetPrefix: "https://other.example.com/app/", wantAssetBase: "https://cdn.example.com/assets/app/"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			fetcher := &stubFetcher{pages: map[string]string{
+				target: nextData(tc.assetPrefix),
+				tc.wantAssetBase + "_next/static/b1/_buildManifest.js": sampleManifestJS,
+			}}
+			scr := NewScannerWithOptions(fetcher, stubDetector{}, Options{CustomBaseURL: tc.baseURL})
+
+			result, err := scr.ScanTarget(target)
+
+			require.NoError(t, err)
+			require.Equal(t, tc.wantAssetBase, result.AssetBaseURL)
+			require.True(t, result.ManifestExecOK)
+		})
+	}
+}
+
+func TestScanTarget_RejectsInvalidCustomBaseURL(t *testing.T) {
+	t.Parallel()
+
+	scr := NewScannerWithOptions(&stubFetcher{}, stubDetector{}, Options{CustomBaseURL: "cdn.example.com/assets"})
+
+	result, err := scr.ScanTarget("https://www.example.com/")
+
+	require.Nil(t, result)
+	require.ErrorContains(t, err, "must be an absolute")
+}
+
+func TestResolveAssetBaseURL(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		pageURL       string
+		customBaseURL string
+		assetPrefix   string
+		basePath      string
+		want          string
+	}{
+		{name: "No prefix", pageURL: "https://example.com/blog/post", want: "https://example.com/blog/post"},
+		{name: "Base path", pageURL: "https://example.com/docs/guide/intro", basePath: "/docs", want: "https://example.com/docs/"},
+		{name: "Prefix takes precedence over base path", pageURL: "https://example.com/docs/", assetPrefix: "https://cdn.example.com/assets", basePath: "/docs", want: "https://cdn.example.com/assets/"},
+		{name: "Custom base takes precedence over base path", pageURL: "https://example.com/docs/", customBaseURL: "https://mirror.example.com/", basePath: "/docs", want: "https://mirror.example.com/"},
+		{name: "Absolute prefix", pageURL: "https://example.com/", assetPrefix: "https://cdn.example.com/assets", want: "https://cdn.example.com/assets/"},
+		{name: "Absolute prefix on a subpath page", pageURL: "https://example.com/blog/post", assetPrefix: "https://cdn.example.com/assets/", want: "https://cdn.example.com/assets/"},
+		{name: "Protocol-relative prefix", pageURL: "http://example.com/", assetPrefix: "//cdn.example.com/assets", want: "http://cdn.example.com/assets/"},
+		{name: "Relative prefix", pageURL: "https://example.com/", assetPrefix: "/docs", want: "https://example.com/docs/"},
+		{name: "Relative prefix on a subpath page", pageURL: "https://example.com/docs/guide/intro?ref=1", assetPrefix: "/docs", want: "https://example.com/docs/"},
+		{name: "Relative prefix without leading slash", pageURL: "https://example.com/blog/post", assetPrefix: "cdn-cgi/", want: "https://example.com/cdn-cgi/"},
+		{name: "Custom base", pageURL: "https://example.com/", customBaseURL: "https://mirror.example.com/", want: "https://mirror.example.com/"},
+		{name: "Custom base with relative prefix", pageURL: "https://example.com/", customBaseURL: "https://mirror.example.com/", assetPrefix: "/docs", want: "https://mirror.example.com/docs/"},
+		{name: "Custom base subpath with relative prefix", pageURL: "https://example.com/", customBaseURL: "https://mirror.example.com/assets/", assetPrefix: "/app", want: "https://mirror.example.com/assets/app/"},
+		{name: "Custom base subpath already ending in prefix", pageURL: "https://example.com/", customBaseURL: "https://mirror.example.com/assets/app/", assetPrefix: "/app", want: "https://mirror.example.com/assets/app/"},
+		{name: "Custom base with prefix on a different host", pageURL: "https://example.com/", customBaseURL: "https://mirror.example.com/", assetPrefix: "https://cdn.example.com/app", want: "https://mirror.example.com/app/"},
+		{name: "Custom base subpath already ending in absolute prefix path", pageURL: "https://example.com/", customBaseURL: "https://mirror.example.com/app/", assetPrefix: "https://cdn.example.com/app/", want: "https://mirror.example.com/app/"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			pageURL, err := url.Parse(tc.pageURL)
+			require.NoError(t, err)
+
+			got := resolveAssetBaseURL(pageURL, tc.customBaseURL, tc.assetPrefix, tc.basePath)
+
+			require.Equal(t, tc.want, got.String())
+		})
+	}
+}
+
+func TestCandidateManifestURLs(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		assetBaseURL  string
+		assetPrefix   string
+		customBaseURL string
+		want          []string
+	}{
+		{
+			name:         "Vercel default deployment",
+			assetBaseURL: "https://app.vercel.app/",
+			want:         []string{"https://app.vercel.app/_next/static/b1/_buildManifest.js"},
+		},
+		{
+			name:         "CDN asset prefix",
+			assetBaseURL: "https://cdn.example.com/",
+			assetPrefix:  "https://cdn.example.com",
+			want:         []string{"https://cdn.example.com/_next/static/b1/_buildManifest.js"},
+		},
+		{
+			name:         "Subpath deployment",
+			assetBaseURL: "https://example.com/docs/",
+			assetPrefix:  "/docs",
+			want:         []string{"https://example.com/docs/_next/static/b1/_buildManifest.js"},
+		},
+		{
+			name:         "Asset base already ending in _next",
+			assetBaseURL: "https://example.com/assets/_next/",
+			assetPrefix:  "/assets/_next",
+			want:         []string{"https://example.com/assets/_next/static/b1/_buildManifest.js"},
+		},
+		{
+			name:          "Custom base URL with CDN asset prefix",
+			assetBaseURL:  "https://mirror.example.com/app/",
+			assetPrefix:   "https://cdn.example.com/app",
+			customBaseURL: "https://mirror.example.com/",
+			want: []string{
+				"https://mirror.example.com/app/_next/static/b1/_buildManifest.js",
+				"https://cdn.example.com/app/_next/static/b1/_buildManifest.js",
+			},
+		},
+		{
+			name:          "Custom base URL with domain in relative prefix",
+			assetBaseURL:  "http://mirror.example.com/cdn.example.com/app/",
+			assetPrefix:   "cdn.example.com/app",
+			customBaseURL: "http://mirror.example.com/",
+			want: []string{
+				"http://mirror.example.com/cdn.example.com/app/_next/static/b1/_buildManifest.js",
+				"http://cdn.example.com/app/_next/static/b1/_buildManifest.js",
+			},
+		},
+		{
+			name:          "Custom base URL with plain subpath prefix",
+			assetBaseURL:  "https://mirror.example.com/docs/",
+			assetPrefix:   "/docs",
+			customBaseURL: "https://mirror.example.com/",
+			want:          []string{"https://mirror.example.com/docs/_next/static/b1/_buildManifest.js"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assetBaseURL, err := url.Parse(tc.assetBaseURL)
+			require.NoError(t, err)
+
+			got := candidateManifestURLs(assetBaseURL, tc.assetPrefix, "b1", tc.customBaseURL)
+
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	result := &ScanResult{BaseURL: "https://example.com/", IsNextJS: true, BuildID: "abc"}
+
+	indented, err := marshalJSON(result, false)
+	require.NoError(t, err)
+	require.Contains(t, string(indented), "\n  \"BuildID\": \"abc\"")
+
+	compact, err := marshalJSON(result, true)
+	require.NoError(t, err)
+	require.NotContains(t, string(compact), "\n")
+	require.Contains(t, string(compact), `"BuildID":"abc"`)
+}
+
+func TestScanTarget_IncludeRaw(t *testing.T) {
+	t.Parallel()
+
+	const target = "https://www.example.com/"
+	pages := map[string]string{
+		target: `<html><body><script id="__NEXT_DATA__" type="application/json">{"buildId":"b1","props":{}}</script></body></html>`,
+		target + "_next/static/b1/_buildManifest.js": sampleManifestJS,
+	}
+
+	for _, includeRaw := range []bool{false, true} {
+		scr := NewScannerWithOptions(&stubFetcher{pages: pages}, stubDetector{}, Options{IncludeRaw: includeRaw})
+
+		result, err := scr.ScanTarget(target)
+
+		require.NoError(t, err)
+		require.True(t, result.ManifestExecOK)
+		if !includeRaw {
+			require.Nil(t, result.BuildManifestRaw)
+			continue
+		}
+		require.Equal(t, []interface{}{"/", "/_app", "/_error"}, result.BuildManifestRaw["sortedPages"])
+		require.Contains(t, result.BuildManifestRaw, "__rewrites")
+	}
+}
+
+func TestScanTarget_SkipVersions(t *testing.T) {
+	t.Parallel()
+
+	const target = "https://www.example.com/"
+	fetcher := &stubFetcher{pages: map[string]string{
+		target: `<html><body><script src="/_next/static/chunks/main-1.js"></script>` +
+			`<script id="__NEXT_DATA__" type="application/json">{"buildId":"b1","props":{}}</script></body></html>`,
+		target + "_next/static/b1/_buildManifest.js": sampleManifestJS,
+		target + "_next/static/chunks/main-1.js":     `window.next={version:"14.2.3"}`,
+	}}
+	scr := NewScannerWithOptions(fetcher, &versiondetect.HeuristicAssetScannerDetector{}, Options{SkipVersions: true})
+
+	result, err := scr.ScanTarget(target)
+
+	require.NoError(t, err)
+	require.Equal(t, VersionSkipped, result.DetectedNextVersion)
+	require.Equal(t, VersionSkipped, result.DetectedReactVersion)
+	require.Zero(t, result.ChunkCount, "no chunk should be fetched")
+	require.NotEmpty(t, result.Routes)
+}
+
+func TestScanTarget_RecordsTimings(t *testing.T) {
+	t.Parallel()
+
+	const target = "https://www.example.com/"
+	fetcher := &stubFetcher{pages: map[string]string{
+		target: `<html><body><script id="__NEXT_DATA__" type="application/json">{"buildId":"b1","props":{}}</script></body></html>`,
+		target + "_next/static/b1/_buildManifest.js": sampleManifestJS,
+	}}
+	scr := NewScannerWithOptions(fetcher, stubDetector{}, Options{})
+
+	result, err := scr.ScanTarget(target)
+
+	require.NoError(t, err)
+	require.Equal(t, 3, result.FetchCount) // Page, build manifest and middleware manifest probe
+	require.Positive(t, result.HTMLFetchDuration)
+	require.GreaterOrEqual(t, result.TotalScanDuration, result.HTMLFetchDuration)
+	require.Contains(t, formatTiming(result), "3 requests")
+}
+
+func TestScanTarget_NotNextJS(t *testing.T) {
+	t.Parallel()
+
+	const target = "https://www.example.com/"
+	fetcher := &stubFetcher{pages: map[string]string{target: `<html><body><h1>Hello</h1></body></html>`}}
+	scr := NewScannerWithOptions(fetcher, stubDetector{}, Options{})
+
+	result, err := scr.ScanTarget(target)
+
+	require.ErrorIs(t, err, ErrNextDataNotFound)
+	require.False(t, result.IsNextJS)
+}
+
+// decoyDetector reports a version as if a stray "15.0.0" string had been
+// matched in the page scripts.
+type decoyDetector struct{}
+
+func (decoyDetector) Detect(string, map[string]bool, *url.URL, fetch.Fetcher) versiondetect.Result {
+	return versiondetect.Result{NextVersion: "15.0.0", ReactVersion: "Unknown"}
+}
+
+func TestScanTarget_VersionOnlyPromotesWithNextStaticScripts(t *testing.T) {
+	t.Parallel()
+
+	const target = "https://www.example.com/"
+	const brokenNextData = `<script id="__NEXT_DATA__" type="application/json">{"props":</script>`
+	testCases := []struct {
+		name         string
+		page         string
+		assetPattern *regexp.Regexp
+		wantNextJS   bool
+	}{
+		{
+			name:       "Non-Next site with a decoy version",
+			page:       `<html><body><script src="/js/app.js"></script><script>var v = "15.0.0";</script></body></html>`,
+			wantNextJS: false,
+		},
+		{
+			name:         "Broken __NEXT_DATA__ without _next/static scripts",
+			page:         `<html><body><script src="/assets/app.js"></script>` + brokenNextData + `</body></html>`,
+			assetPattern: regexp.MustCompile(`/assets/`),
+			wantNextJS:   false,
+		},
+		{
+			name:       "Broken __NEXT_DATA__ with _next/static scripts",
+			page:       `<html><body><script src="/_next/static/chunks/main-1.js"></script>` + brokenNextData + `</body></html>`,
+			wantNextJS: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			fetcher := &stubFetcher{pages: map[string]string{target: tc.page}}
+			scr := NewScannerWithOptions(fetcher, decoyDetector{}, Options{AssetPattern: tc.assetPattern})
+
+			result, _ := scr.ScanTarget(target)
+
+			require.NotNil(t, result)
+			require.Equal(t, tc.wantNextJS, result.IsNextJS)
+		})
+	}
+}
+
+func TestFindInitialScriptURLs_QueryStringsAndPrefixes(t *testing.T) {
+	t.Parallel()
+
+	html, err := os.ReadFile("testdata/query_string_chunks.html")
+	require.NoError(t, err)
+	base, err := url.Parse("https://www.example.com/")
+	require.NoError(t, err)
+
+	nextChunks := map[string]bool{
+		"https://www.example.com/_next/static/chunks/webpack-59c5c889f52620d6.js?v=1700000000":     true,
+		"https://www.example.com/_next/static/chunks/framework-2c79e2a64abdb08b.js?dpl=dpl_8xK3mQ": true,
+		"https://assets.example-cdn.net/site/_next/static/chunks/main-f11614d8aa7ee555.js?v=2":     true,
+		"https://www.example.com/_next/static/chunks/pages/_app-0b4c2a3a1b1e8c7d.js?v=1700000000":  true,
+	}
+	require.Equal(t, nextChunks, findInitialScriptURLs(string(html), base, nil))
+
+	withPattern := findInitialScriptURLs(string(html), base, regexp.MustCompile(`/static/bundles/.+\.js`))
+	require.Len(t, withPattern, len(nextChunks)+1)
+	require.True(t, withPattern["https://www.example.com/static/bundles/vendor.3f2a9c.js"])
+}
+
+func TestWriteOutput_Routes(t *testing.T) {
+	t.Parallel()
+
+	result := &ScanResult{
+		BaseURL: "https://example.com/",
+		Routes: map[string][]string{
+			"/blog/[slug]": nil,
+			"/":            nil,
+			"/api/login":   nil,
+		},
+	}
+	outputFile := filepath.Join(t.TempDir(), "routes.txt")
+	require.NoError(t, WriteOutput(result, outputFile, "routes", false))
+
+	content, err := os.ReadFile(outputFile)
+	require.NoError(t, err)
+	require.Equal(t, "/\n/api/login\n/blog/[slug]\n", string(content))
+}
+
+func TestScanTarget_IncludeErrors(t *testing.T) {
+	t.Parallel()
+
+	const target = "https://www.example.com/"
+	pages := map[string]string{
+		target: `<html><body><script src="/_next/static/chunks/main-1.js"></script><script src="/_next/static/chunks/missing-2.js"></script>` +
+			`<script id="__NEXT_DATA__" type="application/json">{"buildId":"b1","props":{}}</script></body></html>`,
+		target + "_next/static/b1/_buildManifest.js": sampleManifestJS,
+		target + "_next/static/chunks/main-1.js":     `console.log("main")`,
+	}
+	missing := target + "_next/static/chunks/missing-2.js"
+
+	testCases := []struct {
+		name          string
+		includeErrors bool
+	}{
+		{name: "enabled", includeErrors: true},
+		{name: "disabled"},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			scr := NewScannerWithOptions(&stubFetcher{pages: pages}, &versiondetect.HeuristicAssetScannerDetector{}, Options{IncludeErrors: tc.includeErrors})
+
+			result, err := scr.ScanTarget(target)
+
+			require.NoError(t, err)
+			if !tc.includeErrors {
+				require.Nil(t, result.AssetErrors)
+				return
+			}
+			require.Contains(t, result.AssetErrors[missing], "404")
+			require.NotContains(t, result.AssetErrors, target+"_next/static/chunks/main-1.js")
+		})
+	}
+}
+
+func TestScanTarget_SkipRoutes(t *testing.T) {
+	t.Parallel()
+
+	const target = "https://www.example.com/"
+	fetcher := &stubFetcher{pages: map[string]string{
+		target: `<html><body><script src="/_next/static/chunks/main-1.js"></script>` +
+			`<script id="__NEXT_DATA__" type="application/json">{"buildId":"b1","props":{}}</script></body></html>`,
+		target + "_next/static/b1/_buildManifest.js": sampleManifestJS,
+		target + "_next/static/chunks/main-1.js":     `console.log("main")`,
+	}}
+	scr := NewScannerWithOptions(fetcher, &versiondetect.HeuristicAssetScannerDetector{}, Options{SkipRoutes: true})
+
+	result, err := scr.ScanTarget(target)
+
+	require.NoError(t, err)
+	require.True(t, result.IsNextJS)
+	require.Equal(t, "b1", result.BuildID)
+	require.False(t, result.ManifestFound, "the build manifest should not be fetched")
+	require.Empty(t, result.Routes)
+	require.Equal(t, map[string]bool{target + "_next/static/chunks/main-1.js": true}, result.AllAssets)
+	require.Equal(t, 1, result.ChunkCount)
+}
+
+func TestScanTarget_HTMLEscapedNextData(t *testing.T) {
+	t.Parallel()
+
+	html, err := os.ReadFile("testdata/escaped_next_data.html")
+	require.NoError(t, err)
+
+	const target = "https://travel.example.com/"
+	fetcher := &stubFetcher{pages: map[string]string{
+		target: string(html),
+		target + "_next/static/Es6cApEd_bUiLd/_buildManifest.js": sampleManifestJS,
+	}}
+	scr := NewScannerWithOptions(fetcher, stubDetector{}, Options{})
+
+	result, err := scr.ScanTarget(target)
+
+	require.NoError(t, err)
+	require.True(t, result.IsNextJS)
+	require.Equal(t, "Es6cApEd_bUiLd", result.BuildID)
+	require.Equal(t, []string{"en", "pt"}, result.Locales)
+	require.Equal(t, RenderStrategySSR, result.RenderStrategy)
+	require.True(t, result.ManifestExecOK)
+	require.Contains(t, result.NextDataJSONRaw, `"searchUrl":"/search?from=LIS&to=JFK"`)
+}
+
+func TestScanTarget_AssetHosts(t *testing.T) {
+	t.Parallel()
+
+	const target = "https://www.example.com/"
+	pages := map[string]string{
+		target: `<html><body><script src="/_next/static/chunks/main-1.js"></script>` +
+			`<script src="https://CDN.example.net/_next/static/chunks/app-2.js"></script>` +
+			`<script id="__NEXT_DATA__" type="application/json">{"buildId":"b1","props":{}}</script></body></html>`,
+		target + "_next/static/b1/_buildManifest.js": sampleManifestJS,
+	}
+
+	scr := NewScannerWithOptions(&stubFetcher{pages: pages}, &versiondetect.HeuristicAssetScannerDetector{}, Options{})
+	result, err := scr.ScanTarget(target)
+	require.NoError(t, err)
+	require.Equal(t, []string{"cdn.example.net", "www.example.com"}, result.AssetHosts)
+}
+
+func TestScanTarget_DebugVersionCandidates(t *testing.T) {
+	t.Parallel()
+
+	const target = "https://www.example.com/"
+	pages := map[string]string{
+		target: `<html><body><script src="/_next/static/chunks/main-1.js"></script>` +
+			`<script id="__NEXT_DATA__" type="application/json">{"buildId":"b1","props":{}}</script></body></html>`,
+		target + "_next/static/b1/_buildManifest.js": sampleManifestJS,
+		target + "_next/static/chunks/main-1.js":     `window.next={version:"14.2.3",appDir:false};`,
+	}
+
+	scr := NewScannerWithOptions(&stubFetcher{pages: pages}, &versiondetect.HeuristicAssetScannerDetector{}, Options{})
+	result, err := scr.ScanTarget(target)
+	require.NoError(t, err)
+	require.Equal(t, "14.2.3", result.DetectedNextVersion)
+	require.Nil(t, result.VersionCandidates)
+
+	scr = NewScannerWithOptions(&stubFetcher{pages: pages}, &versiondetect.HeuristicAssetScannerDetector{}, Options{Debug: true})
+	result, err = scr.ScanTarget(target)
+	require.NoError(t, err)
+	require.NotEmpty(t, result.VersionCandidates)
+	require.Equal(t, versiondetect.ProductNext, result.VersionCandidates[0].Product)
+	require.Equal(t, "14.2.3", result.VersionCandidates[0].Version)
+
+	var text strings.Builder
+	RenderText(result, &text, false)
+	require.Contains(t, text.String(), "Version Candidates (")
+}
+
+func TestScanTarget_NotHTML(t *testing.T) {
+	t.Parallel()
+
+	const target = "https://www.example.com/"
+	fetcher := &statusFetcher{
+		stubFetcher: stubFetcher{pages: map[string]string{target: `{"error":"not found"}`}},
+		headers:     map[string]http.Header{target: {"Content-Type": {"application/json; charset=utf-8"}}},
+	}
+	scr := NewScannerWithOptions(fetcher, &stubDetector{}, Options{})
+	result, err := scr.ScanTarget(target)
+	require.ErrorIs(t, err, ErrNotHTML)
+	require.EqualError(t, err, "scanner: target did not return HTML (got application/json; charset=utf-8)")
+	require.False(t, result.IsNextJS)
+
+	require.True(t, isHTMLContentType("text/html; charset=utf-8"))
+	require.True(t, isHTMLContentType("Application/XHTML+XML"))
+	require.False(t, isHTMLContentType("image/png"))
+}
+
+func TestScanTarget_IncludeHeaders(t *testing.T) {
+	t.Parallel()
+
+	const target = "https://www.example.com/"
+	fetcher := &statusFetcher{
+		stubFetcher: stubFetcher{pages: map[string]string{
+			target: `<html><body><script id="__NEXT_DATA__" type="application/json">{"buildId":"b1","props":{}}</script></body></html>`,
+		}},
+		headers: map[string]http.Header{target: {
+			"Content-Type": {"text/html; charset=utf-8"},
+			"Set-Cookie":   {"session=abc; HttpOnly", "theme=dark"},
+		}},
+	}
+
+	scr := NewScannerWithOptions(fetcher, &stubDetector{}, Options{SkipRoutes: true})
+	result, _ := scr.ScanTarget(target)
+	require.Nil(t, result.ResponseHeaders)
+
+	scr = NewScannerWithOptions(fetcher, &stubDetector{}, Options{SkipRoutes: true, IncludeHeaders: true})
+	result, _ = scr.ScanTarget(target)
+	require.Equal(t, []string{"session=abc; HttpOnly", "theme=dark"}, result.ResponseHeaders["Set-Cookie"])
+	require.Equal(t, []string{"text/html; charset=utf-8"}, result.ResponseHeaders["Content-Type"])
+}
@@ -0,0 +1,66 @@
+package scanner
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// securityHeaderNames are the response headers recommended for any web app,
+// besides the Content Security Policy reported in CSPInfo.
+var securityHeaderNames = []string{
+	"Strict-Transport-Security",
+	"X-Frame-Options",
+	"X-Content-Type-Options",
+	"Referrer-Policy",
+	"Permissions-Policy",
+}
+
+// parseSecurityHeaders returns the recommended security headers sent with
+// the target page, keyed by their canonical name, and the names of those
+// missing, in the order of securityHeaderNames. A CSP frame-ancestors
+// directive stands in for a missing X-Frame-Options, which it supersedes.
+// Both are nil when the fetcher reported no headers.
+func parseSecurityHeaders(headers http.Header, csp *CSPInfo) (present map[string]string, missing []string) {
+	if headers == nil {
+		return nil, nil
+	}
+	present = make(map[string]string)
+	for _, name := range securityHeaderNames {
+		if values := headers.Values(name); len(values) > 0 {
+			present[name] = strings.Join(values, ", ")
+			continue
+		}
+		if name == "X-Frame-Options" && csp != nil && !csp.ReportOnly {
+			if _, ok := csp.Directives["frame-ancestors"]; ok {
+				continue
+			}
+		}
+		missing = append(missing, name)
+	}
+	return present, missing
+}
+
+// formatSecurityHeader renders a recommended security header for text
+// output, e.g. "pass (nosniff)", "pass (CSP frame-ancestors)" or "missing".
+func formatSecurityHeader(result *ScanResult, name string) string {
+	if value, ok := result.SecurityHeaders[name]; ok {
+		return fmt.Sprintf("pass (%s)", value)
+	}
+	for _, missing := range result.MissingSecurityHeaders {
+		if missing == name {
+			return "missing"
+		}
+	}
+	return "pass (CSP frame-ancestors)"
+}
+
+// formatSecurityHeaders summarizes the recommended security headers, e.g.
+// "3/5 present, missing Referrer-Policy, Permissions-Policy".
+func formatSecurityHeaders(result *ScanResult) string {
+	summary := fmt.Sprintf("%d/%d present", len(securityHeaderNames)-len(result.MissingSecurityHeaders), len(securityHeaderNames))
+	if len(result.MissingSecurityHeaders) > 0 {
+		summary += ", missing " + strings.Join(result.MissingSecurityHeaders, ", ")
+	}
+	return summary
+}
@@ -0,0 +1,40 @@
+package scanner
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSecurityHeaders(t *testing.T) {
+	t.Parallel()
+
+	present, missing := parseSecurityHeaders(nil, nil)
+	require.Nil(t, present)
+	require.Nil(t, missing)
+
+	headers := http.Header{}
+	headers.Set("Strict-Transport-Security", "max-age=63072000; includeSubDomains")
+	headers.Set("X-Content-Type-Options", "nosniff")
+	present, missing = parseSecurityHeaders(headers, nil)
+	require.Equal(t, map[string]string{
+		"Strict-Transport-Security": "max-age=63072000; includeSubDomains",
+		"X-Content-Type-Options":    "nosniff",
+	}, present)
+	require.Equal(t, []string{"X-Frame-Options", "Referrer-Policy", "Permissions-Policy"}, missing)
+
+	// frame-ancestors supersedes X-Frame-Options, unless only reported
+	headers.Set("Content-Security-Policy", "frame-ancestors 'none'")
+	_, missing = parseSecurityHeaders(headers, parseCSP(headers))
+	require.Equal(t, []string{"Referrer-Policy", "Permissions-Policy"}, missing)
+	headers.Del("Content-Security-Policy")
+	headers.Set("Content-Security-Policy-Report-Only", "frame-ancestors 'none'")
+	_, missing = parseSecurityHeaders(headers, parseCSP(headers))
+	require.Equal(t, []string{"X-Frame-Options", "Referrer-Policy", "Permissions-Policy"}, missing)
+
+	result := &ScanResult{SecurityHeaders: present, MissingSecurityHeaders: missing}
+	require.Equal(t, "pass (nosniff)", formatSecurityHeader(result, "X-Content-Type-Options"))
+	require.Equal(t, "missing", formatSecurityHeader(result, "Referrer-Policy"))
+	require.Equal(t, "2/5 present, missing X-Frame-Options, Referrer-Policy, Permissions-Policy", formatSecurityHeaders(result))
+}
@@ -0,0 +1,112 @@
+package scanner
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Styling libraries reported in ScanResult.StylingLibraries.
+const (
+	StylingStyledComponents = "styled-components"
+	StylingEmotion          = "Emotion"
+	StylingTailwind         = "Tailwind CSS"
+)
+
+// stylingSignatures match the runtime markers each library leaves in its JS
+// chunks and CSS: style tag attributes, global names and generated CSS.
+var stylingSignatures = map[string]*regexp.Regexp{
+	StylingStyledComponents: regexp.MustCompile(`data-styled|styled-components|SC_ATTR`),
+	StylingEmotion:          regexp.MustCompile(`data-emotion|__emotion_|@emotion/`),
+	StylingTailwind:         regexp.MustCompile(`--tw-[a-z]|tailwindcss`),
+}
+
+// styledComponentsClassRegex matches the "sc-" classes generated by styled-components.
+var styledComponentsClassRegex = regexp.MustCompile(`^sc-[A-Za-z0-9]{3,}`)
+
+// emotionClassRegex matches the "css-<hash>" classes generated by Emotion.
+var emotionClassRegex = regexp.MustCompile(`^css-[a-z0-9]{4,}`)
+
+// tailwindUtilityRegex matches common Tailwind utility classes, optionally
+// behind variants such as "md:" or "hover:".
+var tailwindUtilityRegex = regexp.MustCompile(`^(?:[a-z0-9-]+:)*-?(?:` +
+	`flex|grid|hidden|block|inline-flex|items-(?:start|center|end)|justify-(?:start|center|end|between)|` +
+	`[mp][xytblr]?-(?:\d+(?:\.5)?|auto|px)|gap-\d+|space-[xy]-\d+|[wh]-(?:\d+|full|screen|auto)|` +
+	`text-(?:xs|sm|base|lg|\d?xl|[a-z]+-\d{2,3})|bg-[a-z]+-\d{2,3}|font-(?:medium|semibold|bold)|` +
+	`rounded(?:-[a-z0-9]+)?|shadow(?:-[a-z]+)?|border(?:-[a-z0-9]+)?)$`)
+
+// minTailwindUtilities is the number of distinct utility classes required
+// before an HTML page is considered styled with Tailwind, since a few of them
+// ("flex", "hidden") are common class names on their own.
+const minTailwindUtilities = 8
+
+// stylingDetector records the styling libraries whose signatures appear in
+// the page HTML or in fetched assets. It is safe for concurrent use.
+type stylingDetector struct {
+	mu    sync.Mutex
+	found map[string]bool
+}
+
+// newStylingDetector creates a stylingDetector with nothing detected.
+func newStylingDetector() *stylingDetector {
+	return &stylingDetector{found: make(map[string]bool)}
+}
+
+// inspect implements assetInspector.
+func (d *stylingDetector) inspect(_ string, body []byte) {
+	for library, signature := range stylingSignatures {
+		if signature.Match(body) {
+			d.add(library)
+		}
+	}
+}
+
+// inspectHTML looks for style tags and generated class names in the page.
+func (d *stylingDetector) inspectHTML(doc *goquery.Document) {
+	if doc.Find("style[data-styled]").Length() > 0 {
+		d.add(StylingStyledComponents)
+	}
+	if doc.Find("style[data-emotion]").Length() > 0 {
+		d.add(StylingEmotion)
+	}
+
+	utilities := make(map[string]bool)
+	doc.Find("[class]").Each(func(_ int, s *goquery.Selection) {
+		class, _ := s.Attr("class")
+		for _, name := range strings.Fields(class) {
+			switch {
+			case styledComponentsClassRegex.MatchString(name):
+				d.add(StylingStyledComponents)
+			case emotionClassRegex.MatchString(name):
+				d.add(StylingEmotion)
+			case tailwindUtilityRegex.MatchString(name):
+				utilities[name] = true
+			}
+		}
+	})
+	if len(utilities) >= minTailwindUtilities {
+		d.add(StylingTailwind)
+	}
+}
+
+// Libraries returns the detected libraries, sorted.
+func (d *stylingDetector) Libraries() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	libraries := make([]string, 0, len(d.found))
+	for library := range d.found {
+		libraries = append(libraries, library)
+	}
+	sort.Strings(libraries)
+	return libraries
+}
+
+func (d *stylingDetector) add(library string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.found[library] = true
+}
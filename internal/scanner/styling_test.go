@@ -0,0 +1,55 @@
+package scanner
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStylingDetector(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name   string
+		html   string
+		chunks []string
+		want   []string
+	}{
+		{
+			name: "Plain page",
+			html: `<html><body><div class="header flex hidden">Hi</div></body></html>`,
+			want: []string{},
+		},
+		{
+			name: "Tailwind utility classes",
+			html: `<html><body><div class="flex items-center justify-between px-4 py-2 md:px-8 text-sm font-semibold bg-slate-900 rounded-lg">Hi</div></body></html>`,
+			want: []string{StylingTailwind},
+		},
+		{
+			name: "Generated class names",
+			html: `<html><head><style data-emotion="css 1x2y3z"></style></head><body><div class="sc-bdVaJa kXyZ">Hi</div><p class="css-1x2y3z">Hi</p></body></html>`,
+			want: []string{StylingEmotion, StylingStyledComponents},
+		},
+		{
+			name:   "Chunk signatures",
+			html:   `<html><body></body></html>`,
+			chunks: []string{`var SC_ATTR="data-styled";`, `.a{--tw-ring-offset-width:0px}`},
+			want:   []string{StylingTailwind, StylingStyledComponents},
+		},
+	}
+
+	for _, tc := range testCases {
+		detector := newStylingDetector()
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(tc.html))
+		require.NoError(t, err)
+
+		detector.inspectHTML(doc)
+		for _, chunk := range tc.chunks {
+			detector.inspect("https://example.com/chunk.js", []byte(chunk))
+		}
+
+		require.Equal(t, tc.want, detector.Libraries(), tc.name)
+	}
+}
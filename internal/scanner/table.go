@@ -0,0 +1,62 @@
+package scanner
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"text/tabwriter"
+)
+
+// tableColumns are the headers of the table output.
+var tableColumns = "HOST\tNEXT.JS\tNEXT VERSION\tREACT VERSION\tBUILD ID\tROUTES"
+
+// RenderTable writes the summary of each result as a row of an aligned
+// table, for a quick overview of many targets. Missing values are shown as
+// "-".
+func RenderTable(results []*ScanResult, w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, tableColumns)
+	for _, result := range results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			tableCell(tableHost(result.BaseURL)),
+			formatYesNo(result.IsNextJS),
+			tableCell(result.DetectedNextVersion),
+			tableCell(result.DetectedReactVersion),
+			tableCell(result.BuildID),
+			tableRouteCount(result))
+	}
+	return tw.Flush()
+}
+
+// tableHost returns the host of a result's base URL, or the URL itself if
+// it can't be parsed.
+func tableHost(baseURL string) string {
+	if u, err := url.Parse(baseURL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return baseURL
+}
+
+// tableRouteCount renders the number of routes, "-" when the build manifest
+// was not read.
+func tableRouteCount(result *ScanResult) string {
+	if !result.ManifestExecOK {
+		return "-"
+	}
+	return strconv.Itoa(len(result.Routes))
+}
+
+func tableCell(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func formatYesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
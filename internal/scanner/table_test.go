@@ -0,0 +1,32 @@
+package scanner
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderTable(t *testing.T) {
+	t.Parallel()
+
+	results := []*ScanResult{
+		{
+			BaseURL:              "https://example.com/",
+			IsNextJS:             true,
+			BuildID:              "abc123",
+			DetectedNextVersion:  "14.2.3",
+			DetectedReactVersion: "18.3.1",
+			ManifestExecOK:       true,
+			Routes:               map[string][]string{"/": nil, "/about": nil},
+		},
+		{BaseURL: "https://blog.example.com/"},
+	}
+	var sb strings.Builder
+	require.NoError(t, RenderTable(results, &sb))
+	require.Equal(t, ""+
+		"HOST              NEXT.JS  NEXT VERSION  REACT VERSION  BUILD ID  ROUTES\n"+
+		"example.com       yes      14.2.3        18.3.1         abc123    2\n"+
+		"blog.example.com  no       -             -              -         -\n",
+		sb.String())
+}
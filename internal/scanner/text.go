@@ -0,0 +1,237 @@
+package scanner
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// textStyle holds the color functions of the text output.
+type textStyle struct {
+	title     func(a ...interface{}) string
+	label     func(a ...interface{}) string
+	value     func(a ...interface{}) string
+	boolTrue  func(a ...interface{}) string
+	boolFalse func(a ...interface{}) string
+	errorText func(a ...interface{}) string
+	routePath func(a ...interface{}) string
+	count     func(a ...interface{}) string
+}
+
+// newTextStyle returns the colors of the text output, or plain functions
+// when colorize is false. Colors still follow color.NoColor (non-TTY
+// environments, NO_COLOR, --color=never).
+func newTextStyle(colorize bool) textStyle {
+	paint := func(attributes ...color.Attribute) func(a ...interface{}) string {
+		c := color.New(attributes...)
+		if !colorize {
+			c.DisableColor()
+		}
+		return c.SprintFunc()
+	}
+	return textStyle{
+		title:     paint(color.FgWhite, color.Bold),
+		label:     paint(color.FgYellow),
+		value:     paint(color.FgCyan),
+		boolTrue:  paint(color.FgGreen),
+		boolFalse: paint(color.FgRed),
+		errorText: paint(color.FgRed),
+		routePath: paint(color.FgMagenta),
+		count:     paint(color.FgBlue),
+	}
+}
+
+// RenderText writes the human-readable report of a scan result to w. It is
+// shared by the terminal output (colorize set), the text files written with
+// --output and the MCP text responses, so they never drift apart.
+func RenderText(result *ScanResult, w io.Writer, colorize bool) {
+	style := newTextStyle(colorize)
+	label, value := style.label, style.value
+
+	fmt.Fprintf(w, "%s: %s\n", style.title("Scan Results for"), value(result.BaseURL))
+	if len(result.RedirectChain) > 0 {
+		fmt.Fprintf(w, "%s %s\n", label("Redirect Chain:"), value(strings.Join(result.RedirectChain, " -> ")))
+	}
+	fmt.Fprintf(w, "%s %s\n", label("Timing:"), value(formatTiming(result)))
+	fmt.Fprintf(w, "%s %s\n", label("Is Next.js:"), formatBool(result.IsNextJS, style.boolTrue, style.boolFalse))
+	if result.DeploymentType != "" {
+		fmt.Fprintf(w, "%s %s\n", label("Deployment Type:"), value(result.DeploymentType))
+	}
+	if metadata := formatAppMetadata(result); metadata != "" {
+		fmt.Fprintf(w, "%s %s\n", label("App Metadata:"), value(metadata))
+	}
+
+	if result.PlannedRequests != nil {
+		fmt.Fprintf(w, "%s (%s, dry run):\n", label("Planned Requests"), value(len(result.PlannedRequests)))
+		for _, plannedURL := range result.PlannedRequests {
+			fmt.Fprintf(w, "  - %s\n", value(plannedURL))
+		}
+	} else if result.IsNextJS {
+		fmt.Fprintf(w, "%s %s\n", label("Build ID:"), value(formatBuildID(result)))
+		fmt.Fprintf(w, "%s %s\n", label("Detected Next.js Version:"), value(formatVersion(result.DetectedNextVersion)))
+		fmt.Fprintf(w, "%s %s\n", label("Detected React Version:"), value(formatVersion(result.DetectedReactVersion)))
+		if result.ReactReconcilerVersion != "" {
+			fmt.Fprintf(w, "%s %s\n", label("React Reconciler Version:"), value(result.ReactReconcilerVersion))
+		}
+		if len(result.VersionCandidates) > 0 {
+			fmt.Fprintf(w, "%s (%s):\n", label("Version Candidates"), value(len(result.VersionCandidates)))
+			for _, candidate := range result.VersionCandidates {
+				fmt.Fprintf(w, "  - %s %s %s\n", value(candidate.Product), value(candidate.Version), style.count(formatCandidate(candidate)))
+			}
+		}
+		if result.WebpackVersion != "" {
+			fmt.Fprintf(w, "%s %s\n", label("Webpack Version:"), value(result.WebpackVersion))
+		}
+		if result.ChunkCount > 0 {
+			fmt.Fprintf(w, "%s %s\n", label("JS Bundle:"), value(formatJSBundle(result)))
+		}
+		fmt.Fprintf(w, "%s %s\n", label("Asset Prefix:"), value(result.AssetPrefix))
+		if result.BasePath != "" {
+			fmt.Fprintf(w, "%s %s\n", label("Base Path:"), value(result.BasePath))
+		}
+		fmt.Fprintf(w, "%s %s\n", label("Runtime:"), value(formatRuntime(result)))
+		if result.RenderStrategy != "" {
+			fmt.Fprintf(w, "%s %s\n", label("Render Strategy:"), value(result.RenderStrategy))
+		}
+		if result.CSP != nil {
+			fmt.Fprintf(w, "%s %s\n", label("Content Security Policy:"), value(formatCSP(result.CSP)))
+		}
+		if result.SecurityHeaders != nil {
+			fmt.Fprintf(w, "%s (%s/%s present):\n", label("Security Headers"), value(len(securityHeaderNames)-len(result.MissingSecurityHeaders)), value(len(securityHeaderNames)))
+			for _, name := range securityHeaderNames {
+				if status := formatSecurityHeader(result, name); status == "missing" {
+					fmt.Fprintf(w, "  - %s: %s\n", name, style.errorText(status))
+				} else {
+					fmt.Fprintf(w, "  - %s: %s\n", name, value(status))
+				}
+			}
+		}
+		if len(result.StylingLibraries) > 0 {
+			fmt.Fprintf(w, "%s %s\n", label("Styling:"), value(strings.Join(result.StylingLibraries, ", ")))
+		}
+		if len(result.Locales) > 0 {
+			fmt.Fprintf(w, "%s %s (%s %s)\n", label("Locales:"), value(strings.Join(result.Locales, ", ")), label("default:"), value(result.DefaultLocale))
+			fmt.Fprintf(w, "%s %s\n", label("Scanned Locale:"), value(result.Locale))
+		}
+		fmt.Fprintf(w, "%s %s\n", label("Calculated Asset Base URL:"), value(result.AssetBaseURL))
+		if result.AssetHostBlocked {
+			fmt.Fprintf(w, "%s %s\n", label("Asset Host:"), style.errorText(formatAssetHost(result)))
+		} else if result.AssetHost != "" {
+			fmt.Fprintf(w, "%s %s\n", label("Asset Host:"), value(formatAssetHost(result)))
+		}
+		if len(result.AssetHosts) > 0 {
+			fmt.Fprintf(w, "%s %s\n", label("Asset Hosts:"), value(strings.Join(result.AssetHosts, ", ")))
+		}
+		fmt.Fprintf(w, "%s %s\n", label("Build Manifest Found:"), formatBool(result.ManifestFound, style.boolTrue, style.boolFalse))
+		fmt.Fprintf(w, "%s %s\n", label("Build Manifest Executed OK:"), formatBool(result.ManifestExecOK, style.boolTrue, style.boolFalse))
+
+		if result.ExecutionError != nil {
+			fmt.Fprintf(w, "%s %s\n", label("Execution Error:"), style.errorText(result.ExecutionError.Error()))
+		} else {
+			fmt.Fprintf(w, "%s (%s routes found):\n", label("Routes"), value(len(result.Routes)))
+			for _, route := range sortedKeys(result.Routes) {
+				fmt.Fprintf(w, "  - %s %s\n", style.routePath(route), style.count(fmt.Sprintf("(%d assets)", len(result.Routes[route]))))
+			}
+			if len(result.LocalePrefixes) > 0 {
+				fmt.Fprintf(w, "%s %s\n", label("Routes are also localized under:"), value(strings.Join(result.LocalePrefixes, ", ")))
+			}
+			if len(result.SpecialPages) > 0 {
+				fmt.Fprintf(w, "%s %s\n", label("Special Pages:"), value(strings.Join(sortedKeys(result.SpecialPages), ", ")))
+			}
+			fmt.Fprintf(w, "%s %s unique assets from manifest.\n", label("Found"), value(len(result.AllAssets)))
+			if result.AssetHashes != nil {
+				fmt.Fprintf(w, "%s %s hashed, %s failed (see JSON output for digests)\n", label("Asset Hashes:"), value(len(result.AssetHashes)), value(len(result.AssetHashErrors)))
+			}
+		}
+	}
+	if len(result.ResponseHeaders) > 0 {
+		fmt.Fprintf(w, "%s (%s):\n", label("Response Headers"), value(len(result.ResponseHeaders)))
+		for _, name := range sortedKeys(result.ResponseHeaders) {
+			for _, headerValue := range result.ResponseHeaders[name] {
+				fmt.Fprintf(w, "  - %s: %s\n", name, value(headerValue))
+			}
+		}
+	}
+	if len(result.RobotsDisallow) > 0 {
+		fmt.Fprintf(w, "%s (%s):\n", label("Robots.txt Disallowed Paths"), value(len(result.RobotsDisallow)))
+		for _, p := range result.RobotsDisallow {
+			fmt.Fprintf(w, "  - %s\n", style.routePath(p))
+		}
+	}
+	if len(result.DiscoveredEndpoints) > 0 {
+		fmt.Fprintf(w, "%s (%s):\n", label("Discovered Endpoints"), value(len(result.DiscoveredEndpoints)))
+		for _, endpoint := range result.DiscoveredEndpoints {
+			fmt.Fprintf(w, "  - %s\n", value(endpoint))
+		}
+	}
+	if len(result.EmbeddedKeys) > 0 {
+		fmt.Fprintf(w, "%s (%s services, may be sensitive):\n", label("Embedded Keys"), value(len(result.EmbeddedKeys)))
+		for _, service := range sortedKeys(result.EmbeddedKeys) {
+			fmt.Fprintf(w, "  - %s: %s\n", label(service), value(strings.Join(result.EmbeddedKeys[service], ", ")))
+		}
+	}
+	if result.Instrumentation {
+		fmt.Fprintf(w, "%s %s\n", label("Instrumentation:"), value(strings.Join(result.InstrumentationSignals, ", ")))
+	}
+	if len(result.TelemetryEndpoints) > 0 {
+		fmt.Fprintf(w, "%s (%s):\n", label("Telemetry Endpoints"), value(len(result.TelemetryEndpoints)))
+		for _, endpoint := range result.TelemetryEndpoints {
+			fmt.Fprintf(w, "  - %s\n", value(endpoint))
+		}
+	}
+	if len(result.RuntimeConfig) > 0 {
+		fmt.Fprintf(w, "%s (%s keys):\n", label("Runtime Config"), value(len(result.RuntimeConfig)))
+		for _, line := range formatRuntimeConfig(result.RuntimeConfig) {
+			fmt.Fprintf(w, "  - %s\n", value(line))
+		}
+	}
+	if len(result.AssetErrors) > 0 {
+		fmt.Fprintf(w, "%s (%s):\n", label("Asset Errors"), value(len(result.AssetErrors)))
+		for _, assetURL := range sortedStringKeys(result.AssetErrors) {
+			fmt.Fprintf(w, "  - %s: %s\n", value(assetURL), style.errorText(result.AssetErrors[assetURL]))
+		}
+	}
+	if len(result.SitemapURLs) > 0 {
+		fmt.Fprintf(w, "%s (%s URLs):\n", label("Sitemaps"), value(len(result.SitemapURLs)))
+		for _, sitemapURL := range result.SitemapURLs {
+			fmt.Fprintf(w, "  - %s\n", value(sitemapURL))
+		}
+	}
+	if len(result.ResolvedRoutes) > 0 {
+		fmt.Fprintf(w, "%s (%s):\n", label("Resolved Dynamic Routes"), value(len(result.ResolvedRoutes)))
+		for _, template := range sortedKeys(result.ResolvedRoutes) {
+			fmt.Fprintf(w, "  - %s: %s\n", style.routePath(template), value(strings.Join(result.ResolvedRoutes[template], ", ")))
+		}
+	}
+	if result.DataRoutes != nil {
+		fmt.Fprintf(w, "%s %s\n", label("Data Routes:"), value(formatDataRoutes(result.DataRoutes)))
+	}
+	if result.ImageOptimizer != "" {
+		fmt.Fprintf(w, "%s %s\n", label("Image Optimizer:"), value(formatImageOptimizer(result)))
+	}
+	if result.PreviewEndpoints != nil {
+		fmt.Fprintf(w, "%s %s\n", label("Preview Mode:"), value(formatPreviewMode(result)))
+	}
+	if len(result.PreviewCookies) > 0 {
+		fmt.Fprintf(w, "%s %s\n", label("Preview Cookies:"), value(strings.Join(result.PreviewCookies, ", ")))
+	}
+	if len(result.DiscoveredRoutes) > 0 {
+		fmt.Fprintf(w, "%s (%s):\n", label("Discovered Routes"), value(len(result.DiscoveredRoutes)))
+		for _, route := range result.DiscoveredRoutes {
+			fmt.Fprintf(w, "  - %s (%s)\n", style.routePath(route.Path), formatDiscoveredRoute(route))
+		}
+	}
+	if result.NextDataJSONRaw != "" && !result.IsNextJS {
+		fmt.Fprintf(w, "\n%s\n%s\n", label("Raw __NEXT_DATA__ (found but potentially invalid):"), result.NextDataJSONRaw)
+	}
+}
+
+// formatBool helper for colorizing boolean output
+func formatBool(b bool, trueColorFunc, falseColorFunc func(a ...interface{}) string) string {
+	if b {
+		return trueColorFunc("true")
+	}
+	return falseColorFunc("false")
+}
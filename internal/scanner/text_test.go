@@ -0,0 +1,77 @@
+package scanner
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func TestRenderText_Golden(t *testing.T) {
+	t.Parallel()
+
+	result := &ScanResult{
+		BaseURL:                "https://www.example.com/",
+		AssetBaseURL:           "https://static.example.com/",
+		AssetHost:              "static.example.com",
+		AssetHosts:             []string{"cdn.example.com", "static.example.com"},
+		ResponseHeaders:        map[string][]string{"Content-Type": {"text/html"}, "Set-Cookie": {"a=1; Path=/", "b=2; Path=/"}},
+		SecurityHeaders:        map[string]string{"Strict-Transport-Security": "max-age=63072000", "X-Content-Type-Options": "nosniff"},
+		MissingSecurityHeaders: []string{"X-Frame-Options", "Referrer-Policy", "Permissions-Policy"},
+		IsNextJS:               true,
+		RenderStrategy:         RenderStrategyISR,
+		BuildID:                "V1StGXR8_Z5jdHi6B-myT",
+		BuildIDType:            "nanoid",
+		AssetPrefix:            "https://static.example.com",
+		Routes:                 map[string][]string{"/": {"a.js", "b.js"}, "/blog/[slug]": {"c.js"}},
+		SpecialPages:           map[string][]string{"/_app": {"app.js"}, "/_error": {"error.js"}},
+		AllAssets:              map[string]bool{"a.js": true, "b.js": true, "c.js": true},
+		ManifestFound:          true,
+		ManifestExecOK:         true,
+		DetectedNextVersion:    "14.2.3",
+		DetectedReactVersion:   "18.3.1",
+		ReactReconcilerVersion: "18.3.1",
+		Locale:                 "en",
+		Locales:                []string{"en", "fr"},
+		DefaultLocale:          "en",
+		LocalePrefixes:         []string{"/fr"},
+		RuntimeConfig:          map[string]interface{}{"apiUrl": "https://api.example.com", "beta": true},
+		RedirectChain:          []string{"https://example.com/", "https://www.example.com/"},
+		AssetErrors:            map[string]string{"https://static.example.com/_next/static/chunks/c.js": "404 Not Found"},
+		RobotsDisallow:         []string{"/admin"},
+		SitemapURLs:            []string{"https://www.example.com/blog/hello"},
+		ResolvedRoutes:         map[string][]string{"/blog/[slug]": {"https://www.example.com/blog/hello"}},
+		DataRoutes:             map[string]bool{"/": true, "/blog/[slug]": false},
+		DiscoveredRoutes:       []DiscoveredRoute{{Path: "/api/health", StatusCode: 200}},
+		Runtime:                RuntimeNode,
+		RuntimeSignals:         []string{"x-powered-by: Next.js"},
+		DiscoveredEndpoints:    []string{"/api/graphql"},
+		EmbeddedKeys:           map[string][]string{"google-analytics": {"G-ABC1234567"}},
+		StylingLibraries:       []string{"tailwindcss"},
+		WebpackVersion:         "5.x",
+		ChunkCount:             3,
+		TotalJSBytes:           123456,
+		HTMLFetchDuration:      120 * time.Millisecond,
+		TotalScanDuration:      2 * time.Second,
+		FetchCount:             12,
+		FetchDuration:          3 * time.Second,
+		ManifestExecDuration:   4 * time.Millisecond,
+	}
+
+	var sb strings.Builder
+	RenderText(result, &sb, false)
+
+	golden := filepath.Join("testdata", "render_text.golden")
+	if *updateGolden {
+		require.NoError(t, os.WriteFile(golden, []byte(sb.String()), 0644))
+	}
+	want, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	require.Equal(t, string(want), sb.String())
+}
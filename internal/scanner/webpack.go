@@ -0,0 +1,84 @@
+package scanner
+
+import (
+	"regexp"
+	"sort"
+	"sync"
+)
+
+// webpackRuntimeRegex matches the file name of the webpack runtime chunk
+// ("webpack-<hash>.js" in production builds, "webpack.js" in development).
+var webpackRuntimeRegex = regexp.MustCompile(`/webpack(?:-[0-9a-f]+)?\.js(?:\?.*)?$`)
+
+// webpackVersionCommentRegex matches a version banner left in the runtime,
+// e.g. "/*! webpack 5.90.3 */" or "webpack/5.90.3".
+var webpackVersionCommentRegex = regexp.MustCompile(`webpack[ /@]v?(\d+\.\d+\.\d+)`)
+
+// webpack5Regex and webpack4Regex match the chunk-loading global of each
+// major version, which survives minification: webpack 5 pushes chunks onto
+// self.webpackChunk_N_E, webpack 4 onto window.webpackJsonp.
+var (
+	webpack5Regex = regexp.MustCompile(`webpackChunk[A-Za-z0-9_$]*`)
+	webpack4Regex = regexp.MustCompile(`webpackJsonp[A-Za-z0-9_$]*`)
+)
+
+// findWebpackRuntime returns the URL of the webpack runtime chunk among the
+// initial scripts, or "" if there is none (e.g. Turbopack builds).
+func findWebpackRuntime(scripts map[string]bool) string {
+	var matches []string
+	for script := range scripts {
+		if webpackRuntimeRegex.MatchString(script) {
+			matches = append(matches, script)
+		}
+	}
+	if len(matches) == 0 {
+		return ""
+	}
+	sort.Strings(matches)
+	return matches[0]
+}
+
+// webpackVersion extracts the webpack version from the runtime chunk: the
+// exact version if a banner was kept, otherwise the major version family
+// ("5.x") from its chunk-loading global. It returns "" if neither is found.
+func webpackVersion(runtime []byte) string {
+	if m := webpackVersionCommentRegex.FindSubmatch(runtime); m != nil {
+		return string(m[1])
+	}
+	switch {
+	case webpack5Regex.Match(runtime):
+		return "5.x"
+	case webpack4Regex.Match(runtime):
+		return "4.x"
+	}
+	return ""
+}
+
+// webpackDetector records the webpack version from the runtime chunk when it
+// goes through an inspectingFetcher. It is safe for concurrent use.
+type webpackDetector struct {
+	mu      sync.Mutex
+	seen    bool
+	version string
+}
+
+// inspect implements assetInspector.
+func (d *webpackDetector) inspect(assetURL string, body []byte) {
+	if !webpackRuntimeRegex.MatchString(assetURL) {
+		return
+	}
+	version := webpackVersion(body)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.seen = true
+	if version != "" {
+		d.version = version
+	}
+}
+
+// Version returns the detected version and whether a runtime chunk was inspected.
+func (d *webpackDetector) Version() (string, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.version, d.seen
+}
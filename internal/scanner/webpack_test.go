@@ -0,0 +1,66 @@
+package scanner
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebpackVersion(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		runtime string
+		want    string
+	}{
+		{name: "Version banner", runtime: `/*! webpack 5.90.3 */(()=>{"use strict";var e={}})();`, want: "5.90.3"},
+		{name: "Webpack 5 runtime", runtime: `(()=>{var e,r={};var t=self.webpackChunk_N_E=self.webpackChunk_N_E||[];})();`, want: "5.x"},
+		{name: "Webpack 4 runtime", runtime: `!function(e){var r=window.webpackJsonp_N_E=window.webpackJsonp_N_E||[];}([]);`, want: "4.x"},
+		{name: "Unknown format", runtime: `console.log("hi")`, want: ""},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.want, webpackVersion([]byte(tc.runtime)), tc.name)
+	}
+}
+
+func TestScanTarget_WebpackVersion(t *testing.T) {
+	t.Parallel()
+
+	const target = "https://www.example.com/"
+	testCases := []struct {
+		name   string
+		script string
+		body   string
+		want   string
+	}{
+		{
+			name:   "Webpack runtime chunk",
+			script: "/_next/static/chunks/webpack-0a1b2c3d.js",
+			body:   `(()=>{var t=self.webpackChunk_N_E=self.webpackChunk_N_E||[];})();`,
+			want:   "5.x",
+		},
+		{
+			name:   "Turbopack build",
+			script: "/_next/static/chunks/turbopack-0a1b2c3d.js",
+			body:   `(globalThis.TURBOPACK=globalThis.TURBOPACK||[]).push([]);`,
+			want:   "",
+		},
+	}
+
+	for _, tc := range testCases {
+		fetcher := &stubFetcher{pages: map[string]string{
+			target: `<html><body><script src="` + tc.script + `"></script>` +
+				`<script id="__NEXT_DATA__" type="application/json">{"buildId":"b1","props":{}}</script></body></html>`,
+			strings.TrimSuffix(target, "/") + tc.script: tc.body,
+		}}
+		scr := NewScanner(fetcher, stubDetector{}, "")
+
+		result, err := scr.ScanTarget(target)
+
+		require.Error(t, err, tc.name) // The build manifest is missing
+		require.Equal(t, tc.want, result.WebpackVersion, tc.name)
+	}
+}
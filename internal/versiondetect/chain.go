@@ -0,0 +1,76 @@
+package versiondetect
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/rodrigopv/nextr4y/internal/fetch"
+)
+
+// ChainDetector implements VersionDetector by running several detectors in
+// order and merging their results. Each of the Next.js and React versions is
+// taken from the first detector that found an exact version; when none did,
+// from the first one that found a hint (e.g. ">=13 (App Router Likely)" or a
+// last-resort guess). Detectors after the point where both versions are
+// exact are not run.
+type ChainDetector struct {
+	Detectors []VersionDetector
+}
+
+var _ VersionDetector = (*ChainDetector)(nil)
+
+// NewChainDetector creates a ChainDetector running detectors in the given order.
+func NewChainDetector(detectors ...VersionDetector) *ChainDetector {
+	return &ChainDetector{Detectors: detectors}
+}
+
+var exactVersionRegex = regexp.MustCompile(`^\d+\.\d+\.\d+`)
+
+// Version ranks used to merge results, from least to most precise.
+const (
+	rankUnknown = iota
+	rankHint
+	rankExact
+)
+
+// versionRank grades a version reported by a detector.
+func versionRank(version string, fallback bool) int {
+	switch {
+	case version == "" || strings.HasPrefix(version, "Unknown"):
+		return rankUnknown
+	case fallback || !exactVersionRegex.MatchString(version):
+		return rankHint
+	default:
+		return rankExact
+	}
+}
+
+// Detect implements VersionDetector.
+func (c *ChainDetector) Detect(buildID string, jsAssetURLs map[string]bool, assetBaseURL *url.URL, fetcher fetch.Fetcher) Result {
+	merged := Result{NextVersion: "Unknown", ReactVersion: "Unknown"}
+	nextRank, reactRank := -1, -1
+
+	for _, detector := range c.Detectors {
+		result := detector.Detect(buildID, jsAssetURLs, assetBaseURL, fetcher)
+
+		if rank := versionRank(result.NextVersion, result.NextVersionFallback); rank > nextRank {
+			nextRank = rank
+			merged.NextVersion = result.NextVersion
+			merged.NextVersionFallback = result.NextVersionFallback
+		}
+		if rank := versionRank(result.ReactVersion, false); rank > reactRank {
+			reactRank = rank
+			merged.ReactVersion = result.ReactVersion
+		}
+		if merged.ReactReconcilerVersion == "" {
+			merged.ReactReconcilerVersion = result.ReactReconcilerVersion
+		}
+		merged.Candidates = append(merged.Candidates, result.Candidates...)
+
+		if nextRank == rankExact && reactRank == rankExact {
+			break
+		}
+	}
+	return merged
+}
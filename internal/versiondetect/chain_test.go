@@ -0,0 +1,96 @@
+package versiondetect
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigopv/nextr4y/internal/fetch"
+)
+
+// fixedDetector returns a canned result and counts its calls.
+type fixedDetector struct {
+	result Result
+	calls  int
+}
+
+func (d *fixedDetector) Detect(string, map[string]bool, *url.URL, fetch.Fetcher) Result {
+	d.calls++
+	return d.result
+}
+
+func TestChainDetector(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		results []Result
+		want    Result
+	}{
+		{
+			name: "first exact versions win",
+			results: []Result{
+				{NextVersion: "Unknown", ReactVersion: "18.2.0"},
+				{NextVersion: "14.1.0", ReactVersion: "18.3.1", ReactReconcilerVersion: "0.29.0"},
+				{NextVersion: "14.2.0", ReactVersion: "Unknown"},
+			},
+			want: Result{NextVersion: "14.1.0", ReactVersion: "18.2.0", ReactReconcilerVersion: "0.29.0"},
+		},
+		{
+			name: "exact versions beat earlier hints and guesses",
+			results: []Result{
+				{NextVersion: ">=13 (App Router Likely)", ReactVersion: "Unknown"},
+				{NextVersion: "1.6.2", NextVersionFallback: true, ReactVersion: "Unknown"},
+				{NextVersion: "13.5.6", ReactVersion: "Unknown"},
+			},
+			want: Result{NextVersion: "13.5.6", ReactVersion: "Unknown"},
+		},
+		{
+			name: "hints are kept when nothing better is found",
+			results: []Result{
+				{NextVersion: "Unknown", ReactVersion: "Unknown"},
+				{NextVersion: ">=13 (App Router Likely)", ReactVersion: "Unknown"},
+			},
+			want: Result{NextVersion: ">=13 (App Router Likely)", ReactVersion: "Unknown"},
+		},
+		{
+			name: "last-resort guesses keep their flag",
+			results: []Result{
+				{NextVersion: "1.6.2", NextVersionFallback: true, ReactVersion: "18.2.0"},
+			},
+			want: Result{NextVersion: "1.6.2", NextVersionFallback: true, ReactVersion: "18.2.0"},
+		},
+		{
+			name:    "no detectors",
+			results: nil,
+			want:    Result{NextVersion: "Unknown", ReactVersion: "Unknown"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var detectors []VersionDetector
+			for _, result := range tc.results {
+				detectors = append(detectors, &fixedDetector{result: result})
+			}
+			got := NewChainDetector(detectors...).Detect("", nil, nil, nil)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestChainDetector_StopsOnceBothVersionsAreExact(t *testing.T) {
+	t.Parallel()
+
+	first := &fixedDetector{result: Result{NextVersion: "14.1.0", ReactVersion: "18.2.0"}}
+	second := &fixedDetector{result: Result{NextVersion: "15.0.0", ReactVersion: "19.0.0"}}
+
+	got := NewChainDetector(first, second).Detect("", nil, nil, nil)
+
+	require.Equal(t, "14.1.0", got.NextVersion)
+	require.Equal(t, 1, first.calls)
+	require.Zero(t, second.calls)
+}
@@ -6,12 +6,40 @@ import (
 	"github.com/rodrigopv/nextr4y/internal/fetch"
 )
 
+// Result holds the versions found by a VersionDetector.
+type Result struct {
+	NextVersion            string      // Detected Next.js version or hint (e.g. ">=13 (App Router Likely)")
+	ReactVersion           string      // Detected React version
+	ReactReconcilerVersion string      // React reconciler version (reconcilerVersion), empty if not found
+	NextVersionFallback    bool        // NextVersion is a last-resort guess: no Next.js-specific token was near it
+	Candidates             []Candidate // Every version considered, in the order found, for auditing the choice
+}
+
+// Products a Candidate is a version of.
+const (
+	ProductNext            = "next"
+	ProductReact           = "react"
+	ProductReactReconciler = "react-reconciler"
+)
+
+// Candidate is a version string found during detection, with how it was
+// found. Repeated findings of the same version by the same method are
+// merged into one Candidate.
+type Candidate struct {
+	Product    string   // ProductNext, ProductReact or ProductReactReconciler
+	Version    string   // The version found, e.g. "14.2.3"
+	Method     string   // How it was found, e.g. "window.next", "react-exports", "context" or "fallback"
+	Confidence int      // Weight of Method: the most confident candidate wins, then the most frequent
+	Count      int      // Number of occurrences across the scanned chunks
+	Assets     []string // Chunks it was found in
+}
+
 // VersionDetector defines the interface for strategies that detect Next.js and React versions.
 type VersionDetector interface {
 	// Detect attempts to find the Next.js and React versions using a specific strategy.
 	// It takes the build ID (if known), a map of all JS asset URLs (from HTML and manifest),
 	// the parsed base URL for assets, and a fetcher to retrieve content.
-	// It returns the detected Next.js version and React version strings.
+	// It returns the detected versions.
 	// "Unknown" or a fallback value (like ">=13...") should be returned if detection fails.
-	Detect(buildID string, jsAssetURLs map[string]bool, assetBaseURL *url.URL, fetcher fetch.Fetcher) (nextVersion string, reactVersion string)
+	Detect(buildID string, jsAssetURLs map[string]bool, assetBaseURL *url.URL, fetcher fetch.Fetcher) Result
 } 
\ No newline at end of file
@@ -1,7 +1,6 @@
 package versiondetect
 
 import (
-	"bytes"
 	"io"
 	"log"
 	"net/url"
@@ -18,108 +17,85 @@ var simpleVersionRegex = regexp.MustCompile(`["'](\d+\.\d+\.\d+[^"']*)["']`)
 var windowNextDirectVersionRegex = regexp.MustCompile(`window\.next\s*=\s*{[\\s\\S]*?version\s*:\s*[\"\'](\\d+\\.\\d+\\.\\d+[^\\\"\']*)[\"\']`)
 var windowNextVarVersionRegex = regexp.MustCompile(`window\.next\s*=\s*{[\\s\\S]*?version\s*:\s*([a-zA-Z0-9_$]+(?:\\.[a-zA-Z0-9_$]+)?)`)
 var assignmentVersionRegex = regexp.MustCompile(`(?:let|var|const)\s+[a-zA-Z0-9_$]+\s*=\s*[\"\'](\\d+\\.\\d+\\.\\d+[^\"\']*)[\"\']`)
+var reconcilerVersionRegex = regexp.MustCompile(`reconcilerVersion\s*:\s*["'](\d+\.\d+\.\d+[^"']*)["']`)
 var reactVersionInContextRegex = regexp.MustCompile(`version\\s*:\\s*[\"\'](\\d+\\.\\d+\\.\\d+[^\"\']*)[\"\']`)
 
+// reactExportsVersionRegex matches the version export of the react and
+// react-dom packages (exports.version = "18.3.1"), with exports renamed to a
+// short identifier by the minifier ("t.version").
+var reactExportsVersionRegex = regexp.MustCompile(`(?:^|[^\w$.])(?:exports|[a-zA-Z_$][\w$]?)\.version\s*=\s*["'](\d+\.\d+\.\d+[^"']*)["']`)
+
+// versionContextWindow is how many bytes around a quoted version are checked
+// for the tokens that attribute it to React or Next.js.
+const versionContextWindow = 30
+
+// nextVersionTokens are the Next.js-specific tokens one of which must appear
+// near a quoted version for it to be taken as the Next.js version.
+var nextVersionTokens = []string{"next", "__NEXT", "buildId"}
+
+// DefaultMaxAssets is the default number of JS assets fetched during version detection.
+const DefaultMaxAssets = 25
+
 // HeuristicAssetScannerDetector implements VersionDetector using regex scanning of JS assets.
 // It prioritizes core chunks and uses context checks to differentiate Next.js and React.
-type HeuristicAssetScannerDetector struct{}
+type HeuristicAssetScannerDetector struct {
+	// MaxAssets caps how many asset URLs are considered, keeping the
+	// prioritized framework/main chunks first. Zero means no limit.
+	MaxAssets int
+
+	// Progress, when set, is called each time a new asset is fetched, with
+	// the number of distinct assets fetched so far and the number considered.
+	Progress func(done, total int)
+}
 
 var _ VersionDetector = (*HeuristicAssetScannerDetector)(nil)
 
-type fetchFunc func(assetURL string, stage string) ([]byte, bool)
-
-// detectWithWindowNextPattern searches URLs for the specific window.next.version pattern (direct or via variable).
-func detectWithWindowNextPattern(urls []string, fetchContent fetchFunc, stagePrefix string) (version string, found bool) {
-	log.Printf("Version check (%s): Searching %d URLs for window.next patterns...", stagePrefix, len(urls))
-	for _, assetURL := range urls {
-		contentBytes, ok := fetchContent(assetURL, stagePrefix+" window.next patterns")
-		if !ok { continue }
-
-		// Try direct assignment regex
-		matchDirect := windowNextDirectVersionRegex.FindSubmatch(contentBytes)
-		if len(matchDirect) > 1 {
-			foundVersion := string(matchDirect[1])
-			log.Printf("Version check (%s): Found specific Next.js version '%s' (via direct window.next regex) in %s", stagePrefix, foundVersion, assetURL)
-			return foundVersion, true
-		}
-
-		// Try variable assignment regex
-		matchVar := windowNextVarVersionRegex.FindSubmatchIndex(contentBytes)
-		if matchVar != nil {
-			// Found window.next = { version: someVariable }
-			// Search the entire file for the first likely version assignment
-			varIdentifierBytes := contentBytes[matchVar[2]:matchVar[3]]
-			varIdentifier := string(varIdentifierBytes)
-			log.Printf("Version check (%s): Found window.next assignment via variable '%s' in %s. Searching *entire file* for version assignment...", stagePrefix, varIdentifier, assetURL)
-			
-			// Look for patterns like let H = "15.2.0" anywhere in this file
-			assignmentMatch := assignmentVersionRegex.FindSubmatch(contentBytes)
-			if len(assignmentMatch) > 1 {
-				foundVersion := string(assignmentMatch[1])
-				log.Printf("Version check (%s): Found potential version '%s' (via file-wide assignment regex) after finding variable use in %s", stagePrefix, foundVersion, assetURL)
-				return foundVersion, true
-			}
-
-			// Fallback: Use simple regex across the whole file
-			simpleMatch := simpleVersionRegex.FindSubmatch(contentBytes)
-			if len(simpleMatch) > 1 {
-				foundVersion := string(simpleMatch[1])
-				log.Printf("Version check (%s): Found potential version '%s' (via file-wide simple regex fallback) after finding variable use in %s", stagePrefix, foundVersion, assetURL)
-				return foundVersion, true
-			}
-			log.Printf("Version check (%s): Could not find any version assignment in file %s despite finding variable use.", stagePrefix, assetURL)
-		}
+// isReactChunk reports whether an asset is, by name, a chunk bundling React:
+// the "framework" chunk of Next.js builds or a dedicated react-dom chunk.
+func isReactChunk(assetURL string) bool {
+	parsedURL, err := url.Parse(assetURL)
+	if err != nil {
+		return false
 	}
-	log.Printf("Version check (%s): window.next patterns did not yield version in provided URLs.", stagePrefix)
-	return "", false
+	base := path.Base(parsedURL.Path)
+	return strings.Contains(base, "framework") || strings.Contains(base, "react-dom")
 }
 
-// detectWithSimpleContextPattern searches URLs using simple regex and context analysis.
-func detectWithSimpleContextPattern(urls []string, fetchContent fetchFunc, currentNextVersion, currentReactVersion string) (foundNext string, foundReact string) {
-	log.Printf("Version check (Simple Context): Searching %d URLs with simple regex + context...", len(urls))
-	nextVersion := currentNextVersion
-	reactVersion := currentReactVersion
-
-	for _, assetURL := range urls {
-		if nextVersion != "" && reactVersion != "" { break }
-
-		contentBytes, ok := fetchContent(assetURL, "Simple Context Scan")
-		if !ok { continue }
-
-		matches := simpleVersionRegex.FindAllSubmatch(contentBytes, -1)
-		for _, match := range matches {
-			if len(match) < 2 { continue }
-			candidateVersion := string(match[1])
-			fullMatchText := string(match[0])
-
-			if nextVersion != "" && reactVersion != "" { break }
-
-			matchIndex := bytes.Index(contentBytes, match[0])
-			contextWindow := 30
-			// Using built-in min/max functions from Go 1.21+
-			start := max(0, matchIndex-contextWindow)
-			end := min(len(contentBytes), matchIndex+len(match[0])+contextWindow)
-			context := string(contentBytes[start:end])
-			contextCleaned := strings.ReplaceAll(context, "\\n", " ")
-
-			isReact := strings.Contains(context, "react") || strings.Contains(context, "React") || strings.Contains(context, "react-dom")
-			isReconciler := strings.Contains(context, "reconcilerVersion")
-
-			if isReact && reactVersion == "" {
-				reactVersion = candidateVersion
-				log.Printf("Version check (Simple Context): Found potential React version '%s' (Full Match: '%s', Context: '%s') in %s",
-					candidateVersion, fullMatchText, contextCleaned, assetURL)
-			} else if !isReact && !isReconciler && nextVersion == "" {
-				nextVersion = candidateVersion
-				log.Printf("Version check (Simple Context): Found potential Next.js version '%s' (Full Match: '%s', Context: '%s') in %s",
-					candidateVersion, fullMatchText, contextCleaned, assetURL)
-			}
+// versionCandidate is a quoted version string found in an asset, with what
+// its surrounding context says about it.
+type versionCandidate struct {
+	version      string
+	context      string // Up to versionContextWindow bytes on each side, for logging
+	isReact      bool
+	isReconciler bool
+	nearNext     bool // A Next.js token (see nextVersionTokens) is within the context
+}
 
-			if nextVersion != "" && reactVersion != "" { break }
+// versionCandidates returns every quoted version string in content, in order.
+func versionCandidates(content []byte) []versionCandidate {
+	var candidates []versionCandidate
+	for _, loc := range simpleVersionRegex.FindAllSubmatchIndex(content, -1) {
+		// Using built-in min/max functions from Go 1.21+
+		start := max(0, loc[0]-versionContextWindow)
+		end := min(len(content), loc[1]+versionContextWindow)
+		context := string(content[start:end])
+
+		nearNext := false
+		for _, token := range nextVersionTokens {
+			if strings.Contains(context, token) {
+				nearNext = true
+				break
+			}
 		}
+		candidates = append(candidates, versionCandidate{
+			version:      string(content[loc[2]:loc[3]]),
+			context:      strings.ReplaceAll(context, "\\n", " "),
+			isReact:      strings.Contains(context, "react") || strings.Contains(context, "React") || strings.Contains(context, "react-dom"),
+			isReconciler: strings.Contains(context, "reconcilerVersion"),
+			nearNext:     nearNext,
+		})
 	}
-	log.Println("Version check (Simple Context): Scan complete.")
-	return nextVersion, reactVersion
+	return candidates
 }
 
 // detectWithAppManifestProbe checks for the existence of _appManifest.js.
@@ -152,21 +128,137 @@ func detectWithAppManifestProbe(buildID string, assetBaseURL *url.URL, fetcher f
 	return "Unknown (Error probing)", false
 }
 
-// Detect attempts to fingerprint Next.js and React versions using asset scanning strategies.
-func (d *HeuristicAssetScannerDetector) Detect(buildID string, jsAssetURLs map[string]bool, assetBaseURL *url.URL, fetcher fetch.Fetcher) (nextVersion string, reactVersion string) {
-	if fetcher == nil {
-		return "Unknown (Missing fetcher)", "Unknown (Missing fetcher)"
+// Detection methods recorded in Candidate.Method, from the most to the least
+// reliable.
+const (
+	methodWindowNext           = "window.next"            // window.next = {version: "14.2.3"}
+	methodReactExports         = "react-exports"          // exports.version = "18.3.1" in a React chunk
+	methodWindowNextAssignment = "window.next-assignment" // let r = "14.2.3" in a file setting window.next = {version: r}
+	methodWindowNextContext    = "window.next-context"    // Quoted version near a Next.js token, in a file setting window.next
+	methodContext              = "context"                // Quoted version near a Next.js or React token
+	methodReconciler           = "reconciler"             // reconcilerVersion: "0.26.0"
+	methodFallback             = "fallback"               // Quoted version with nothing tying it to Next.js
+)
+
+// methodConfidence weighs each detection method in the selection of the
+// final versions.
+var methodConfidence = map[string]int{
+	methodWindowNext:           5,
+	methodReactExports:         5,
+	methodWindowNextAssignment: 4,
+	methodWindowNextContext:    3,
+	methodContext:              2,
+	methodReconciler:           2,
+	methodFallback:             1,
+}
+
+// candidateSet collects the version candidates of a detection, merging
+// repeated findings of the same version by the same method.
+type candidateSet struct {
+	candidates []Candidate
+	index      map[[3]string]int // Product, version and method to position in candidates
+}
+
+// add records one occurrence of version in assetURL.
+func (s *candidateSet) add(product, version, method, assetURL string) {
+	if s.index == nil {
+		s.index = make(map[[3]string]int)
+	}
+	key := [3]string{product, version, method}
+	i, ok := s.index[key]
+	if !ok {
+		i = len(s.candidates)
+		s.index[key] = i
+		s.candidates = append(s.candidates, Candidate{Product: product, Version: version, Method: method, Confidence: methodConfidence[method]})
+	}
+	candidate := &s.candidates[i]
+	candidate.Count++
+	if n := len(candidate.Assets); n == 0 || candidate.Assets[n-1] != assetURL {
+		candidate.Assets = append(candidate.Assets, assetURL)
+	}
+}
+
+// best returns the candidate of product with the highest confidence, the
+// most frequent among equally confident ones, and the first found among
+// equally frequent ones (priority chunks are scanned first).
+func (s *candidateSet) best(product string) (Candidate, bool) {
+	var best Candidate
+	found := false
+	for _, candidate := range s.candidates {
+		if candidate.Product != product {
+			continue
+		}
+		if !found || candidate.Confidence > best.Confidence || (candidate.Confidence == best.Confidence && candidate.Count > best.Count) {
+			best = candidate
+			found = true
+		}
+	}
+	return best, found
+}
+
+// collectCandidates records every version found in the content of a chunk.
+func collectCandidates(set *candidateSet, assetURL string, content []byte) {
+	if match := windowNextDirectVersionRegex.FindSubmatch(content); len(match) > 1 {
+		set.add(ProductNext, string(match[1]), methodWindowNext, assetURL)
+	}
+
+	// window.next = {version: r}: the version is assigned to a variable
+	// somewhere else in the file
+	setsWindowNext := windowNextVarVersionRegex.Match(content)
+	if setsWindowNext {
+		if match := assignmentVersionRegex.FindSubmatch(content); len(match) > 1 {
+			set.add(ProductNext, string(match[1]), methodWindowNextAssignment, assetURL)
+		}
+	}
+
+	if isReactChunk(assetURL) {
+		if match := reactExportsVersionRegex.FindSubmatch(content); len(match) > 1 {
+			set.add(ProductReact, string(match[1]), methodReactExports, assetURL)
+		}
+	}
+	for _, match := range reconcilerVersionRegex.FindAllSubmatch(content, -1) {
+		set.add(ProductReactReconciler, string(match[1]), methodReconciler, assetURL)
+	}
+
+	for _, candidate := range versionCandidates(content) {
+		switch {
+		case candidate.isReconciler:
+			// Already recorded above
+		case candidate.isReact:
+			set.add(ProductReact, candidate.version, methodContext, assetURL)
+		case candidate.nearNext && setsWindowNext:
+			set.add(ProductNext, candidate.version, methodWindowNextContext, assetURL)
+		case candidate.nearNext:
+			set.add(ProductNext, candidate.version, methodContext, assetURL)
+		default:
+			set.add(ProductNext, candidate.version, methodFallback, assetURL)
+		}
 	}
+}
+
+// logSelection logs the candidate chosen for product.
+func logSelection(name string, candidate Candidate, total int) {
+	log.Printf("Version check: Selected %s version '%s' (%s, confidence %d, %d occurrences in %d chunks) among %d candidates",
+		name, candidate.Version, candidate.Method, candidate.Confidence, candidate.Count, len(candidate.Assets), total)
+}
 
-	finalNextVersion := ""
-	finalReactVersion := ""
+// Detect attempts to fingerprint Next.js and React versions using asset
+// scanning strategies. Every chunk is scanned once, collecting each version
+// string found along with the method that found it; the most reliable
+// method wins, and among equally reliable findings the most frequent
+// version, so a decoy version in a bundled dependency doesn't mask the real
+// one. Result.Candidates lists every finding.
+func (d *HeuristicAssetScannerDetector) Detect(buildID string, jsAssetURLs map[string]bool, assetBaseURL *url.URL, fetcher fetch.Fetcher) Result {
+	if fetcher == nil {
+		return Result{NextVersion: "Unknown (Missing fetcher)", ReactVersion: "Unknown (Missing fetcher)"}
+	}
 
 	// Prepare URL Lists
 	priorityURLs := []string{}
 	otherURLs := []string{}
 	for u := range jsAssetURLs {
 		parsedURL, err := url.Parse(u)
-		if err == nil && (strings.Contains(path.Base(parsedURL.Path), "framework") || strings.Contains(path.Base(parsedURL.Path), "main")) {
+		if err == nil && (isReactChunk(u) || strings.Contains(path.Base(parsedURL.Path), "main")) {
 			priorityURLs = append(priorityURLs, u)
 		} else {
 			otherURLs = append(otherURLs, u)
@@ -174,82 +266,78 @@ func (d *HeuristicAssetScannerDetector) Detect(buildID string, jsAssetURLs map[s
 	}
 	sort.Strings(priorityURLs)
 	sort.Strings(otherURLs)
-	allURLs := append(priorityURLs, otherURLs...)
-	sort.Strings(allURLs)
 
-	// Fetch Content Helper
-	fetchContent := func(assetURL string, stage string) ([]byte, bool) {
-		log.Printf("Version check (%s): Probing %s", stage, assetURL)
+	// Enforce the asset cap after sorting so the most informative chunks survive
+	if d.MaxAssets > 0 && len(priorityURLs)+len(otherURLs) > d.MaxAssets {
+		log.Printf("Version check: Limiting scan to %d of %d JS assets (max assets).", d.MaxAssets, len(priorityURLs)+len(otherURLs))
+		if len(priorityURLs) >= d.MaxAssets {
+			priorityURLs = priorityURLs[:d.MaxAssets]
+			otherURLs = nil
+		} else {
+			otherURLs = otherURLs[:d.MaxAssets-len(priorityURLs)]
+		}
+	}
+	allURLs := make([]string, 0, len(priorityURLs)+len(otherURLs))
+	allURLs = append(allURLs, priorityURLs...)
+	allURLs = append(allURLs, otherURLs...)
+
+	log.Printf("Version check: Scanning %d JS assets for version candidates...", len(allURLs))
+	candidates := &candidateSet{}
+	for i, assetURL := range allURLs {
+		if d.Progress != nil {
+			d.Progress(i+1, len(allURLs))
+		}
+		log.Printf("Version check: Probing %s", assetURL)
 		reader, _, err := fetcher.Fetch(assetURL)
 		if err != nil {
-			log.Printf("Version check (%s): Failed to fetch asset %s: %v", stage, assetURL, err)
-			return nil, false
+			log.Printf("Version check: Failed to fetch asset %s: %v", assetURL, err)
+			continue
 		}
-		defer reader.Close()
-		contentBytes, readErr := io.ReadAll(reader)
+		content, readErr := io.ReadAll(reader)
+		reader.Close()
 		if readErr != nil {
-			log.Printf("Version check (%s): Failed to read asset %s: %v", stage, assetURL, readErr)
-			return nil, false
+			log.Printf("Version check: Failed to read asset %s: %v", assetURL, readErr)
+			continue
 		}
-		return contentBytes, true
-	}
-
-	// Strategy 1a: Try window.next pattern on priority URLs (for Next.js version)
-	foundVersion, found := detectWithWindowNextPattern(priorityURLs, fetchContent, "Strategy 1a (Priority window.next)")
-	if found {
-		finalNextVersion = foundVersion
-	}
-
-	// Strategy 1b: Try simple context pattern on priority URLs (for React version)
-	_, reactCand := detectWithSimpleContextPattern(priorityURLs, fetchContent, finalNextVersion, "")
-	if reactCand != "" {
-		finalReactVersion = reactCand
-		log.Printf("Version check (Strategy 1b Priority React Context): Set React version to '%s' based on priority scan.", finalReactVersion)
+		collectCandidates(candidates, assetURL, content)
 	}
-
-	// Strategy 1c: If Next.js not found yet, try window.next pattern on other URLs
-	if finalNextVersion == "" {
-		foundVersion, found = detectWithWindowNextPattern(otherURLs, fetchContent, "Strategy 1c (Other window.next)")
-		if found {
-			finalNextVersion = foundVersion
+	result := Result{Candidates: candidates.candidates}
+	total := len(candidates.candidates)
+
+	if next, found := candidates.best(ProductNext); found {
+		logSelection("Next.js", next, total)
+		result.NextVersion = next.Version
+		result.NextVersionFallback = next.Method == methodFallback
+		if result.NextVersionFallback {
+			log.Printf("Version check: No version found near a Next.js token, falling back to '%s' (low confidence).", next.Version)
 		}
+	} else if versionHint, foundHint := detectWithAppManifestProbe(buildID, assetBaseURL, fetcher); foundHint {
+		// Fallback: the App Router manifest only tells the major version range
+		result.NextVersion = versionHint
 	}
-
-	// Strategy 2: Try simple regex with context on ALL URLs (Fallback for anything not found yet)
-	if finalNextVersion == "" || finalReactVersion == "" {
-		log.Printf("Version check (Strategy 2 Fallback Context): Running simple context scan on ALL URLs for missing versions (Next?: %t, React?: %t).", finalNextVersion == "", finalReactVersion == "")
-		nextCandFallback, reactCandFallback := detectWithSimpleContextPattern(allURLs, fetchContent, finalNextVersion, finalReactVersion)
-		if finalNextVersion == "" && nextCandFallback != "" {
-			finalNextVersion = nextCandFallback
-		}
-		if finalReactVersion == "" && reactCandFallback != "" {
-			finalReactVersion = reactCandFallback
-		}
+	if reconciler, found := candidates.best(ProductReactReconciler); found {
+		result.ReactReconcilerVersion = reconciler.Version
 	}
-
-	// Strategy 3: Fallback - App Manifest Probe (only if Next version still unknown)
-	if finalNextVersion == "" {
-		versionHint, foundHint := detectWithAppManifestProbe(buildID, assetBaseURL, fetcher)
-		if foundHint {
-			finalNextVersion = versionHint
-		}
+	if react, found := candidates.best(ProductReact); found {
+		logSelection("React", react, total)
+		result.ReactVersion = react.Version
+	} else if result.ReactReconcilerVersion != "" {
+		log.Printf("Version check: Using React reconciler version '%s' as React version.", result.ReactReconcilerVersion)
+		result.ReactVersion = result.ReactReconcilerVersion
 	}
 
 	// Final Cleanup
-	if finalNextVersion == "" {
+	if result.NextVersion == "" {
 		log.Println("Version check: Could not determine Next.js version through any strategy.")
-		finalNextVersion = "Unknown"
+		result.NextVersion = "Unknown"
 	} else {
-		log.Printf("Version check: Final determined Next.js version/hint: %s", finalNextVersion)
+		log.Printf("Version check: Final determined Next.js version/hint: %s", result.NextVersion)
 	}
-
-	if finalReactVersion == "" {
+	if result.ReactVersion == "" {
 		log.Println("Version check: Could not determine React version.")
-		finalReactVersion = "Unknown"
+		result.ReactVersion = "Unknown"
 	} else {
-		log.Printf("Version check: Final determined React version: %s", finalReactVersion)
+		log.Printf("Version check: Final determined React version: %s", result.ReactVersion)
 	}
-
-	return finalNextVersion, finalReactVersion
+	return result
 }
-
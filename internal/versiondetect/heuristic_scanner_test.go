@@ -0,0 +1,172 @@
+package versiondetect
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rodrigopv/nextr4y/internal/fetch"
+)
+
+// mapFetcher serves canned asset bodies and records every URL requested.
+type mapFetcher struct {
+	mu      sync.Mutex
+	assets  map[string]string
+	fetched []string
+}
+
+func (f *mapFetcher) Fetch(targetURL string) (io.ReadCloser, string, error) {
+	f.mu.Lock()
+	f.fetched = append(f.fetched, targetURL)
+	f.mu.Unlock()
+	body, ok := f.assets[targetURL]
+	if !ok {
+		return nil, targetURL, fmt.Errorf("bad status code fetching %s: 404", targetURL)
+	}
+	return io.NopCloser(strings.NewReader(body)), targetURL, nil
+}
+
+func (f *mapFetcher) Capabilities() fetch.FetcherCapabilities {
+	return fetch.FetcherCapabilities{}
+}
+
+func TestDetect_MaxAssetsKeepsPriorityChunks(t *testing.T) {
+	t.Parallel()
+
+	assets := map[string]string{
+		"https://example.com/_next/static/chunks/framework-1.js": `var a="noop";`,
+	}
+	urls := map[string]bool{"https://example.com/_next/static/chunks/framework-1.js": true}
+	for i := 0; i < 10; i++ {
+		u := fmt.Sprintf("https://example.com/_next/static/chunks/%d.js", i)
+		assets[u] = `var b="noop";`
+		urls[u] = true
+	}
+	fetcher := &mapFetcher{assets: assets}
+
+	detector := &HeuristicAssetScannerDetector{MaxAssets: 3}
+	detector.Detect("", urls, nil, fetcher)
+
+	unique := make(map[string]bool)
+	for _, u := range fetcher.fetched {
+		unique[u] = true
+	}
+	require.Len(t, unique, 3)
+	require.True(t, unique["https://example.com/_next/static/chunks/framework-1.js"])
+}
+
+func TestDetect_CapturesReconcilerVersion(t *testing.T) {
+	t.Parallel()
+
+	const frameworkURL = "https://example.com/_next/static/chunks/framework-abc.js"
+	fetcher := &mapFetcher{assets: map[string]string{
+		frameworkURL: `var Qk={findFiberByHostInstance:Wc,bundleType:0,version:"17.0.2",rendererPackageName:"react-dom"};` +
+			`var Rk={bundleType:Qk.bundleType,version:Qk.version,rendererPackageName:Qk.rendererPackageName,reconcilerVersion:"0.26.0"};`,
+	}}
+
+	result := (&HeuristicAssetScannerDetector{}).Detect("", map[string]bool{frameworkURL: true}, nil, fetcher)
+
+	require.Equal(t, "0.26.0", result.ReactReconcilerVersion)
+	require.Equal(t, "17.0.2", result.ReactVersion)
+}
+
+func TestDetect_ReportsProgressOncePerAsset(t *testing.T) {
+	t.Parallel()
+
+	urls := make(map[string]bool)
+	for i := 0; i < 4; i++ {
+		urls[fmt.Sprintf("https://example.com/_next/static/chunks/%d.js", i)] = true
+	}
+	fetcher := &mapFetcher{assets: map[string]string{}}
+
+	var updates [][2]int
+	detector := &HeuristicAssetScannerDetector{Progress: func(done, total int) {
+		updates = append(updates, [2]int{done, total})
+	}}
+	detector.Detect("", urls, nil, fetcher)
+
+	// Every asset is counted once, even when it fails to fetch
+	require.Equal(t, [][2]int{{1, 4}, {2, 4}, {3, 4}, {4, 4}}, updates)
+}
+
+func TestDetect_SkipsDecoyVersions(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		fixture      string
+		wantVersion  string
+		wantFallback bool
+	}{
+		// The dependency's version comes first, the real one feeds window.next
+		{fixture: "decoy_window_next.js", wantVersion: "14.2.3"},
+		// Same, with only __NEXT_DATA__ / buildId near the real version
+		{fixture: "decoy_context.js", wantVersion: "13.4.19"},
+		// Nothing ties the only version to Next.js: kept, but as a fallback
+		{fixture: "decoy_only.js", wantVersion: "1.6.2", wantFallback: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.fixture, func(t *testing.T) {
+			t.Parallel()
+
+			content, err := os.ReadFile(filepath.Join("testdata", tc.fixture))
+			require.NoError(t, err)
+			const chunkURL = "https://example.com/_next/static/chunks/pages/_app-abc.js"
+			fetcher := &mapFetcher{assets: map[string]string{chunkURL: string(content)}}
+
+			result := (&HeuristicAssetScannerDetector{}).Detect("", map[string]bool{chunkURL: true}, nil, fetcher)
+
+			require.Equal(t, tc.wantVersion, result.NextVersion)
+			require.Equal(t, tc.wantFallback, result.NextVersionFallback)
+		})
+	}
+}
+
+func TestDetect_ReactVersionFromExports(t *testing.T) {
+	t.Parallel()
+
+	content, err := os.ReadFile(filepath.Join("testdata", "react_dom_chunk.js"))
+	require.NoError(t, err)
+
+	// The chunk mentions react-refresh's version before React's own export
+	for _, chunkURL := range []string{
+		"https://example.com/_next/static/chunks/framework-2c79e2a64abdb08b.js",
+		"https://example.com/_next/static/chunks/react-dom-5ad3f1b2.js",
+	} {
+		fetcher := &mapFetcher{assets: map[string]string{chunkURL: string(content)}}
+
+		result := (&HeuristicAssetScannerDetector{}).Detect("", map[string]bool{chunkURL: true}, nil, fetcher)
+
+		require.Equal(t, "18.3.1", result.ReactVersion, chunkURL)
+		require.Equal(t, "18.3.1-next-f1338f8080-20240426", result.ReactReconcilerVersion, chunkURL)
+	}
+}
+
+func TestDetect_WeighsCandidates(t *testing.T) {
+	t.Parallel()
+
+	const mainURL = "https://example.com/_next/static/chunks/main-abc.js"
+	const otherURL = "https://example.com/_next/static/chunks/123-def.js"
+	fetcher := &mapFetcher{assets: map[string]string{
+		// A decoy near a Next.js token comes first, the real version is
+		// found twice by the same method
+		mainURL:  `var d={next:"9.9.9"};var v={nextVersion:"14.2.3"};`,
+		otherURL: `var w={next:"14.2.3"};` + strings.Repeat(" ", versionContextWindow) + `var x="1.0.0",y="1.0.0",z="1.0.0";`,
+	}}
+
+	result := (&HeuristicAssetScannerDetector{}).Detect("", map[string]bool{mainURL: true, otherURL: true}, nil, fetcher)
+
+	require.Equal(t, "14.2.3", result.NextVersion)
+	require.False(t, result.NextVersionFallback)
+	require.Equal(t, []Candidate{
+		{Product: ProductNext, Version: "9.9.9", Method: "context", Confidence: 2, Count: 1, Assets: []string{mainURL}},
+		{Product: ProductNext, Version: "14.2.3", Method: "context", Confidence: 2, Count: 2, Assets: []string{mainURL, otherURL}},
+		{Product: ProductNext, Version: "1.0.0", Method: "fallback", Confidence: 1, Count: 3, Assets: []string{otherURL}},
+	}, result.Candidates)
+}
@@ -0,0 +1,5 @@
+(self.webpackChunk_N_E=self.webpackChunk_N_E||[]).push([[888],{
+5190:function(e,t,n){"use strict";var a={name:"axios",version:"1.6.2",adapter:["xhr","http"]};e.exports=a},
+6840:function(e,t,n){"use strict";var i=n(5190);
+var u="13.4.19",s=self.__NEXT_DATA__;e.exports={version:u,buildId:s.buildId};
+}}]);
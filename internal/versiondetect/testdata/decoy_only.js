@@ -0,0 +1,3 @@
+(self.webpackChunk_N_E=self.webpackChunk_N_E||[]).push([[405],{
+5190:function(e,t,n){"use strict";var a={name:"axios",version:"1.6.2",adapter:["xhr","http"]};e.exports=a}
+}}]);
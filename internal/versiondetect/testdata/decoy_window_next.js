@@ -0,0 +1,5 @@
+(self.webpackChunk_N_E=self.webpackChunk_N_E||[]).push([[179],{
+8612:function(e,t,n){"use strict";var o=n(2784);e.exports={name:"lodash",VERSION:"4.17.21",debounce:o.debounce}},
+2431:function(e,t,n){"use strict";Object.defineProperty(t,"__esModule",{value:!0});
+let r="14.2.3";window.next={version:r,appDir:!1,get router(){return n.router}};
+}}]);
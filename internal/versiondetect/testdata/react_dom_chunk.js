@@ -0,0 +1,9 @@
+"use strict";(self.webpackChunk_N_E=self.webpackChunk_N_E||[]).push([[774],{
+4448:function(e,t,n){var r=n(7294),l=n(3840);function a(e){for(var t="https://reactjs.org/docs/error-decoder.html?invariant="+e,n=1;n<arguments.length;n++)t+="&args[]="+encodeURIComponent(arguments[n]);return"Minified React error #"+e+"; visit "+t+" for the full message or use the non-minified dev environment for full errors and additional helpful warnings."}
+var Zf={name:"react-refresh",version:"0.14.0"},ut=!("undefined"==typeof window||void 0===window.document||void 0===window.document.createElement);
+var Qk={findFiberByHostInstance:Wc,bundleType:0,version:"18.3.1",rendererPackageName:"react-dom"};
+var Rk={bundleType:Qk.bundleType,version:Qk.version,rendererPackageName:Qk.rendererPackageName,rendererConfig:Qk.rendererConfig,reconcilerVersion:"18.3.1-next-f1338f8080-20240426"};
+t.createPortal=function(e,t){var n=2<arguments.length&&void 0!==arguments[2]?arguments[2]:null;return dl(e,t,null,n)};t.flushSync=function(e){return Sk(e)};t.version="18.3.1";
+},
+3935:function(e,t,n){!function e(){if("undefined"!=typeof __REACT_DEVTOOLS_GLOBAL_HOOK__&&"function"==typeof __REACT_DEVTOOLS_GLOBAL_HOOK__.checkDCE)try{__REACT_DEVTOOLS_GLOBAL_HOOK__.checkDCE(e)}catch(e){console.error(e)}}(),e.exports=n(4448)}
+}]);
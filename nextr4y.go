@@ -0,0 +1,638 @@
+// Package nextr4y exposes the nextr4y scanner as a library.
+//
+// Scan is the single entrypoint used by the CLI and the MCP server. It wires up
+// the default cycleTLS fetcher and heuristic version detector unless the caller
+// supplies its own implementations through Options:
+//
+//	result, err := nextr4y.Scan(ctx, "https://example.com", nextr4y.Options{
+//		Timeout: 20 * time.Second,
+//		Headers: map[string]string{"Accept-Language": "en-US"},
+//	})
+//
+// As with the CLI, a non-nil error does not necessarily mean the result is
+// empty: partial results are returned alongside the error whenever possible.
+package nextr4y
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/rodrigopv/nextr4y/internal/fetch"
+	"github.com/rodrigopv/nextr4y/internal/scanner"
+	"github.com/rodrigopv/nextr4y/internal/versiondetect"
+)
+
+// ScanResult holds everything discovered about a scanned target.
+type ScanResult = scanner.ScanResult
+
+// SchemaVersion is the version of the JSON structure of ScanResult, recorded
+// in ScanResult.SchemaVersion. Its major version changes when fields are
+// removed, renamed or change type, its minor version when fields are added.
+const SchemaVersion = scanner.SchemaVersion
+
+// VersionSkipped is reported as the detected versions of scans run with
+// Options.SkipVersions, as opposed to "Unknown" for undetermined versions.
+const VersionSkipped = scanner.VersionSkipped
+
+// ProgressEvent is a typed step of a scan, sent to Options.Events.
+type ProgressEvent = scanner.ProgressEvent
+
+// ProgressEventType identifies the kind of a ProgressEvent.
+type ProgressEventType = scanner.ProgressEventType
+
+// Types of the events sent to Options.Events.
+const (
+	EventHTMLFetched     = scanner.EventHTMLFetched     // The target page was fetched, URL is its final URL
+	EventManifestFetched = scanner.EventManifestFetched // The build manifest was fetched from URL
+	EventAssetScanned    = scanner.EventAssetScanned    // An asset was fetched for analysis, Found reports whether it was served
+	EventDone            = scanner.EventDone            // The scan finished, Err holds its error if any
+)
+
+// Fetcher retrieves web content for the scanner.
+type Fetcher = fetch.Fetcher
+
+// FetcherCapabilities describes the optional abilities of a Fetcher.
+type FetcherCapabilities = fetch.FetcherCapabilities
+
+// FileFetcher serves a saved page and its assets from the local file
+// system, see NewFileFetcher.
+type FileFetcher = fetch.FileFetcher
+
+// NewFileFetcher creates a Fetcher answering pageURL with htmlFile and any
+// other URL with the file at its path under assetDir, e.g. to scan a page
+// captured by another tool or to test a detector without network.
+// Options.FromDir sets one up for a directory holding the page as index.html.
+func NewFileFetcher(pageURL, htmlFile, assetDir string) *FileFetcher {
+	return fetch.NewFileFetcher(pageURL, htmlFile, assetDir)
+}
+
+// CircuitBreaker skips the hosts that failed too many requests in a row,
+// see NewCircuitBreaker.
+type CircuitBreaker = fetch.CircuitBreaker
+
+// ErrCircuitOpen is returned for the requests a CircuitBreaker skipped.
+var ErrCircuitOpen = fetch.ErrCircuitOpen
+
+// NewCircuitBreaker creates a CircuitBreaker refusing the requests to a host
+// after threshold consecutive failures (no response, a 5xx, 401, 403 or 429)
+// of its page fetches. The assets and probes of a scan whose page was served
+// are not counted. Share it between the Scan calls of a batch through Options.CircuitBreaker.
+func NewCircuitBreaker(threshold int) *CircuitBreaker {
+	return fetch.NewCircuitBreaker(threshold)
+}
+
+// VersionDetector fingerprints the Next.js and React versions of a target.
+type VersionDetector = versiondetect.VersionDetector
+
+// DetectionResult holds the versions reported by a VersionDetector.
+type DetectionResult = versiondetect.Result
+
+// VersionCandidate is a version found during detection, with the method
+// that found it and its weight, see DetectionResult.Candidates.
+type VersionCandidate = versiondetect.Candidate
+
+// ChainDetector runs several VersionDetectors in order and merges their
+// results, preferring exact versions over hints and earlier detectors over
+// later ones.
+type ChainDetector = versiondetect.ChainDetector
+
+// NewChainDetector creates a ChainDetector running detectors in the given
+// order. Pass it as Options.VersionDetector, e.g. to run a custom detector
+// before the default one:
+//
+//	opts.VersionDetector = nextr4y.NewChainDetector(myDetector, nextr4y.DefaultVersionDetector(opts))
+func NewChainDetector(detectors ...VersionDetector) *ChainDetector {
+	return versiondetect.NewChainDetector(detectors...)
+}
+
+// DefaultVersionDetector returns the heuristic asset scanner Scan uses when
+// Options.VersionDetector is nil, configured from opts (MaxAssets, Progress).
+func DefaultVersionDetector(opts Options) VersionDetector {
+	maxAssets := opts.MaxAssets
+	if maxAssets == 0 {
+		maxAssets = versiondetect.DefaultMaxAssets
+	} else if maxAssets < 0 {
+		maxAssets = 0
+	}
+	heuristic := &versiondetect.HeuristicAssetScannerDetector{MaxAssets: maxAssets}
+	if opts.Progress != nil {
+		heuristic.Progress = func(done, total int) {
+			opts.Progress("Fetching asset", done, total)
+		}
+	}
+	return heuristic
+}
+
+// Options configures a scan. The zero value scans with the same defaults as
+// `nextr4y scan <url>`.
+type Options struct {
+	// BaseURL overrides the auto-detected base URL for asset resolution
+	// (the CLI's --base-url flag).
+	BaseURL string
+
+	// AssetHostOverride fetches the assets served from another host than the
+	// page (e.g. "static.example.com") from this host instead, for asset hosts
+	// that block the scanner (the CLI's --asset-host-override flag).
+	AssetHostOverride string
+
+	// Timeout bounds each individual HTTP request. Zero uses the fetcher default.
+	Timeout time.Duration
+
+	// Headers are extra HTTP headers sent with every request.
+	Headers map[string]string
+
+	// MaxRedirects limits the redirects followed per request. Zero uses
+	// fetch.DefaultMaxRedirects (10); a negative value disables redirects.
+	MaxRedirects int
+
+	// CookieJar enables a cookie jar for the duration of the scan: cookies set
+	// by any response (e.g. a CDN token on the HTML page) are replayed on the
+	// following manifest and asset requests.
+	CookieJar bool
+
+	// Insecure disables TLS certificate verification, e.g. for staging hosts
+	// with self-signed certificates (the CLI's --insecure flag). A warning is
+	// logged for every scan that uses it.
+	Insecure bool
+
+	// RotateUserAgent starts every request from a randomly chosen TLS and
+	// User-Agent profile instead of always the first one, so the requests of
+	// a scan look like they come from varied clients (the CLI's --rotate-ua
+	// flag). The other profiles are still tried when one is rejected.
+	RotateUserAgent bool
+
+	// Seed makes the RotateUserAgent choices reproducible: scans with the same
+	// seed pick the same sequence of profiles. Zero uses a random seed.
+	Seed int64
+
+	// ProfileDelay is the wait between two TLS profile attempts of a request,
+	// after a profile was rejected (403, handshake failure), plus up to as much
+	// random jitter (the CLI's --profile-delay flag). It implies
+	// RotateUserAgent. Spreading the attempts out helps against WAFs that
+	// block by fingerprint, at the cost of slower scans when profiles are
+	// rejected. Zero retries immediately.
+	ProfileDelay time.Duration
+
+	// Resolve connects to the given IPs instead of resolving the hosts, like
+	// curl's --resolve (the CLI's --resolve flag), e.g. to scan a site before
+	// a DNS cutover or through a specific CDN node. Keys are "host" or
+	// "host:port", see fetch.ParseResolve. The TLS SNI and the Host header
+	// keep the host name. Hosts without an override resolve normally.
+	Resolve map[string]string
+
+	// HTTPVersion selects the HTTP version of the requests: "1.1" forces
+	// HTTP/1.1, "" (or "auto", "2") negotiates it with the server, preferring
+	// HTTP/2. HTTP/3 is not supported. See fetch.NormalizeHTTPVersion (the
+	// CLI's --http-version flag).
+	HTTPVersion string
+
+	// Cookies seeds the scan's cookie jar for the target host. Setting it
+	// implies CookieJar.
+	Cookies []*http.Cookie
+
+	// MaxAssets limits how many JS assets the default version detector fetches.
+	// Zero uses versiondetect.DefaultMaxAssets (25); a negative value removes the limit.
+	MaxAssets int
+
+	// ManifestTimeout bounds the evaluation of the build manifest JS (the
+	// CLI's --manifest-exec-timeout flag), independently of Timeout.
+	// Zero uses scanner.DefaultManifestExecTimeout (5s).
+	ManifestTimeout time.Duration
+
+	// Scheme is used for targets given without a scheme ("example.com"):
+	// "https" or "http" force that scheme, while the empty string tries HTTPS
+	// and falls back to HTTP when the host cannot be reached over HTTPS.
+	Scheme string
+
+	// HashAssets downloads every discovered asset and records its SHA-256 in
+	// ScanResult.AssetHashes, so two scans can be diffed chunk by chunk.
+	HashAssets bool
+
+	// Discover also fetches /robots.txt and the site's sitemaps to record
+	// server-declared paths in ScanResult.RobotsDisallow and SitemapURLs.
+	Discover bool
+
+	// IncludeRaw keeps the full executed build manifest in
+	// ScanResult.BuildManifestRaw and the App Router flight payload in
+	// ScanResult.FlightPayloadRaw (the CLI's --include-raw flag).
+	IncludeRaw bool
+
+	// ExtractEndpoints collects the URLs and API/GraphQL paths referenced in
+	// the JS chunks fetched during the scan into ScanResult.DiscoveredEndpoints
+	// (the CLI's --extract-endpoints flag).
+	ExtractEndpoints bool
+
+	// ExtractKeys collects the keys and IDs of well-known SDKs (Sentry DSNs,
+	// analytics IDs, ...) embedded in the same chunks into
+	// ScanResult.EmbeddedKeys, unredacted (the CLI's --extract-keys flag).
+	ExtractKeys bool
+
+	// DetectInstrumentation looks in the same chunks for OpenTelemetry SDKs
+	// and Next.js instrumentation hooks, setting ScanResult.Instrumentation,
+	// and collects the OTLP collector URLs they report to into
+	// ScanResult.TelemetryEndpoints (the CLI's --detect-instrumentation flag).
+	DetectInstrumentation bool
+
+	// Metadata fetches /favicon.ico and the PWA web app manifest to record
+	// ScanResult.FaviconFound, AppName and ThemeColor (the CLI's --metadata flag).
+	Metadata bool
+
+	// ProbeData fetches the _next/data JSON of a sample of the Pages Router
+	// routes and records in ScanResult.DataRoutes which ones are live
+	// (the CLI's --probe-data flag).
+	ProbeData bool
+
+	// ProbeImage requests images through the /_next/image optimizer and
+	// records in ScanResult.ImageOptimizer whether it is served, and in
+	// ImageOptimizerExternal whether it fetches images from any host, a
+	// known SSRF vector (the CLI's --probe-image flag).
+	ProbeImage bool
+
+	// ProbePreview requests the conventional Preview and Draft Mode routes
+	// (/api/preview, /api/draft, ...) and records those the target serves in
+	// ScanResult.PreviewEndpoints, a sign of a CMS-backed site (the CLI's
+	// --probe-preview flag).
+	ProbePreview bool
+
+	// Bruteforce probes a wordlist of common Next.js paths (API routes,
+	// development chunks, ...) and records those the target answers in
+	// ScanResult.DiscoveredRoutes (the CLI's --bruteforce flag).
+	Bruteforce bool
+
+	// Wordlist replaces the paths probed by Bruteforce. Empty uses
+	// scanner.DefaultWordlist() (the CLI's --wordlist flag).
+	Wordlist []string
+
+	// SkipVersions skips version detection, the slowest phase of a scan, and
+	// reports both versions as VersionSkipped (the CLI's --no-versions flag).
+	// Use it when only the routes and build ID are needed.
+	SkipVersions bool
+
+	// SkipRoutes skips the build manifest and route enumeration, detecting
+	// versions from the page's own scripts only. It is much faster on large
+	// sites and is what the MCP scan tool's "light" depth uses.
+	SkipRoutes bool
+
+	// DryRun only fetches the page and lists in ScanResult.PlannedRequests
+	// the URLs the rest of the scan would request, without requesting them
+	// (the CLI's --dry-run flag).
+	DryRun bool
+
+	// IncludeErrors records in ScanResult.AssetErrors the error of every asset
+	// that failed to download during version detection or hashing, to explain
+	// "Unknown" versions (the CLI's --include-errors flag).
+	IncludeErrors bool
+
+	// IncludeHeaders records every header of the target page response, with
+	// all the values of repeated headers such as Set-Cookie, in
+	// ScanResult.ResponseHeaders (the CLI's --include-headers flag).
+	IncludeHeaders bool
+
+	// Debug records troubleshooting details in the result, such as every
+	// version candidate the detector weighed in ScanResult.VersionCandidates
+	// (the CLI's --debug flag).
+	Debug bool
+
+	// AssetPattern, when set, also treats the page scripts whose URL matches it
+	// as Next.js chunks, for apps serving them outside "_next/static"
+	// (the CLI's --asset-pattern flag).
+	AssetPattern *regexp.Regexp
+
+	// Progress, when set, receives progress updates during the long phases of
+	// the scan: fetching the build manifest, fetching assets for version
+	// detection ("Fetching asset", 5, 40) and hashing assets.
+	// It may be called from several goroutines.
+	Progress func(stage string, done, total int)
+
+	// Events, when set, receives a typed ProgressEvent at each step of the
+	// scan: EventHTMLFetched, EventManifestFetched, EventAssetScanned for
+	// every asset fetched for version detection or analysis, and EventDone
+	// last. It lets frontends track a scan without scraping the logs, and is
+	// independent of Progress. It may be called from several goroutines.
+	Events func(event ProgressEvent)
+
+	// MaxBodySize caps the size of every response body read, HTML page and
+	// assets alike (the CLI's --max-body-size flag): reading a larger body
+	// fails with fetch.ErrBodyTooLarge, and the default HTTP fetcher stops
+	// downloading it. Zero uses fetch.DefaultMaxBodySize (10MB); a negative
+	// value removes the limit.
+	MaxBodySize int64
+
+	// CacheDir, when set, caches the last result of each host and set of
+	// options in this directory (the CLI's --cache-dir flag). A scan finding
+	// the build ID of the result cached for its options returns it right
+	// after fetching the page, skipping the build manifest, the assets and
+	// the optional phases.
+	CacheDir string
+
+	// CacheTTL is how long a cached result is reused. Zero uses
+	// scanner.DefaultCacheTTL (24h); a negative value never expires it.
+	CacheTTL time.Duration
+
+	// RefreshCache scans the target fully even if its build ID is cached,
+	// then caches the new result (the CLI's --no-cache flag).
+	RefreshCache bool
+
+	// RateLimit caps requests per second for this scan. Zero means unlimited.
+	RateLimit float64
+
+	// RateLimiter, when set, takes precedence over RateLimit. Passing the same
+	// limiter to several concurrent Scan calls makes them share one budget.
+	RateLimiter *rate.Limiter
+
+	// CircuitBreaker, when set, refuses the requests to hosts that failed
+	// too many requests in a row, failing them with ErrCircuitOpen. Passing
+	// the same breaker to the scans of a batch stops them from retrying a
+	// dead or blocking host (the CLI's --circuit-breaker flag).
+	CircuitBreaker *CircuitBreaker
+
+	// Render enables a headless Chrome fallback: when the static HTML has no
+	// Next.js signal, the page is rendered and the resulting DOM is scanned
+	// instead (the CLI's --render flag). Requires Chrome or Chromium installed.
+	Render bool
+
+	// Fetcher replaces the default cycleTLS fetcher. When set, Timeout,
+	// Headers, MaxRedirects, Insecure, RotateUserAgent, ProfileDelay, Seed,
+	// HTTPVersion, Resolve, CookieJar and Cookies are ignored and must be
+	// configured on the custom fetcher instead. The browser started by Render
+	// still honors Timeout, Headers, Insecure and Resolve.
+	Fetcher Fetcher
+
+	// FromDir scans a saved copy of the target instead of fetching it (the
+	// CLI's --from-dir flag): the index.html of the directory is served as
+	// the page and every other URL by its path, e.g. _next/static/... from
+	// FromDir/_next/static/... . No request reaches the network, so Render
+	// is ignored. Fetcher takes precedence.
+	FromDir string
+
+	// VersionDetector replaces the default heuristic asset scanner. Use
+	// NewChainDetector to combine several detectors.
+	VersionDetector VersionDetector
+}
+
+// Scan runs a full scan of targetURL and returns its result.
+// Cancelling ctx stops the scan before its next network request.
+func Scan(ctx context.Context, targetURL string, opts Options) (*ScanResult, error) {
+	scr, closeFetchers, err := newScanner(ctx, targetURL, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer closeFetchers()
+	return scr.ScanTarget(targetURL)
+}
+
+// ScanManifest reads the routes of the build manifest at manifestURL
+// (usually .../_next/static/<build ID>/_buildManifest.js) without fetching
+// the page, for targets whose page blocks scanning while the manifest is
+// reachable. The build ID and asset base URL are derived from manifestURL
+// unless Options.BaseURL is set. Only the manifest is fetched: versions are
+// reported as VersionSkipped, and options about the page or other assets
+// are ignored.
+func ScanManifest(ctx context.Context, manifestURL string, opts Options) (*ScanResult, error) {
+	opts.Render = false
+	opts.FromDir = ""
+	scr, closeFetchers, err := newScanner(ctx, manifestURL, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer closeFetchers()
+	return scr.ScanManifest(manifestURL)
+}
+
+// newScanner wires up the fetchers and version detector configured by opts.
+// closeFetchers releases the fetchers created for the scan.
+func newScanner(ctx context.Context, targetURL string, opts Options) (scr *scanner.Scanner, closeFetchers func(), err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	httpVersion, err := fetch.NormalizeHTTPVersion(opts.HTTPVersion)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var closers []func()
+	closeFetchers = func() {
+		for _, closeFetcher := range closers {
+			closeFetcher()
+		}
+	}
+
+	maxBodySize := opts.MaxBodySize
+	if maxBodySize == 0 {
+		maxBodySize = fetch.DefaultMaxBodySize
+	}
+	fetcher := opts.Fetcher
+	if fetcher == nil && opts.FromDir != "" {
+		fetcher = fetch.NewFileFetcher(targetURL, filepath.Join(opts.FromDir, "index.html"), opts.FromDir)
+		opts.Render = false
+	}
+	if fetcher == nil {
+		fetcherOpts := fetch.HTTPFetcherOptions{
+			Timeout:            opts.Timeout,
+			Headers:            opts.Headers,
+			MaxRedirects:       opts.MaxRedirects,
+			InsecureSkipVerify: opts.Insecure,
+			RotateProfiles:     opts.RotateUserAgent || opts.ProfileDelay > 0,
+			Seed:               opts.Seed,
+			HTTPVersion:        httpVersion,
+			ProfileDelay:       opts.ProfileDelay,
+			Resolve:            opts.Resolve,
+			MaxBodySize:        max(maxBodySize, 0),
+		}
+		if opts.CookieJar || len(opts.Cookies) > 0 {
+			jar, err := newCookieJar(targetURL, opts.Cookies)
+			if err != nil {
+				return nil, nil, err
+			}
+			fetcherOpts.CookieJar = jar
+		}
+		httpFetcher := fetch.NewHTTPFetcherWithOptions(fetcherOpts)
+		closers = append(closers, httpFetcher.Close)
+		fetcher = httpFetcher
+	}
+
+	detector := opts.VersionDetector
+	if detector == nil {
+		detector = DefaultVersionDetector(opts)
+	}
+
+	limiter := opts.RateLimiter
+	if limiter == nil && opts.RateLimit > 0 {
+		limiter = fetch.NewLimiter(opts.RateLimit)
+	}
+	wrap := func(f Fetcher) Fetcher {
+		if maxBodySize > 0 {
+			f = fetch.NewMaxBodyFetcher(f, maxBodySize)
+		}
+		if limiter != nil {
+			f = fetch.NewRateLimitedFetcher(ctx, f, limiter)
+		}
+		if opts.CircuitBreaker != nil {
+			// Outside the rate limiter, so skipped requests don't wait for a token
+			f = fetch.NewCircuitBreakerFetcher(f, opts.CircuitBreaker)
+		}
+		return &contextFetcher{ctx: ctx, Fetcher: f}
+	}
+
+	scannerOpts := scanner.Options{
+		CustomBaseURL:         opts.BaseURL,
+		AssetHostOverride:     opts.AssetHostOverride,
+		ManifestExecTimeout:   opts.ManifestTimeout,
+		Scheme:                opts.Scheme,
+		HashAssets:            opts.HashAssets,
+		Discover:              opts.Discover,
+		Metadata:              opts.Metadata,
+		IncludeRaw:            opts.IncludeRaw,
+		ExtractEndpoints:      opts.ExtractEndpoints,
+		ExtractKeys:           opts.ExtractKeys,
+		DetectInstrumentation: opts.DetectInstrumentation,
+		AssetPattern:          opts.AssetPattern,
+		ProbeData:             opts.ProbeData,
+		ProbeImage:            opts.ProbeImage,
+		ProbePreview:          opts.ProbePreview,
+		Bruteforce:            opts.Bruteforce,
+		Wordlist:              opts.Wordlist,
+		SkipVersions:          opts.SkipVersions,
+		SkipRoutes:            opts.SkipRoutes,
+		DryRun:                opts.DryRun,
+		IncludeErrors:         opts.IncludeErrors,
+		IncludeHeaders:        opts.IncludeHeaders,
+		Debug:                 opts.Debug,
+		Progress:              opts.Progress,
+		Events:                opts.Events,
+		RefreshCache:          opts.RefreshCache,
+	}
+	if opts.CacheDir != "" {
+		scannerOpts.Cache = scanner.NewDirCache(opts.CacheDir, opts.CacheTTL)
+	}
+	if opts.Render {
+		renderFetcher := fetch.NewChromedpFetcher(fetch.HTTPFetcherOptions{
+			Timeout:            opts.Timeout,
+			Headers:            opts.Headers,
+			InsecureSkipVerify: opts.Insecure,
+			Resolve:            opts.Resolve,
+		})
+		closers = append(closers, renderFetcher.Close)
+		scannerOpts.RenderFetcher = wrap(renderFetcher)
+	}
+
+	// Only the fetchers created here honor Insecure, a custom Fetcher or
+	// FromDir doesn't
+	if opts.Insecure && len(closers) > 0 {
+		log.Printf("Warning: TLS certificate verification is disabled, responses may come from an impostor")
+	}
+
+	return scanner.NewScannerWithOptions(wrap(fetcher), detector, scannerOpts), closeFetchers, nil
+}
+
+// newCookieJar creates an in-memory cookie jar seeded with cookies for the
+// host of targetURL, which may lack a scheme.
+func newCookieJar(targetURL string, cookies []*http.Cookie) (http.CookieJar, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(cookies) > 0 {
+		if !strings.Contains(targetURL, "://") {
+			targetURL = "https://" + targetURL
+		}
+		u, err := url.Parse(targetURL)
+		if err != nil {
+			return nil, fmt.Errorf("nextr4y: cannot seed cookies for invalid target URL '%s': %w", targetURL, err)
+		}
+		jar.SetCookies(u, cookies)
+	}
+	return jar, nil
+}
+
+// contextFetcher refuses to start new requests once its context is done.
+type contextFetcher struct {
+	ctx context.Context
+	Fetcher
+}
+
+// Fetch implements the Fetcher interface.
+func (f *contextFetcher) Fetch(targetURL string) (io.ReadCloser, string, error) {
+	if err := f.ctx.Err(); err != nil {
+		return nil, targetURL, err
+	}
+	return f.Fetcher.Fetch(targetURL)
+}
+
+// FetchWithInfo implements the fetch.InfoFetcher interface.
+func (f *contextFetcher) FetchWithInfo(targetURL string) (io.ReadCloser, *fetch.ResponseInfo, error) {
+	if err := f.ctx.Err(); err != nil {
+		return nil, &fetch.ResponseInfo{FinalURL: targetURL}, err
+	}
+	return fetch.FetchWithInfo(f.Fetcher, targetURL)
+}
+
+// Confidence levels reported in VersionInfo.
+const (
+	ConfidenceHigh   = "high"   // An exact version string was found
+	ConfidenceMedium = "medium" // Only a range or router-based estimate is known
+	ConfidenceLow    = "low"    // The version could not be determined, or is only a last-resort guess
+)
+
+// VersionInfo is a compact summary of the framework versions of a target,
+// for callers that don't need the full route and asset listing.
+type VersionInfo struct {
+	IsNextJS     bool   `json:"isNextJS"`
+	NextVersion  string `json:"nextVersion"`
+	ReactVersion string `json:"reactVersion"`
+	Confidence   string `json:"confidence"`
+}
+
+// Versions summarizes the detected versions of a scan result. Confidence
+// reflects how precise the detected Next.js version is, and is low for
+// versions the asset scan could not tie to Next.js.
+func Versions(result *ScanResult) VersionInfo {
+	info := VersionInfo{
+		IsNextJS:     result.IsNextJS,
+		NextVersion:  result.DetectedNextVersion,
+		ReactVersion: result.DetectedReactVersion,
+		Confidence:   versionConfidence(result.DetectedNextVersion),
+	}
+	if result.NextVersionFallback {
+		info.Confidence = ConfidenceLow
+	}
+	return info
+}
+
+// DetectVersions scans targetURL and returns only its version summary.
+// As with Scan, a summary of partial results is returned alongside an error
+// whenever possible.
+func DetectVersions(ctx context.Context, targetURL string, opts Options) (*VersionInfo, error) {
+	result, err := Scan(ctx, targetURL, opts)
+	if result == nil {
+		return nil, err
+	}
+	info := Versions(result)
+	return &info, err
+}
+
+var exactVersionRegex = regexp.MustCompile(`^\d+\.\d+\.\d+`)
+
+// versionConfidence grades a version string produced by a VersionDetector.
+func versionConfidence(version string) string {
+	switch {
+	case exactVersionRegex.MatchString(version):
+		return ConfidenceHigh
+	case version == "" || version == VersionSkipped || strings.HasPrefix(version, "Unknown"):
+		return ConfidenceLow
+	default:
+		return ConfidenceMedium
+	}
+}
@@ -0,0 +1,65 @@
+package nextr4y
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionConfidence(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		version string
+		want    string
+	}{
+		{version: "14.1.0", want: ConfidenceHigh},
+		{version: "13.5.7-canary.1", want: ConfidenceHigh},
+		{version: ">=13 (App Router Likely)", want: ConfidenceMedium},
+		{version: "<13 / Pages Router Likely", want: ConfidenceMedium},
+		{version: "Unknown (Error probing)", want: ConfidenceLow},
+		{version: "", want: ConfidenceLow},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.want, versionConfidence(tc.version), tc.version)
+	}
+}
+
+func TestVersions_FallbackVersionIsLowConfidence(t *testing.T) {
+	t.Parallel()
+
+	result := &ScanResult{IsNextJS: true, DetectedNextVersion: "1.6.2", NextVersionFallback: true}
+	require.Equal(t, ConfidenceLow, Versions(result).Confidence)
+
+	result.NextVersionFallback = false
+	require.Equal(t, ConfidenceHigh, Versions(result).Confidence)
+}
+
+func TestScan_FromDir(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"index.html": `<html><body><script src="/_next/static/chunks/main-1.js"></script>` +
+			`<script id="__NEXT_DATA__" type="application/json">{"buildId":"b1","props":{}}</script></body></html>`,
+		"_next/static/b1/_buildManifest.js": `self.__BUILD_MANIFEST={"/":["static/chunks/pages/index-abc.js"],"/about":["static/chunks/pages/about-def.js"],sortedPages:["/","/about"]};`,
+		"_next/static/chunks/main-1.js":     `console.log("main")`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	result, err := Scan(context.Background(), "https://www.example.com/", Options{FromDir: dir, MaxAssets: 1})
+	require.NoError(t, err)
+	require.True(t, result.IsNextJS)
+	require.Equal(t, "b1", result.BuildID)
+	require.True(t, result.ManifestExecOK)
+	require.Len(t, result.Routes, 2)
+	require.Contains(t, result.Routes, "/about")
+}